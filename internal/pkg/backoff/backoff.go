@@ -0,0 +1,12 @@
+package backoff
+
+import "time"
+
+// Exponential задержка после attempts неудачных попыток: base, 2*base, 4*base и так далее, не больше limit
+func Exponential(attempts int, base, limit time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
@@ -0,0 +1,24 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	for _, tc := range []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	} {
+		if got := Exponential(tc.attempts, 30*time.Second, time.Hour); got != tc.want {
+			t.Errorf("Exponential(%d): got %s, want %s", tc.attempts, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package apierrors общие для HTTP и gRPC API коды ошибок и таблица известных ошибок database и service,
+// чтобы одна и та же ошибка выглядела для клиентов одинаково
+package apierrors
+
+import (
+	"net/http"
+
+	"github.com/SakuraBurst/denet/internal/referrer/database"
+	"github.com/SakuraBurst/denet/internal/referrer/service"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"google.golang.org/grpc/codes"
+)
+
+// коды ошибок API, клиенты могут на них полагаться, текст сообщений может меняться
+const (
+	CodeInternal             = "INTERNAL_ERROR"
+	CodeMalformedBody        = "MALFORMED_BODY"
+	CodeInvalidParameter     = "INVALID_PARAMETER"
+	CodeValidationFailed     = "VALIDATION_FAILED"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeNotFound             = "NOT_FOUND"
+	CodeMethodNotAllowed     = "METHOD_NOT_ALLOWED"
+	CodeUpgradeRequired      = "UPGRADE_REQUIRED"
+	CodeInvalidCredentials   = "INVALID_CREDENTIALS"
+	CodeEarningLimitExceeded = "EARNING_LIMIT_EXCEEDED"
+
+	CodeUserNotFound         = "USER_NOT_FOUND"
+	CodeUserAlreadyExists    = "USER_ALREADY_EXISTS"
+	CodeTaskNotFound         = "TASK_NOT_FOUND"
+	CodeTaskAlreadyExists    = "TASK_ALREADY_EXISTS"
+	CodeTaskAlreadyCompleted = "TASK_ALREADY_COMPLETED"
+	CodeUnknownCurrency      = "UNKNOWN_CURRENCY"
+
+	CodeReferrerCodeNotFound = "REFERRER_CODE_NOT_FOUND"
+	CodeAlreadyReferred      = "ALREADY_REFERRED"
+	CodeInvalidReferral      = "INVALID_REFERRAL"
+
+	CodeSeasonNotFound      = "SEASON_NOT_FOUND"
+	CodeSeasonAlreadyClosed = "SEASON_ALREADY_CLOSED"
+	CodeSeasonNotStarted    = "SEASON_NOT_STARTED"
+	CodeInvalidSeason       = "INVALID_SEASON"
+	CodeInvalidPeriod       = "INVALID_PERIOD"
+
+	CodeItemNotFound           = "ITEM_NOT_FOUND"
+	CodeItemAlreadyExists      = "ITEM_ALREADY_EXISTS"
+	CodeOutOfStock             = "OUT_OF_STOCK"
+	CodePurchaseLimitExceeded  = "PURCHASE_LIMIT_EXCEEDED"
+	CodeInsufficientBalance    = "INSUFFICIENT_BALANCE"
+	CodeOrderNotFound          = "ORDER_NOT_FOUND"
+	CodeOrderNotPending        = "ORDER_NOT_PENDING"
+	CodeInvalidShopItem        = "INVALID_SHOP_ITEM"
+	CodeInvalidQuantity        = "INVALID_QUANTITY"
+	CodeAmountOverflow         = "AMOUNT_OVERFLOW"
+	CodeInvalidTransfer        = "INVALID_TRANSFER"
+	CodeSelfTransfer           = "SELF_TRANSFER"
+	CodeAccountTooNew          = "ACCOUNT_TOO_NEW"
+	CodeTransferLimitExceeded  = "TRANSFER_LIMIT_EXCEEDED"
+	CodeIdempotencyKeyReused   = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInFlight = "IDEMPOTENCY_KEY_IN_PROGRESS"
+
+	CodeCompletionNotFound = "COMPLETION_NOT_FOUND"
+	CodeReferralNotFound   = "REFERRAL_NOT_FOUND"
+	CodeAlreadyRevoked     = "ALREADY_REVOKED"
+
+	CodeTeamNotFound        = "TEAM_NOT_FOUND"
+	CodeTeamAlreadyExists   = "TEAM_ALREADY_EXISTS"
+	CodeAlreadyInTeam       = "ALREADY_IN_TEAM"
+	CodeNotInTeam           = "NOT_IN_TEAM"
+	CodeTeamFull            = "TEAM_FULL"
+	CodeInviteCodeNotFound  = "INVITE_CODE_NOT_FOUND"
+	CodeInvalidTeam         = "INVALID_TEAM"
+	CodeInvalidTeamGoal     = "INVALID_TEAM_GOAL"
+	CodeRaffleNotFound      = "RAFFLE_NOT_FOUND"
+	CodeRaffleClosed        = "RAFFLE_CLOSED"
+	CodeTicketLimitExceeded = "TICKET_LIMIT_EXCEEDED"
+	CodeInvalidRaffle       = "INVALID_RAFFLE"
+
+	CodeWebhookNotFound         = "WEBHOOK_NOT_FOUND"
+	CodeWebhookDeliveryNotFound = "WEBHOOK_DELIVERY_NOT_FOUND"
+	CodeWebhookURLNotPublic     = "WEBHOOK_URL_NOT_PUBLIC"
+)
+
+// InternalMessage сообщение для неизвестных ошибок, подробности остаются в логе
+const InternalMessage = "Произошла ошибка на сервере"
+
+// Known известная клиентам ошибка database или service: HTTP статус, код gRPC, код API и сообщение
+type Known struct {
+	Err     error
+	Status  int
+	GRPC    codes.Code
+	Code    string
+	Message string
+}
+
+// known проверяется по порядку через errors.Is
+var known = []Known{
+	{database.ErrUserNotExist, http.StatusNotFound, codes.NotFound, CodeUserNotFound, "Пользователя с таким id несуществует"},
+	{database.ErrUserAlreadyExist, http.StatusConflict, codes.AlreadyExists, CodeUserAlreadyExists, "Пользователь с таким ником уже существует"},
+	{database.ErrTaskNotExist, http.StatusNotFound, codes.NotFound, CodeTaskNotFound, "Задания с таким id несуществует"},
+	{database.ErrTaskAlreadyExist, http.StatusConflict, codes.AlreadyExists, CodeTaskAlreadyExists, "Задание с таким описанием уже существует"},
+	{database.ErrAlreadyCompletedTask, http.StatusConflict, codes.AlreadyExists, CodeTaskAlreadyCompleted, "Пользователь уже выполнил это задание"},
+	{database.ErrUnknownCurrency, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeUnknownCurrency, "Неизвестная валюта"},
+
+	{database.ErrAlreadyReferred, http.StatusConflict, codes.AlreadyExists, CodeAlreadyReferred, "Пользователь уже ввел реферальный код"},
+	{database.ErrSelfReferral, http.StatusUnprocessableEntity, codes.FailedPrecondition, CodeInvalidReferral, "Нельзя ввести свой код или код своего реферала"},
+	{database.ErrReferralCycle, http.StatusUnprocessableEntity, codes.FailedPrecondition, CodeInvalidReferral, "Нельзя ввести свой код или код своего реферала"},
+
+	{database.ErrSeasonNotExist, http.StatusNotFound, codes.NotFound, CodeSeasonNotFound, "Сезона с таким id несуществует"},
+	{database.ErrSeasonAlreadyClosed, http.StatusConflict, codes.FailedPrecondition, CodeSeasonAlreadyClosed, "Сезон уже закрыт"},
+	{database.ErrSeasonNotStarted, http.StatusConflict, codes.FailedPrecondition, CodeSeasonNotStarted, "Сезон еще не начался, закрыть можно только начавшийся сезон"},
+	{service.ErrInvalidSeason, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeInvalidSeason, "Сезону необходимо название, начало раньше конца и призы за разные места"},
+	{service.ErrUnknownPeriod, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeInvalidPeriod, "Неизвестный период или неправильные границы периода"},
+	{service.ErrInvalidPeriodRange, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeInvalidPeriod, "Неизвестный период или неправильные границы периода"},
+
+	{database.ErrItemNotExist, http.StatusNotFound, codes.NotFound, CodeItemNotFound, "Товара с таким id несуществует"},
+	{database.ErrItemAlreadyExist, http.StatusConflict, codes.AlreadyExists, CodeItemAlreadyExists, "Товар с таким названием уже существует"},
+	{database.ErrOutOfStock, http.StatusConflict, codes.FailedPrecondition, CodeOutOfStock, "Товар закончился"},
+	{database.ErrPurchaseLimitExceeded, http.StatusUnprocessableEntity, codes.ResourceExhausted, CodePurchaseLimitExceeded, "Превышен лимит покупок этого товара"},
+	{database.ErrInsufficientBalance, http.StatusUnprocessableEntity, codes.FailedPrecondition, CodeInsufficientBalance, "Недостаточно средств на балансе"},
+	{database.ErrOrderNotExist, http.StatusNotFound, codes.NotFound, CodeOrderNotFound, "Заказа с таким id несуществует"},
+	{database.ErrOrderNotPending, http.StatusConflict, codes.FailedPrecondition, CodeOrderNotPending, "Заказ уже выдан или отменен"},
+	{service.ErrInvalidShopItem, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeInvalidShopItem, "Товару необходимо название и положительная цена"},
+	{service.ErrInvalidQuantity, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeInvalidQuantity, "Количество должно быть положительным"},
+	{types.ErrAmountOverflow, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeAmountOverflow, "Сумма слишком большая"},
+
+	{service.ErrInvalidTransfer, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeInvalidTransfer, "Переводу необходим получатель, положительная сумма и заголовок Idempotency-Key"},
+	{database.ErrSelfTransfer, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeSelfTransfer, "Нельзя перевести самому себе"},
+	{database.ErrAccountTooNew, http.StatusForbidden, codes.PermissionDenied, CodeAccountTooNew, "Аккаунт слишком новый для переводов"},
+	{database.ErrTransferLimitExceeded, http.StatusUnprocessableEntity, codes.ResourceExhausted, CodeTransferLimitExceeded, "Превышен дневной лимит переводов"},
+	{database.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeIdempotencyKeyReused, "Этот Idempotency-Key уже использован для другого запроса"},
+	{database.ErrIdempotentRequestInProgress, http.StatusConflict, codes.Aborted, CodeIdempotencyKeyInFlight, "Запрос с этим Idempotency-Key еще выполняется"},
+
+	{database.ErrCompletionNotExist, http.StatusNotFound, codes.NotFound, CodeCompletionNotFound, "Пользователь не выполнял это задание"},
+	{database.ErrReferralNotExist, http.StatusNotFound, codes.NotFound, CodeReferralNotFound, "Пользователь не вводил реферальный код"},
+	{database.ErrAlreadyRevoked, http.StatusConflict, codes.FailedPrecondition, CodeAlreadyRevoked, "Награда уже отозвана"},
+
+	{database.ErrTeamNotExist, http.StatusNotFound, codes.NotFound, CodeTeamNotFound, "Команды с таким id несуществует"},
+	{database.ErrTeamAlreadyExist, http.StatusConflict, codes.AlreadyExists, CodeTeamAlreadyExists, "Команда с таким названием уже существует"},
+	{database.ErrAlreadyInTeam, http.StatusConflict, codes.FailedPrecondition, CodeAlreadyInTeam, "Пользователь уже состоит в команде"},
+	{database.ErrNotInTeam, http.StatusNotFound, codes.FailedPrecondition, CodeNotInTeam, "Пользователь не состоит в команде"},
+	{database.ErrTeamFull, http.StatusConflict, codes.FailedPrecondition, CodeTeamFull, "В команде нет свободных мест"},
+	{service.ErrInvalidTeam, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeInvalidTeam, "Команде необходимо название"},
+	{service.ErrInvalidTeamGoal, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeInvalidTeamGoal, "Цели необходима положительная сумма и неотрицательный бонус"},
+
+	{database.ErrRaffleNotExist, http.StatusNotFound, codes.NotFound, CodeRaffleNotFound, "Розыгрыша с таким id несуществует"},
+	{database.ErrRaffleClosed, http.StatusConflict, codes.FailedPrecondition, CodeRaffleClosed, "Продажа билетов на этот розыгрыш закончилась"},
+	{database.ErrTicketLimitExceeded, http.StatusUnprocessableEntity, codes.ResourceExhausted, CodeTicketLimitExceeded, "Превышен лимит билетов на этот розыгрыш"},
+	{service.ErrInvalidRaffle, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeInvalidRaffle, "Розыгрышу необходимо название, цена билета, время розыгрыша в будущем и призы за разные места"},
+
+	{database.ErrWebhookNotExist, http.StatusNotFound, codes.NotFound, CodeWebhookNotFound, "Вебхука с таким id несуществует"},
+	{database.ErrWebhookDeliveryNotExist, http.StatusNotFound, codes.NotFound, CodeWebhookDeliveryNotFound, "Доставки вебхука с таким id несуществует"},
+	{service.ErrWebhookURLNotPublic, http.StatusUnprocessableEntity, codes.InvalidArgument, CodeWebhookURLNotPublic, "Адрес вебхука не должен вести в локальную или внутреннюю сеть"},
+}
+
+// Lookup ищет err среди известных ошибок
+func Lookup(err error) (*Known, bool) {
+	for i := range known {
+		if errors.Is(err, known[i].Err) {
+			return &known[i], true
+		}
+	}
+	return nil, false
+}
+
+// LimitMessages сообщения для database.LimitExceededError по названию лимита
+var LimitMessages = map[string]string{
+	types.LimitDailyReward:       "Достигнут дневной лимит начислений",
+	types.LimitHourlyCompletions: "Слишком много заданий за последний час",
+	types.LimitCooldown:          "Задания нельзя выполнять так часто",
+}
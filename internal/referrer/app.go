@@ -1,40 +1,116 @@
 package referrer
 
 import (
+	"context"
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/SakuraBurst/denet/internal/pkg/logger"
 	"github.com/SakuraBurst/denet/internal/referrer/config"
 	"github.com/SakuraBurst/denet/internal/referrer/database"
+	"github.com/SakuraBurst/denet/internal/referrer/events"
+	"github.com/SakuraBurst/denet/internal/referrer/outbox"
 	"github.com/SakuraBurst/denet/internal/referrer/router"
+	"github.com/SakuraBurst/denet/internal/referrer/rpc"
 	"github.com/SakuraBurst/denet/internal/referrer/service"
 	"go.uber.org/zap"
 )
 
 type App struct {
-	router *router.HttpRouter
-	logger *zap.Logger
+	router     *router.HttpRouter
+	grpc       *rpc.GrpcServer
+	controller *service.Controller
+	bus        *events.Bus
+	relay      *outbox.Relay
+	logger     *zap.Logger
+
+	leaderBoard config.LeaderBoardConfig
+	seasons     config.SeasonsConfig
+	idempotency config.IdempotencyConfig
+	expiration  config.ExpirationConfig
+	teams       config.TeamsConfig
+	raffles     config.RafflesConfig
+	webhooks    config.WebhooksConfig
+	outbox      config.OutboxConfig
 }
 
 func (a *App) Run() error {
 	sisChan := make(chan os.Signal, 1)
+	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
 		if err := a.router.Run(); err != nil {
 			a.logger.Error("router.Run failed: ", zap.Error(err))
 			sisChan <- os.Interrupt
 		}
 	}()
-	return a.gracefulShutdown(sisChan)
+	go func() {
+		if err := a.grpc.Run(); err != nil {
+			a.logger.Error("grpc.Run failed: ", zap.Error(err))
+			sisChan <- os.Interrupt
+		}
+	}()
+	go a.bus.Run(ctx)
+	go a.runEvery(ctx, a.leaderBoard.SnapshotInterval, "controller.SnapshotClosedPeriods", func(ctx context.Context) error {
+		return a.controller.SnapshotClosedPeriods(ctx, time.Now())
+	})
+	go a.runEvery(ctx, a.seasons.CloseInterval, "controller.CloseFinishedSeasons", func(ctx context.Context) error {
+		return a.controller.CloseFinishedSeasons(ctx, time.Now())
+	})
+	go a.runEvery(ctx, a.idempotency.CleanupInterval, "controller.DeleteExpiredIdempotencyKeys", a.controller.DeleteExpiredIdempotencyKeys)
+	if a.expiration.Months > 0 {
+		go a.runEvery(ctx, a.expiration.CheckInterval, "controller.ExpirePoints", func(ctx context.Context) error {
+			return a.controller.ExpirePoints(ctx, time.Now())
+		})
+	}
+	go a.runEvery(ctx, a.teams.GoalPayoutInterval, "controller.PayTeamGoals", a.controller.PayTeamGoals)
+	go a.runEvery(ctx, a.raffles.DrawInterval, "controller.DrawRaffles", func(ctx context.Context) error {
+		return a.controller.DrawRaffles(ctx, time.Now())
+	})
+	go a.runEvery(ctx, a.webhooks.PollInterval, "controller.DeliverWebhooks", func(ctx context.Context) error {
+		return a.controller.DeliverWebhooks(ctx, time.Now())
+	})
+	go a.runEvery(ctx, a.outbox.PollInterval, "relay.Publish", func(ctx context.Context) error {
+		return a.relay.Publish(ctx, time.Now())
+	})
+	go a.runEvery(ctx, a.outbox.CleanupInterval, "relay.Cleanup", a.relay.Cleanup)
+	if a.leaderBoard.CacheSize > 0 {
+		go a.runEvery(ctx, a.leaderBoard.CacheTTL, "controller.RefreshLeaderBoard", a.controller.RefreshLeaderBoard)
+		go a.runEvery(ctx, a.leaderBoard.CacheMinRefresh, "controller.RefreshRanks", a.controller.RefreshRanks)
+	}
+	return a.gracefulShutdown(sisChan, cancel)
+}
+
+// runEvery вызывает job сразу и затем каждые interval, пока ctx не отменен
+func (a *App) runEvery(ctx context.Context, interval time.Duration, name string, job func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := job(ctx); err != nil {
+			a.logger.Error(name+" failed: ", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
-func (a *App) gracefulShutdown(sisChan chan os.Signal) error {
+func (a *App) gracefulShutdown(sisChan chan os.Signal, cancel context.CancelFunc) error {
 	signal.Notify(sisChan, os.Interrupt)
 	<-sisChan
+	cancel()
+	// открытые WebSocket и SSE соединения не дали бы HTTP серверу остановиться
+	a.bus.Close()
+	a.grpc.Close()
 	err := a.router.Close()
 	if err != nil {
 		a.logger.Error("router.Close failed: ", zap.Error(err))
 	}
+	if err = a.relay.Close(); err != nil {
+		a.logger.Error("relay.Close failed: ", zap.Error(err))
+	}
 	return a.logger.Sync()
 }
 
@@ -47,13 +123,33 @@ func NewApp(cfg *config.Config) *App {
 	if err != nil {
 		panic(err)
 	}
-	c := service.NewController(cfg, db, db, db, func() error {
+	bus := events.NewBus(db, cfg.Events, log)
+	c := service.NewController(cfg, db, db, db, db, db, db, db, db, db, db, db, db, db, db, db, bus, func() error {
 		db.Conn.Close()
 		return nil
 	})
+	inProcess := outbox.NewInProcess()
+	inProcess.Handle(c.EnqueueWebhooks)
+	publisher, err := outbox.NewPublisher(cfg.Outbox, log)
+	if err != nil {
+		panic(err)
+	}
 	r := router.CreateRouter(c, cfg, log)
+	g := rpc.CreateServer(c, cfg, log)
 	return &App{
-		router: r,
-		logger: log,
+		router:      r,
+		grpc:        g,
+		controller:  c,
+		bus:         bus,
+		relay:       outbox.NewRelay(db, inProcess, publisher, cfg.Outbox, log),
+		logger:      log,
+		leaderBoard: cfg.LeaderBoard,
+		seasons:     cfg.Seasons,
+		idempotency: cfg.Idempotency,
+		expiration:  cfg.Expiration,
+		teams:       cfg.Teams,
+		raffles:     cfg.Raffles,
+		webhooks:    cfg.Webhooks,
+		outbox:      cfg.Outbox,
 	}
 }
@@ -3,14 +3,198 @@ package config
 import (
 	"flag"
 	"os"
+	"strconv"
+	"time"
+	// в alpine образе нет базы часовых поясов
+	_ "time/tzdata"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type Config struct {
 	HttpPort    string `yaml:"http_port" env-required:"true"`
+	GrpcPort    string `yaml:"grpc_port" env-default:"9090"`
 	DataBaseURL string `yaml:"data_base_url" env-required:"true"`
 	JWTSecret   string `yaml:"jwt_secret" env-required:"true"`
+
+	LeaderBoard LeaderBoardConfig `yaml:"leaderboard"`
+	Seasons     SeasonsConfig     `yaml:"seasons"`
+	Transfers   TransfersConfig   `yaml:"transfers"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Clawback    ClawbackConfig    `yaml:"clawback"`
+	Expiration  ExpirationConfig  `yaml:"expiration"`
+	Limits      LimitsConfig      `yaml:"limits"`
+	Teams       TeamsConfig       `yaml:"teams"`
+	Raffles     RafflesConfig     `yaml:"raffles"`
+	GraphQL     GraphQLConfig     `yaml:"graphql"`
+	Events      EventsConfig      `yaml:"events"`
+	Webhooks    WebhooksConfig    `yaml:"webhooks"`
+	Outbox      OutboxConfig      `yaml:"outbox"`
+	Versioning  VersioningConfig  `yaml:"versioning"`
+	// Levels уровни по возрастанию опыта, без них у всех один уровень без бонусов
+	Levels []LevelConfig `yaml:"levels"`
+}
+
+type LeaderBoardConfig struct {
+	DefaultLimit     int `yaml:"default_limit" env-default:"5"`
+	MaxLimit         int `yaml:"max_limit" env-default:"100"`
+	DefaultNeighbors int `yaml:"default_neighbors" env-default:"2"`
+	// Timezone в которой считаются границы дня, недели и месяца
+	Timezone         string         `yaml:"timezone" env-default:"UTC"`
+	Location         *time.Location `yaml:"-"`
+	SnapshotInterval time.Duration  `yaml:"snapshot_interval" env-default:"10m"`
+	// CacheSize сколько первых мест держать в памяти, 0 выключает кэш
+	CacheSize       int           `yaml:"cache_size" env-default:"1000"`
+	CacheTTL        time.Duration `yaml:"cache_ttl" env-default:"30s"`
+	CacheMinRefresh time.Duration `yaml:"cache_min_refresh" env-default:"1s"`
+}
+
+type SeasonsConfig struct {
+	// CloseInterval как часто проверять не пора ли закрыть сезон
+	CloseInterval time.Duration `yaml:"close_interval" env-default:"1m"`
+}
+
+type TransfersConfig struct {
+	// DailyLimit сколько пользователь может перевести за день, 0 - без ограничений
+	DailyLimit    int           `yaml:"daily_limit" env-default:"1000"`
+	MinAccountAge time.Duration `yaml:"min_account_age" env-default:"72h"`
+}
+
+type IdempotencyConfig struct {
+	// TTL сколько хранится ответ на запрос с Idempotency-Key
+	TTL             time.Duration `yaml:"ttl" env-default:"24h"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env-default:"1h"`
+}
+
+type ClawbackConfig struct {
+	// AllowNegativeBalance разрешает уводить баланс в минус, если награда уже потрачена,
+	// иначе списывается только то, что осталось на балансе
+	AllowNegativeBalance bool `yaml:"allow_negative_balance" env-default:"false"`
+}
+
+type ExpirationConfig struct {
+	// Months через сколько месяцев сгорают начисленные баллы, 0 - не сгорают
+	Months        int           `yaml:"months" env-default:"12"`
+	CheckInterval time.Duration `yaml:"check_interval" env-default:"1h"`
+	// NoticePeriod за сколько до сгорания показывать баллы в статусе пользователя
+	NoticePeriod time.Duration `yaml:"notice_period" env-default:"720h"`
+}
+
+type LimitsConfig struct {
+	// Default ограничения на заработок для всех пользователей
+	Default EarningLimitsConfig `yaml:"default"`
+	// Roles ограничения для отдельных ролей, заменяют Default целиком
+	Roles map[string]EarningLimitsConfig `yaml:"roles"`
+}
+
+// EarningLimitsConfig ограничения на заработок, 0 - без ограничения
+type EarningLimitsConfig struct {
+	// MaxDailyReward сколько баллов можно заработать на заданиях и рефералах за день
+	MaxDailyReward        int           `yaml:"max_daily_reward" env-default:"1000"`
+	MaxCompletionsPerHour int           `yaml:"max_completions_per_hour" env-default:"20"`
+	Cooldown              time.Duration `yaml:"cooldown" env-default:"5s"`
+}
+
+type TeamsConfig struct {
+	MaxMembers int `yaml:"max_members" env-default:"20"`
+	// GoalPayoutInterval как часто выплачивать бонусы за достигнутые цели команд
+	GoalPayoutInterval time.Duration `yaml:"goal_payout_interval" env-default:"1m"`
+}
+
+type RafflesConfig struct {
+	// DrawInterval как часто проверять не пора ли провести розыгрыш
+	DrawInterval time.Duration `yaml:"draw_interval" env-default:"1m"`
+}
+
+type GraphQLConfig struct {
+	MaxDepth int `yaml:"max_depth" env-default:"8"`
+	// MaxComplexity максимальная сложность запроса: поле стоит 1, поле внутри списка - столько раз,
+	// сколько элементов в нем может быть
+	MaxComplexity int `yaml:"max_complexity" env-default:"5000"`
+	// ListSize сколько элементов считать в списке, размер которого не задан limit
+	ListSize int `yaml:"list_size" env-default:"20"`
+	// BatchWait сколько загрузчики собирают id перед одной выборкой из базы
+	BatchWait time.Duration `yaml:"batch_wait" env-default:"2ms"`
+	// MaxParallelism сколько резолверов одного запроса выполняются одновременно, не меньше max_limit таблицы лидеров,
+	// иначе выборки дробятся
+	MaxParallelism int `yaml:"max_parallelism" env-default:"100"`
+}
+
+type EventsConfig struct {
+	// Channel канал Postgres LISTEN/NOTIFY, через который экземпляры сервиса пересылают друг другу события
+	Channel string `yaml:"channel" env-default:"referrer_events"`
+	// Buffer сколько событий может ждать отправки клиенту, медленного клиента отключают
+	Buffer int `yaml:"buffer" env-default:"32"`
+	// Heartbeat как часто слать клиенту пустое сообщение, чтобы прокси не закрывали соединение
+	Heartbeat time.Duration `yaml:"heartbeat" env-default:"25s"`
+	// ReconnectInterval через сколько переподключаться к LISTEN после обрыва
+	ReconnectInterval time.Duration `yaml:"reconnect_interval" env-default:"5s"`
+}
+
+type WebhooksConfig struct {
+	// PollInterval как часто искать доставки, которым пора уйти
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"5s"`
+	// Timeout сколько ждать ответа получателя
+	Timeout time.Duration `yaml:"timeout" env-default:"10s"`
+	// MaxAttempts после стольких неудачных попыток доставка становится dead
+	MaxAttempts int `yaml:"max_attempts" env-default:"8"`
+	// RetryBackoff задержка перед первым повтором, дальше удваивается до MaxBackoff
+	RetryBackoff time.Duration `yaml:"retry_backoff" env-default:"30s"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" env-default:"6h"`
+	// BatchSize сколько доставок брать за раз, Concurrency - сколько из них отправлять одновременно
+	BatchSize   int `yaml:"batch_size" env-default:"100"`
+	Concurrency int `yaml:"concurrency" env-default:"8"`
+	// AllowPrivateNetworks разрешает подписывать и слать вебхуки на localhost и внутренние сети,
+	// только для разработки и тестов с локальной заглушкой
+	AllowPrivateNetworks bool `yaml:"allow_private_networks" env-default:"false"`
+}
+
+type OutboxConfig struct {
+	// PollInterval как часто искать неопубликованные сообщения
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1s"`
+	// BatchSize сколько сообщений брать за раз
+	BatchSize int `yaml:"batch_size" env-default:"100"`
+	// PublishTimeout сколько ждать публикации пачки, после этого ее сообщения может взять другой экземпляр
+	PublishTimeout time.Duration `yaml:"publish_timeout" env-default:"30s"`
+	// RetryBackoff задержка перед первым повтором, дальше удваивается до MaxBackoff
+	RetryBackoff time.Duration `yaml:"retry_backoff" env-default:"1s"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" env-default:"10m"`
+	// Retention сколько хранить опубликованные сообщения, CleanupInterval - как часто удалять старые
+	Retention       time.Duration `yaml:"retention" env-default:"168h"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env-default:"1h"`
+	// Publishers куда публиковать кроме обработчиков внутри сервиса: log, file, nats, kafka
+	Publishers []string          `yaml:"publishers"`
+	File       string            `yaml:"file" env-default:"outbox.jsonl"`
+	NATS       OutboxNATSConfig  `yaml:"nats"`
+	Kafka      OutboxKafkaConfig `yaml:"kafka"`
+}
+
+type OutboxNATSConfig struct {
+	URL string `yaml:"url" env-default:"nats://localhost:4222"`
+	// SubjectPrefix к нему добавляется тип события: referrer.task.completed
+	SubjectPrefix string `yaml:"subject_prefix" env-default:"referrer."`
+}
+
+type OutboxKafkaConfig struct {
+	Brokers []string `yaml:"brokers" env-default:"localhost:9092"`
+	Topic   string   `yaml:"topic" env-default:"referrer-events"`
+}
+
+// VersioningConfig сроки маршрутов v1, у которых есть замена в v2: с Deprecated они отдают заголовок
+// Deprecation, после Sunset их могут убрать. Даты в формате 2006-01-02
+type VersioningConfig struct {
+	Deprecated   string    `yaml:"deprecated" env-default:"2026-10-18"`
+	Sunset       string    `yaml:"sunset" env-default:"2027-04-18"`
+	DeprecatedAt time.Time `yaml:"-"`
+	SunsetAt     time.Time `yaml:"-"`
+}
+
+// LevelConfig уровень, который дается с XP опыта. RewardPercent - сколько процентов награды за задание
+// получает пользователь этого уровня, ReferralPercent - сколько процентов награды за реферала
+type LevelConfig struct {
+	XP              int `yaml:"xp"`
+	RewardPercent   int `yaml:"reward_percent"`
+	ReferralPercent int `yaml:"referral_percent"`
 }
 
 func MustLoad() *Config {
@@ -34,6 +218,28 @@ func MustLoadPath(configPath string) *Config {
 		panic("cannot read config: " + err.Error())
 	}
 
+	loc, err := time.LoadLocation(cfg.LeaderBoard.Timezone)
+	if err != nil {
+		panic("cannot load leaderboard timezone: " + err.Error())
+	}
+	cfg.LeaderBoard.Location = loc
+
+	// процент 0 или меньше обнулил бы награды уровня или сделал бы их отрицательными
+	for i, level := range cfg.Levels {
+		if level.RewardPercent <= 0 || level.ReferralPercent <= 0 {
+			panic("levels[" + strconv.Itoa(i) + "]: reward_percent and referral_percent must be positive")
+		}
+	}
+
+	cfg.Versioning.DeprecatedAt, err = time.Parse(time.DateOnly, cfg.Versioning.Deprecated)
+	if err != nil {
+		panic("cannot parse versioning.deprecated: " + err.Error())
+	}
+	cfg.Versioning.SunsetAt, err = time.Parse(time.DateOnly, cfg.Versioning.Sunset)
+	if err != nil {
+		panic("cannot parse versioning.sunset: " + err.Error())
+	}
+
 	return &cfg
 }
 
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMustLoadPathRejectsNonPositiveLevelPercent(t *testing.T) {
+	data, err := os.ReadFile("../../../config/config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct{ level, broken string }{
+		{"reward_percent: 110", "reward_percent: 0"},
+		{"referral_percent: 120", "referral_percent: -10"},
+	} {
+		t.Run(tc.broken, func(t *testing.T) {
+			config := strings.Replace(string(data), tc.level, tc.broken, 1)
+			if config == string(data) {
+				t.Fatalf("config.yaml has no level with %s", tc.level)
+			}
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				if recover() == nil {
+					t.Errorf("MustLoadPath accepted %s", tc.broken)
+				}
+			}()
+			MustLoadPath(path)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+)
+
+const achievementColumns = "a.id, a.code, a.name, a.description, a.kind, a.threshold, a.bonus"
+
+func (d *DB) GetAchievements(ctx context.Context) ([]*types.Achievement, error) {
+	rows, err := d.Conn.Query(ctx, "select "+achievementColumns+" from achievements a order by a.id")
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.Achievement])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+func (d *DB) getUserAchievements(ctx context.Context, userID int) ([]*types.UserAchievement, error) {
+	rows, err := d.Conn.Query(ctx, "select "+achievementColumns+`, ua.awarded_at
+from user_achievements ua
+join achievements a on a.id = ua.achievement_id
+where ua.user_id = $1
+order by ua.awarded_at, a.id`, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.UserAchievement])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+// awardAchievements выдает пользователю еще не полученные достижения видов kinds, условия которых выполнены,
+// начисляет за них бонус и уведомляет. Должен вызываться в той же транзакции, что и событие, после которого
+// условия могли выполниться, пользователь должен быть заблокирован
+func (d *DB) awardAchievements(ctx context.Context, tx pgx.Tx, userID int, kinds ...string) error {
+	for _, kind := range kinds {
+		metric, err := d.achievementMetric(ctx, tx, userID, kind)
+		if err != nil {
+			return err
+		}
+		// место в таблице тем лучше, чем оно меньше
+		condition := "a.threshold <= $3"
+		if kind == types.AchievementLeaderBoardRank {
+			condition = "a.threshold >= $3"
+		}
+		rows, err := tx.Query(ctx, `with awarded as (
+    insert into user_achievements (user_id, achievement_id)
+    select $1, a.id from achievements a where a.kind = $2 and `+condition+`
+    on conflict do nothing
+    returning achievement_id
+)
+select `+achievementColumns+` from awarded join achievements a on a.id = awarded.achievement_id order by a.id`, userID, kind, metric)
+		if err != nil {
+			return errors.Wrap(err, "tx.Query failed: ")
+		}
+		awarded, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.Achievement])
+		if err != nil {
+			return errors.Wrap(err, "pgx.CollectRows failed: ")
+		}
+		for _, achievement := range awarded {
+			message := fmt.Sprintf("Получено достижение «%s»", achievement.Name)
+			if achievement.Bonus > 0 {
+				message += fmt.Sprintf(", начислено %s", achievement.Bonus)
+				if _, err = addBalance(ctx, tx, userID, types.CurrencyPoints, achievement.Bonus, types.RewardSourceAchievement, achievement.ID); err != nil {
+					return err
+				}
+				err = insertRewardEvent(ctx, tx, userID, achievement.Bonus, types.CurrencyPoints, types.RewardSourceAchievement, achievement.ID)
+				if err != nil {
+					return err
+				}
+				if err = d.addLot(ctx, tx, userID, achievement.Bonus, types.RewardSourceAchievement); err != nil {
+					return err
+				}
+			}
+			if err = insertNotification(ctx, tx, userID, types.NotificationAchievement, message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// achievementMetric считает показатель пользователя, по которому выдаются достижения вида kind
+func (d *DB) achievementMetric(ctx context.Context, tx pgx.Tx, userID int, kind string) (int, error) {
+	var query string
+	args := []any{userID}
+	switch kind {
+	case types.AchievementTasksCompleted:
+		query = "select count(*) from tasks_to_users where user_id = $1 and revoked_at is null"
+	case types.AchievementReferrals:
+		query = "select count(*) from users where referrer_id = $1 and referral_revoked_at is null"
+	case types.AchievementStreakDays:
+		// серия дней подряд, заканчивающаяся последним днем, когда пользователь выполнял задания
+		query = `with days as (select distinct (completed_at at time zone $2)::date as day
+              from tasks_to_users where user_id = $1 and completed_at is not null and revoked_at is null),
+     islands as (select day, day - (row_number() over (order by day))::int as island from days)
+select count(*) from islands where island = (select island from islands order by day desc limit 1)`
+		args = append(args, d.timezone)
+	case types.AchievementLeaderBoardRank:
+		query = "select count(*) + 1 from users where balance > (select balance from users where id = $1)"
+	default:
+		return 0, fmt.Errorf("unknown achievement kind %q", kind)
+	}
+	var metric int
+	err := tx.QueryRow(ctx, query, args...).Scan(&metric)
+	if err != nil {
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return metric, nil
+}
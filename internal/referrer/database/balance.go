@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// getBalance возвращает баланс пользователя в валюте currency. Баллы лежат в users.balance,
+// остальные валюты - в user_balances. Пользователь должен быть заблокирован в той же транзакции
+func getBalance(ctx context.Context, tx pgx.Tx, userID int, currency string) (types.Amount, error) {
+	var balance types.Amount
+	var err error
+	if currency == types.CurrencyPoints {
+		err = tx.QueryRow(ctx, "select balance from users where id = $1", userID).Scan(&balance)
+	} else {
+		err = tx.QueryRow(ctx, "select coalesce((select amount from user_balances where user_id = $1 and currency = $2), 0)", userID, currency).Scan(&balance)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrUserNotExist
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return balance, nil
+}
+
+// addBalance прибавляет amount к балансу пользователя в валюте currency, записывает движение вида kind
+// в ledger_entries и возвращает новый баланс. Все изменения баланса проходят через него, чтобы журнал
+// сходился с балансом. Должен вызываться в транзакции, в которой пользователь заблокирован
+func addBalance(ctx context.Context, tx pgx.Tx, userID int, currency string, amount types.Amount, kind string, referenceID int) (types.Amount, error) {
+	var balance types.Amount
+	var err error
+	if currency == types.CurrencyPoints {
+		err = tx.QueryRow(ctx, "update users set balance = balance + $2 where id = $1 returning balance", userID, amount).Scan(&balance)
+	} else {
+		err = tx.QueryRow(ctx, `insert into user_balances (user_id, currency, amount) values ($1, $2, $3)
+on conflict (user_id, currency) do update set amount = user_balances.amount + excluded.amount
+returning amount`, userID, currency, amount).Scan(&balance)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrUserNotExist
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+		return 0, ErrUnknownCurrency
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	_, err = tx.Exec(ctx, "insert into ledger_entries (user_id, amount, currency, kind, reference_id) values ($1, $2, $3, $4, $5)", userID, amount, currency, kind, referenceID)
+	if err != nil {
+		return 0, errors.Wrap(err, "insert into ledger_entries failed: ")
+	}
+	return balance, nil
+}
+
+// getBalances возвращает балансы пользователя во всех валютах, в которых у него что-то есть, и в баллах
+func (d *DB) getBalances(ctx context.Context, userID int, points types.Amount) (map[string]types.Amount, error) {
+	rows, err := d.Conn.Query(ctx, "select currency, amount from user_balances where user_id = $1", userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	defer rows.Close()
+	balances := map[string]types.Amount{types.CurrencyPoints: points}
+	for rows.Next() {
+		var currency string
+		var amount types.Amount
+		if err = rows.Scan(&currency, &amount); err != nil {
+			return nil, errors.Wrap(err, "rows.Scan failed: ")
+		}
+		balances[currency] = amount
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows.Err: ")
+	}
+	return balances, nil
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+)
+
+// пакетные выборки сразу по многим id, чтобы GraphQL не ходил в базу за каждым объектом отдельно.
+// Порядок результата не гарантируется, отсутствующих id в нем просто нет
+
+func (d *DB) GetUsersByIDs(ctx context.Context, userIDs []int) ([]*types.User, error) {
+	rows, err := d.Conn.Query(ctx, "select id, first_name, last_name, user_name, referrer_code, balance, role, referrer_id from users where id = any($1)", userIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, scanUser)
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+// GetRefereesByUserIDs возвращает всех, кого пригласили пользователи userIDs, у отозванных рефералов связь остается
+func (d *DB) GetRefereesByUserIDs(ctx context.Context, userIDs []int) ([]*types.User, error) {
+	rows, err := d.Conn.Query(ctx, "select id, first_name, last_name, user_name, referrer_code, balance, role, referrer_id from users where referrer_id = any($1) order by id", userIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, scanUser)
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+func scanUser(row pgx.CollectableRow) (*types.User, error) {
+	user := &types.User{}
+	err := row.Scan(&user.ID, &user.FirstName, &user.LastName, &user.UserName, &user.ReferrerCode, &user.Balance, &user.Role, &user.ReferrerID)
+	return user, err
+}
+
+// GetUserRanks возвращает места пользователей в общей таблице лидеров
+func (d *DB) GetUserRanks(ctx context.Context, userIDs []int) ([]*types.RankedUser, error) {
+	rows, err := d.Conn.Query(ctx, "with ranked as ("+leaderBoardQuery+") select * from ranked where id = any($1)", userIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.RankedUser])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+// GetCompletionsByUserIDs возвращает неотозванные выполнения заданий пользователями userIDs
+func (d *DB) GetCompletionsByUserIDs(ctx context.Context, userIDs []int) ([]*types.Completion, error) {
+	rows, err := d.Conn.Query(ctx, "select user_id, task_id, completed_at from tasks_to_users where user_id = any($1) and revoked_at is null order by completed_at nulls first, id", userIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[types.Completion])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+func (d *DB) GetTasksByIDs(ctx context.Context, taskIDs []int) ([]*types.Task, error) {
+	rows, err := d.Conn.Query(ctx, "select id, description, reward, currency from tasks where id = any($1)", taskIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.Task])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
@@ -0,0 +1,231 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// RevokeCompletion отзывает награду за выполненное задание. Задание остается выполненным,
+// но повторно выполнить его нельзя. Если allowNegative == false, списывается не больше текущего баланса
+func (d *DB) RevokeCompletion(ctx context.Context, userID, taskID int, reason string, allowNegative bool) (*types.Clawback, error) {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	var lockedID int
+	err = tx.QueryRow(ctx, "select id from users where id = $1 for update", userID).Scan(&lockedID)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotExist
+		}
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	var revoked bool
+	err = tx.QueryRow(ctx, "select revoked_at is not null from tasks_to_users where user_id = $1 and task_id = $2 for update", userID, taskID).Scan(&revoked)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCompletionNotExist
+		}
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if revoked {
+		rollback()
+		return nil, ErrAlreadyRevoked
+	}
+
+	// награду берем из журнала начислений, для старых выполнений, которых там нет, - текущую награду задания
+	var amount types.Amount
+	var currency string
+	err = tx.QueryRow(ctx, `select coalesce((select sum(amount) from reward_events where user_id = $1 and source = $3 and reference_id = $2), t.reward), t.currency
+from tasks t where t.id = $2`, userID, taskID, types.RewardSourceTask).Scan(&amount, &currency)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	balance, err := getBalance(ctx, tx, userID, currency)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	_, err = tx.Exec(ctx, "update tasks_to_users set revoked_at = now() where user_id = $1 and task_id = $2", userID, taskID)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "tx.Exec failed: ")
+	}
+	clawback, err := clawBack(ctx, tx, userID, currency, balance, amount, types.ClawbackKindTask, taskID, reason, allowNegative)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	// баллы за задания шли и в очки команды, забираем их оттуда так же как из сезонов
+	if currency == types.CurrencyPoints {
+		err = revokeTeamPoints(ctx, tx, userID, amount)
+		if err != nil {
+			rollback()
+			return nil, err
+		}
+	}
+	return clawback, tx.Commit(ctx)
+}
+
+// RevokeReferral отзывает награды, начисленные рефералу refereeID и его рефереру за ввод реферального кода.
+// Для старых начислений, которых нет в журнале, списывается fallbackReward
+func (d *DB) RevokeReferral(ctx context.Context, refereeID int, reason string, allowNegative bool, fallbackReward types.Amount) ([]*types.Clawback, error) {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	var referrerID *int
+	var revoked bool
+	err = tx.QueryRow(ctx, "select referrer_id, referral_revoked_at is not null from users where id = $1", refereeID).Scan(&referrerID, &revoked)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotExist
+		}
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if referrerID == nil {
+		rollback()
+		return nil, ErrReferralNotExist
+	}
+
+	// блокируем обоих всегда в порядке id, как и при создании реферала
+	rows, err := tx.Query(ctx, "select id, balance, referral_revoked_at is not null from users where id in ($1, $2) order by id for update", refereeID, *referrerID)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "tx.Query failed: ")
+	}
+	balances := make(map[int]types.Amount, 2)
+	for rows.Next() {
+		var id int
+		var balance types.Amount
+		var userRevoked bool
+		if err = rows.Scan(&id, &balance, &userRevoked); err != nil {
+			rows.Close()
+			rollback()
+			return nil, errors.Wrap(err, "rows.Scan failed: ")
+		}
+		balances[id] = balance
+		if id == refereeID {
+			revoked = userRevoked
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "rows.Err: ")
+	}
+	if revoked {
+		rollback()
+		return nil, ErrAlreadyRevoked
+	}
+
+	_, err = tx.Exec(ctx, "update users set referral_revoked_at = now() where id = $1", refereeID)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "tx.Exec failed: ")
+	}
+	result := make([]*types.Clawback, 0, 2)
+	for _, id := range []int{refereeID, *referrerID} {
+		var amount types.Amount
+		err = tx.QueryRow(ctx, "select coalesce(sum(amount), $4) from reward_events where user_id = $1 and source = $2 and reference_id = $3",
+			id, types.RewardSourceReferral, refereeID, fallbackReward).Scan(&amount)
+		if err != nil {
+			rollback()
+			return nil, errors.Wrap(err, "row.Scan failed: ")
+		}
+		clawback, err := clawBack(ctx, tx, id, types.CurrencyPoints, balances[id], amount, types.ClawbackKindReferral, refereeID, reason, allowNegative)
+		if err != nil {
+			rollback()
+			return nil, err
+		}
+		result = append(result, clawback)
+	}
+	return result, tx.Commit(ctx)
+}
+
+func (d *DB) GetClawbacks(ctx context.Context, userID int) ([]*types.Clawback, error) {
+	rows, err := d.Conn.Query(ctx, "select id, user_id, kind, reference_id, currency, amount, clawed, reason, created_at from clawbacks where ($1 = 0 or user_id = $1) order by created_at desc, id desc", userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.Clawback])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+// clawBack списывает amount в валюте currency с заблокированного пользователя и пишет компенсирующие записи:
+// отрицательное начисление, движение по балансу, уменьшение очков идущих сезонов и опыта, уведомление
+func clawBack(ctx context.Context, tx pgx.Tx, userID int, currency string, balance, amount types.Amount, kind string, referenceID int, reason string, allowNegative bool) (*types.Clawback, error) {
+	clawed := amount
+	if !allowNegative {
+		clawed = min(amount, max(balance, 0))
+	}
+	rows, err := tx.Query(ctx, `insert into clawbacks (user_id, kind, reference_id, currency, amount, clawed, reason) values ($1, $2, $3, $4, $5, $6, $7)
+returning id, user_id, kind, reference_id, currency, amount, clawed, reason, created_at`, userID, kind, referenceID, currency, amount, clawed, reason)
+	if err != nil {
+		return nil, errors.Wrap(err, "tx.Query failed: ")
+	}
+	clawback, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[types.Clawback])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectOneRow failed: ")
+	}
+	// в таблицах лидеров и сезонах отзывается вся награда, даже если с баланса удалось списать только часть
+	err = insertRewardEvent(ctx, tx, userID, -amount, currency, types.RewardSourceClawback, clawback.ID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = addBalance(ctx, tx, userID, currency, -clawed, types.LedgerClawback, clawback.ID)
+	if err != nil {
+		return nil, err
+	}
+	if currency == types.CurrencyPoints {
+		_, err = consumeLots(ctx, tx, userID, clawed)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.Exec(ctx, `update season_points set points = greatest(points - $2, 0)
+where user_id = $1 and season_id in (select id from seasons where closed_at is null)`, userID, amount)
+		if err != nil {
+			return nil, errors.Wrap(err, "tx.Exec failed: ")
+		}
+		// опыт за отозванную награду тоже забирается, уровень считается по опыту и понизится сам
+		_, err = tx.Exec(ctx, "update users set xp = greatest(xp - $2, 0) where id = $1", userID, amount)
+		if err != nil {
+			return nil, errors.Wrap(err, "tx.Exec failed: ")
+		}
+	}
+	message := fmt.Sprintf("Начисление %s %s отозвано администратором, списано %s", amount, currency, clawed)
+	if reason != "" {
+		message += ". Причина: " + reason
+	}
+	err = insertNotification(ctx, tx, userID, types.NotificationClawback, message)
+	if err != nil {
+		return nil, err
+	}
+	return clawback, nil
+}
@@ -7,3 +7,45 @@ var ErrUserNotExist = errors.New("user not exist")
 var ErrTaskNotExist = errors.New("task not exist")
 var ErrTaskAlreadyExist = errors.New("task already exist")
 var ErrAlreadyCompletedTask = errors.New("task already completed")
+
+var ErrAlreadyReferred = errors.New("user already entered referrer code")
+var ErrSelfReferral = errors.New("user can not enter own referrer code")
+var ErrReferralCycle = errors.New("referrer code belongs to user's referee")
+
+var ErrSeasonNotExist = errors.New("season not exist")
+var ErrSeasonAlreadyClosed = errors.New("season already closed")
+var ErrSeasonNotStarted = errors.New("season not started")
+
+var ErrItemNotExist = errors.New("item not exist")
+var ErrItemAlreadyExist = errors.New("item already exist")
+var ErrOutOfStock = errors.New("item out of stock")
+var ErrPurchaseLimitExceeded = errors.New("item purchase limit exceeded")
+var ErrInsufficientBalance = errors.New("insufficient balance")
+var ErrOrderNotExist = errors.New("order not exist")
+var ErrOrderNotPending = errors.New("order is not pending")
+
+var ErrSelfTransfer = errors.New("user can not transfer to himself")
+var ErrAccountTooNew = errors.New("account is too new to transfer")
+var ErrTransferLimitExceeded = errors.New("daily transfer limit exceeded")
+var ErrIdempotencyKeyReused = errors.New("idempotency key reused with different request")
+var ErrIdempotentRequestInProgress = errors.New("request with this idempotency key is in progress")
+
+var ErrCompletionNotExist = errors.New("task completion not exist")
+var ErrReferralNotExist = errors.New("referral not exist")
+var ErrAlreadyRevoked = errors.New("reward already revoked")
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+var ErrEarningLimitExceeded = errors.New("earning limit exceeded")
+
+var ErrTeamNotExist = errors.New("team not exist")
+var ErrTeamAlreadyExist = errors.New("team already exist")
+var ErrAlreadyInTeam = errors.New("user already in team")
+var ErrNotInTeam = errors.New("user not in team")
+var ErrTeamFull = errors.New("team is full")
+
+var ErrRaffleNotExist = errors.New("raffle not exist")
+var ErrRaffleClosed = errors.New("raffle closed")
+var ErrTicketLimitExceeded = errors.New("raffle ticket limit exceeded")
+
+var ErrWebhookNotExist = errors.New("webhook not exist")
+var ErrWebhookDeliveryNotExist = errors.New("webhook delivery not exist")
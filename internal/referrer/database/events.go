@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+)
+
+// Notify отправляет payload всем, кто слушает channel, включая этот экземпляр
+func (d *DB) Notify(ctx context.Context, channel, payload string) error {
+	_, err := d.Conn.Exec(ctx, "select pg_notify($1, $2)", channel, payload)
+	if err != nil {
+		return errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	return nil
+}
+
+// Listen слушает channel на отдельном соединении и вызывает handle на каждое уведомление,
+// пока не отменен ctx или не оборвалось соединение. Соединение забирается из пула насовсем,
+// чтобы подписка не досталась другим запросам
+func (d *DB) Listen(ctx context.Context, channel string, handle func(payload string)) error {
+	pooled, err := d.Conn.Acquire(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Conn.Acquire failed: ")
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "listen "+pgx.Identifier{channel}.Sanitize())
+	if err != nil {
+		return errors.Wrap(err, "conn.Exec failed: ")
+	}
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return errors.Wrap(err, "conn.WaitForNotification failed: ")
+		}
+		handle(notification.Payload)
+	}
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+)
+
+// StartIdempotentRequest резервирует ключ под запрос. Если ключ новый возвращает nil,
+// если запрос с этим ключом уже выполнен - сохраненный ответ
+func (d *DB) StartIdempotentRequest(ctx context.Context, scope, key, fingerprint string, expiresAt time.Time) (*types.IdempotentResponse, error) {
+	_, err := d.Conn.Exec(ctx, "delete from idempotency_keys where scope = $1 and key = $2 and expires_at < now()", scope, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	var inserted string
+	err = d.Conn.QueryRow(ctx, "insert into idempotency_keys (scope, key, fingerprint, expires_at) values ($1, $2, $3, $4) on conflict (scope, key) do nothing returning key",
+		scope, key, fingerprint, expiresAt).Scan(&inserted)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+
+	var storedFingerprint string
+	var statusCode *int
+	response := &types.IdempotentResponse{}
+	err = d.Conn.QueryRow(ctx, "select fingerprint, status_code, coalesce(content_type, ''), response_body from idempotency_keys where scope = $1 and key = $2", scope, key).
+		Scan(&storedFingerprint, &statusCode, &response.ContentType, &response.Body)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// ключ успели удалить, пока мы его читали
+		return nil, ErrIdempotentRequestInProgress
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if storedFingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if statusCode == nil {
+		return nil, ErrIdempotentRequestInProgress
+	}
+	response.StatusCode = *statusCode
+	return response, nil
+}
+
+// FinishIdempotentRequest сохраняет ответ, который будет отдаваться на повторы запроса
+func (d *DB) FinishIdempotentRequest(ctx context.Context, scope, key string, response *types.IdempotentResponse) error {
+	_, err := d.Conn.Exec(ctx, "update idempotency_keys set status_code = $3, content_type = $4, response_body = $5 where scope = $1 and key = $2",
+		scope, key, response.StatusCode, response.ContentType, response.Body)
+	if err != nil {
+		return errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	return nil
+}
+
+// ReleaseIdempotentRequest освобождает ключ, если запрос не удался и его можно повторить
+func (d *DB) ReleaseIdempotentRequest(ctx context.Context, scope, key string) error {
+	_, err := d.Conn.Exec(ctx, "delete from idempotency_keys where scope = $1 and key = $2", scope, key)
+	if err != nil {
+		return errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	return nil
+}
+
+func (d *DB) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := d.Conn.Exec(ctx, "delete from idempotency_keys where expires_at < $1", now)
+	if err != nil {
+		return 0, errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// periodLeaderBoardQuery ранжирует пользователей по тому, сколько баллов они заработали в промежутке [$1, $2)
+const periodLeaderBoardQuery = `select u.id, u.first_name, u.last_name, u.user_name, u.balance, e.earned,
+       rank() over (order by e.earned desc) as rank,
+       dense_rank() over (order by e.earned desc) as dense_rank,
+       row_number() over (order by e.earned desc, u.id) as position
+from (select user_id, sum(amount) as earned
+      from reward_events
+      where created_at >= $1 and created_at < $2 and currency = 'points'
+      group by user_id) e
+join users u on u.id = e.user_id`
+
+// periodBound граница промежутка для periodLeaderBoardQuery, нулевое время - открытая граница infinity
+func periodBound(t time.Time, infinity pgtype.InfinityModifier) pgtype.Timestamptz {
+	if t.IsZero() {
+		return pgtype.Timestamptz{InfinityModifier: infinity, Valid: true}
+	}
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+// GetPeriodLeaderBoard ранжирует по заработку в промежутке [from, to), нулевые from и to - без ограничения,
+// с обоими нулевыми - за все время
+func (d *DB) GetPeriodLeaderBoard(ctx context.Context, from, to time.Time, limit, offset int) ([]*types.PeriodRankedUser, int, error) {
+	start, end := periodBound(from, pgtype.NegativeInfinity), periodBound(to, pgtype.Infinity)
+	rows, err := d.Conn.Query(ctx, periodLeaderBoardQuery+" order by position limit $3 offset $4", start, end, limit, offset)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.PeriodRankedUser])
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	var total int
+	err = d.Conn.QueryRow(ctx, "select count(distinct user_id) from reward_events where created_at >= $1 and created_at < $2 and currency = $3", start, end, types.CurrencyPoints).Scan(&total)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return result, total, nil
+}
+
+// GetLeaderBoardSnapshot возвращает зафиксированные итоги периода, если снимка нет total будет 0
+func (d *DB) GetLeaderBoardSnapshot(ctx context.Context, period types.Period, from time.Time, limit, offset int) ([]*types.PeriodRankedUser, int, error) {
+	var total int
+	err := d.Conn.QueryRow(ctx, "select count(*) from leaderboard_snapshots where period = $1 and period_start = $2", period, from).Scan(&total)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+	rows, err := d.Conn.Query(ctx, `select u.id, u.first_name, u.last_name, u.user_name, u.balance, s.earned, s.rank, s.dense_rank, s.position
+from leaderboard_snapshots s
+join users u on u.id = s.user_id
+where s.period = $1 and s.period_start = $2
+order by s.position limit $3 offset $4`, period, from, limit, offset)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.PeriodRankedUser])
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, total, nil
+}
+
+// SnapshotLeaderBoard фиксирует итоги закрытого периода, повторный вызов для того же периода ничего не меняет
+func (d *DB) SnapshotLeaderBoard(ctx context.Context, period types.Period, from, to time.Time) (int64, error) {
+	tag, err := d.Conn.Exec(ctx, `insert into leaderboard_snapshots (period, period_start, period_end, user_id, earned, rank, dense_rank, position)
+select $3, $1, $2, id, earned, rank, dense_rank, position from (`+periodLeaderBoardQuery+`) ranked
+on conflict (period, period_start, user_id) do nothing`, from, to, period)
+	if err != nil {
+		return 0, errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetSnapshotBackfillStart возвращает время первого начисления после конца последнего снимка периода,
+// с него начинаются незафиксированные периоды. nil - фиксировать нечего
+func (d *DB) GetSnapshotBackfillStart(ctx context.Context, period types.Period) (*time.Time, error) {
+	var start *time.Time
+	err := d.Conn.QueryRow(ctx, `select min(created_at) from reward_events
+where currency = $2 and created_at >= coalesce((select max(period_end) from leaderboard_snapshots where period = $1), '-infinity')`,
+		period, types.CurrencyPoints).Scan(&start)
+	if err != nil {
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return start, nil
+}
+
+// insertRewardEvent записывает начисление, должен вызываться в той же транзакции что и изменение баланса.
+// referenceID - id задания, реферала или сезона, за которое начислено, 0 если не к чему привязать
+func insertRewardEvent(ctx context.Context, tx pgx.Tx, userID int, amount types.Amount, currency, source string, referenceID int) error {
+	_, err := tx.Exec(ctx, "insert into reward_events (user_id, amount, currency, source, reference_id) values ($1, $2, $3, $4, nullif($5, 0))", userID, amount, currency, source, referenceID)
+	if err != nil {
+		return errors.Wrap(err, "insert into reward_events failed: ")
+	}
+	return nil
+}
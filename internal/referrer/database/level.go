@@ -0,0 +1,34 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+)
+
+// addXP добавляет пользователю с опытом xp еще gained опыта. Если он перешел на новый уровень,
+// записывает это в level_ups и уведомляет. Пользователь должен быть заблокирован в той же транзакции
+func addXP(ctx context.Context, tx pgx.Tx, userID int, xp, gained types.Amount, levels types.Levels) error {
+	if gained <= 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, "update users set xp = xp + $2 where id = $1", userID, gained)
+	if err != nil {
+		return errors.Wrap(err, "tx.Exec failed: ")
+	}
+	before, after := levels.For(xp), levels.For(xp+gained)
+	for level := before.Number + 1; level <= after.Number; level++ {
+		_, err = tx.Exec(ctx, "insert into level_ups (user_id, level) values ($1, $2)", userID, level)
+		if err != nil {
+			return errors.Wrap(err, "insert into level_ups failed: ")
+		}
+	}
+	if after.Number > before.Number {
+		message := fmt.Sprintf("Достигнут уровень %d: награды за задания %d%%, за рефералов %d%%", after.Number, after.RewardPercent, after.ReferralPercent)
+		return insertNotification(ctx, tx, userID, types.NotificationLevelUp, message)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+)
+
+// LimitExceededError сработало ограничение на заработок Limit, повторить можно через RetryAfter
+type LimitExceededError struct {
+	Limit      string
+	RetryAfter time.Duration
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("earning limit %s exceeded, retry after %s", e.Limit, e.RetryAfter)
+}
+
+func (e *LimitExceededError) Is(target error) bool {
+	return target == ErrEarningLimitExceeded
+}
+
+// checkCompletionLimits проверяет перерыв между заданиями и число заданий за последний час без учета
+// только что записанного выполнения completionID. Пользователь должен быть заблокирован в той же транзакции
+func checkCompletionLimits(ctx context.Context, tx pgx.Tx, userID, completionID int, limits types.EarningLimits, now time.Time) error {
+	if limits.Cooldown > 0 {
+		var last *time.Time
+		err := tx.QueryRow(ctx, "select max(completed_at) from tasks_to_users where user_id = $1 and id <> $2", userID, completionID).Scan(&last)
+		if err != nil {
+			return errors.Wrap(err, "row.Scan failed: ")
+		}
+		if last != nil && now.Sub(*last) < limits.Cooldown {
+			return &LimitExceededError{Limit: types.LimitCooldown, RetryAfter: last.Add(limits.Cooldown).Sub(now)}
+		}
+	}
+	if limits.MaxCompletionsPerHour > 0 {
+		var count int
+		var oldest *time.Time
+		err := tx.QueryRow(ctx, "select count(*), min(completed_at) from tasks_to_users where user_id = $1 and id <> $2 and completed_at > $3",
+			userID, completionID, now.Add(-time.Hour)).Scan(&count, &oldest)
+		if err != nil {
+			return errors.Wrap(err, "row.Scan failed: ")
+		}
+		if count >= limits.MaxCompletionsPerHour && oldest != nil {
+			return &LimitExceededError{Limit: types.LimitHourlyCompletions, RetryAfter: oldest.Add(time.Hour).Sub(now)}
+		}
+	}
+	return nil
+}
+
+// dailyRewardLeft сколько баллов пользователь еще может заработать на заданиях и рефералах сегодня.
+// ok == false, если дневного лимита нет
+func dailyRewardLeft(ctx context.Context, tx pgx.Tx, userID int, limits types.EarningLimits, policy types.EarningPolicy) (types.Amount, bool, error) {
+	if limits.MaxDailyReward <= 0 {
+		return 0, false, nil
+	}
+	var earned types.Amount
+	err := tx.QueryRow(ctx, `select coalesce(sum(amount), 0) from reward_events
+where user_id = $1 and currency = $2 and source in ($3, $4) and created_at >= $5 and created_at < $6`,
+		userID, types.CurrencyPoints, types.RewardSourceTask, types.RewardSourceReferral, policy.DayStart, policy.DayEnd).Scan(&earned)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return max(limits.MaxDailyReward-earned, 0), true, nil
+}
+
+// checkDailyReward отклоняет начисление reward баллов, если с ним будет превышен дневной лимит
+func checkDailyReward(ctx context.Context, tx pgx.Tx, userID int, reward types.Amount, limits types.EarningLimits, policy types.EarningPolicy) error {
+	left, ok, err := dailyRewardLeft(ctx, tx, userID, limits, policy)
+	if err != nil || !ok {
+		return err
+	}
+	if reward > left {
+		return &LimitExceededError{Limit: types.LimitDailyReward, RetryAfter: policy.DayEnd.Sub(policy.Now)}
+	}
+	return nil
+}
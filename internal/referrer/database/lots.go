@@ -0,0 +1,222 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// uncoveredBalance сколько баланса пользователя не покрыто партиями. После отзыва в минус баланс меньше суммы
+// партий, и новые поступления сначала гасят долг, а в партии идет только то, что осталось сверху
+func uncoveredBalance(ctx context.Context, tx pgx.Tx, userID int) (types.Amount, error) {
+	var uncovered types.Amount
+	err := tx.QueryRow(ctx, `select u.balance - coalesce((select sum(remaining) from balance_lots where user_id = u.id and remaining > 0), 0)
+from users u where u.id = $1`, userID).Scan(&uncovered)
+	if err != nil {
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return uncovered, nil
+}
+
+// addLot записывает поступление на баланс отдельной партией со своим сроком сгорания,
+// должен вызываться в той же транзакции после изменения баланса. Партия не больше непокрытого партиями баланса
+func (d *DB) addLot(ctx context.Context, tx pgx.Tx, userID int, amount types.Amount, source string) error {
+	if amount <= 0 {
+		return nil
+	}
+	uncovered, err := uncoveredBalance(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+	amount = min(amount, uncovered)
+	if amount <= 0 {
+		return nil
+	}
+	_, err = tx.Exec(ctx, `insert into balance_lots (user_id, amount, remaining, source, expires_at)
+values ($1, $2, $2, $3, case when $4::int > 0 then now() + make_interval(months => $4::int) end)`, userID, amount, source, d.expirationMonths)
+	if err != nil {
+		return errors.Wrap(err, "insert into balance_lots failed: ")
+	}
+	return nil
+}
+
+// lotPart часть партии, списанная consumeLots, expiresAt == nil - не сгорает
+type lotPart struct {
+	amount    types.Amount
+	expiresAt *time.Time
+}
+
+// moveLots записывает поступление amount партиями с теми же сроками сгорания, что у частей parts,
+// чтобы перевод или возврат не продлевал срок баллов. Как и addLot, сначала гасит долг после отзыва в минус,
+// то, что частями не покрыто, получает обычный срок
+func (d *DB) moveLots(ctx context.Context, tx pgx.Tx, userID int, amount types.Amount, parts []lotPart, source string) error {
+	uncovered, err := uncoveredBalance(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+	amount = min(amount, uncovered)
+	for _, part := range parts {
+		take := min(amount, part.amount)
+		if take <= 0 {
+			break
+		}
+		_, err = tx.Exec(ctx, "insert into balance_lots (user_id, amount, remaining, source, expires_at) values ($1, $2, $2, $3, $4)",
+			userID, take, source, part.expiresAt)
+		if err != nil {
+			return errors.Wrap(err, "insert into balance_lots failed: ")
+		}
+		amount -= take
+	}
+	return d.addLot(ctx, tx, userID, amount, source)
+}
+
+// consumeLots списывает amount с партий пользователя, начиная с тех, что сгорают раньше, и возвращает списанные части.
+// Пользователь должен быть заблокирован в той же транзакции
+func consumeLots(ctx context.Context, tx pgx.Tx, userID int, amount types.Amount) ([]lotPart, error) {
+	if amount <= 0 {
+		return nil, nil
+	}
+	rows, err := tx.Query(ctx, "select id, remaining, expires_at from balance_lots where user_id = $1 and remaining > 0 order by expires_at nulls last, created_at, id for update", userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "tx.Query failed: ")
+	}
+	type lot struct {
+		id        int
+		remaining types.Amount
+		expiresAt *time.Time
+	}
+	var lots []lot
+	for rows.Next() {
+		var l lot
+		if err = rows.Scan(&l.id, &l.remaining, &l.expiresAt); err != nil {
+			rows.Close()
+			return nil, errors.Wrap(err, "rows.Scan failed: ")
+		}
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows.Err: ")
+	}
+	var parts []lotPart
+	for _, l := range lots {
+		if amount == 0 {
+			break
+		}
+		take := min(amount, l.remaining)
+		_, err = tx.Exec(ctx, "update balance_lots set remaining = remaining - $2 where id = $1", l.id, take)
+		if err != nil {
+			return nil, errors.Wrap(err, "tx.Exec failed: ")
+		}
+		parts = append(parts, lotPart{amount: take, expiresAt: l.expiresAt})
+		amount -= take
+	}
+	return parts, nil
+}
+
+// ScheduleLegacyLots назначает срок сгорания партиям, которые миграция завела из балансов до появления партий:
+// months месяцев с момента миграции. Миграция не знает настройки срока и оставляет его пустым
+func (d *DB) ScheduleLegacyLots(ctx context.Context) error {
+	_, err := d.Conn.Exec(ctx, `update balance_lots set expires_at = created_at + make_interval(months => $1::int)
+where expires_at is null and remaining > 0 and source = $2 and $1::int > 0`, d.expirationMonths, types.LotSourceLegacy)
+	if err != nil {
+		return errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	return nil
+}
+
+// GetUsersWithExpiredLots возвращает до limit пользователей, у которых к моменту now есть сгоревшие партии
+func (d *DB) GetUsersWithExpiredLots(ctx context.Context, now time.Time, limit int) ([]int, error) {
+	rows, err := d.Conn.Query(ctx, "select distinct user_id from balance_lots where remaining > 0 and expires_at <= $1 limit $2", now, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+// ExpireLots сжигает остатки партий пользователя, срок которых вышел к моменту now, и списывает их с баланса
+func (d *DB) ExpireLots(ctx context.Context, userID int, now time.Time) (types.Amount, error) {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	// сначала пользователь, потом партии - в том же порядке, что и при списании
+	_, err = tx.Exec(ctx, "select id from users where id = $1 for update", userID)
+	if err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "tx.Exec failed: ")
+	}
+	rows, err := tx.Query(ctx, `update balance_lots l set remaining = 0, expired_at = $2
+from (select id, remaining from balance_lots where user_id = $1 and remaining > 0 and expires_at <= $2 for update) old
+where l.id = old.id
+returning l.id, old.remaining`, userID, now)
+	if err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "tx.Query failed: ")
+	}
+	type expired struct {
+		id     int
+		amount types.Amount
+	}
+	var lots []expired
+	var total types.Amount
+	for rows.Next() {
+		var e expired
+		if err = rows.Scan(&e.id, &e.amount); err != nil {
+			rows.Close()
+			rollback()
+			return 0, errors.Wrap(err, "rows.Scan failed: ")
+		}
+		lots = append(lots, e)
+		total += e.amount
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "rows.Err: ")
+	}
+	if total == 0 {
+		rollback()
+		return 0, nil
+	}
+	for _, e := range lots {
+		_, err = addBalance(ctx, tx, userID, types.CurrencyPoints, -e.amount, types.LedgerExpiry, e.id)
+		if err != nil {
+			rollback()
+			return 0, err
+		}
+	}
+	return total, tx.Commit(ctx)
+}
+
+// GetUpcomingExpirations возвращает сколько баллов сгорит до момента before, сгруппировано по дате сгорания
+func (d *DB) GetUpcomingExpirations(ctx context.Context, userID int, before time.Time) ([]*types.Expiration, error) {
+	rows, err := d.Conn.Query(ctx, `select sum(remaining) as amount, expires_at
+from balance_lots
+where user_id = $1 and remaining > 0 and expires_at <= $2
+group by expires_at
+order by expires_at`, userID, before)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.Expiration])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// newTestDB подключается к TEST_DATABASE_URL и накатывает миграции в отдельную схему, которая удаляется после теста.
+// Без TEST_DATABASE_URL тест пропускается
+func newTestDB(t *testing.T, expirationMonths int) *DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+
+	admin, err := pgx.Connect(ctx, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = admin.Exec(ctx, "create schema "+schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(ctx, "drop schema "+schema+" cascade"); err != nil {
+			t.Error(err)
+		}
+		admin.Close(ctx)
+	})
+
+	cfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	migrations, err := filepath.Glob("../../../migrations/*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := func(path string) int {
+		n, _ := strconv.Atoi(strings.SplitN(filepath.Base(path), "_", 2)[0])
+		return n
+	}
+	sort.Slice(migrations, func(i, j int) bool { return version(migrations[i]) < version(migrations[j]) })
+	for _, path := range migrations {
+		sql, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = pool.Exec(ctx, string(sql), pgx.QueryExecModeSimpleProtocol); err != nil {
+			t.Fatalf("%s: %v", filepath.Base(path), err)
+		}
+	}
+	return &DB{Conn: pool, logger: zap.NewNop(), expirationMonths: expirationMonths, timezone: "UTC"}
+}
+
+// inTx выполняет fn в транзакции с заблокированным пользователем
+func inTx(t *testing.T, d *DB, userID int, fn func(tx pgx.Tx) error) {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+	if _, err = tx.Exec(ctx, "select id from users where id = $1 for update", userID); err != nil {
+		t.Fatal(err)
+	}
+	if err = fn(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkLots проверяет, что баланс равен want, сходится с журналом движений, а партии покрывают ровно его положительную часть
+func checkLots(t *testing.T, d *DB, userID int, want types.Amount) {
+	t.Helper()
+	var balance, ledger, lots types.Amount
+	err := d.Conn.QueryRow(context.Background(), `select balance,
+coalesce((select sum(amount) from ledger_entries where user_id = $1 and currency = 'points'), 0),
+coalesce((select sum(remaining) from balance_lots where user_id = $1), 0)
+from users where id = $1`, userID).Scan(&balance, &ledger, &lots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != want || ledger != balance || lots != max(balance, 0) {
+		t.Errorf("balance %s, ledger %s, lots %s, want balance %s matched by ledger and covered by lots", balance, ledger, lots, want)
+	}
+}
+
+func TestLotsFollowBalanceThroughNegativeClawback(t *testing.T) {
+	d := newTestDB(t, 12)
+	ctx := context.Background()
+	var userID int
+	err := d.Conn.QueryRow(ctx, "insert into users (first_name, last_name, user_name, password, balance) values ('', '', 'lots', '', 0) returning id").Scan(&userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	earn := func(amount types.Amount) {
+		inTx(t, d, userID, func(tx pgx.Tx) error {
+			if _, err := addBalance(ctx, tx, userID, types.CurrencyPoints, amount, types.RewardSourceTask, 0); err != nil {
+				return err
+			}
+			return d.addLot(ctx, tx, userID, amount, types.RewardSourceTask)
+		})
+	}
+
+	earn(types.NewAmount(100))
+	checkLots(t, d, userID, types.NewAmount(100))
+
+	// отзыв больше баланса уводит его в минус, все партии списаны
+	inTx(t, d, userID, func(tx pgx.Tx) error {
+		_, err := clawBack(ctx, tx, userID, types.CurrencyPoints, types.NewAmount(100), types.NewAmount(150), types.ClawbackKindTask, 1, "", true)
+		return err
+	})
+	checkLots(t, d, userID, types.NewAmount(-50))
+
+	// новое начисление сначала гасит долг, в партию идет только остаток
+	earn(types.NewAmount(80))
+	checkLots(t, d, userID, types.NewAmount(30))
+
+	// сгорает только то, что пользователь действительно держит
+	if _, err = d.Conn.Exec(ctx, "update balance_lots set expires_at = now() - interval '1 second' where user_id = $1", userID); err != nil {
+		t.Fatal(err)
+	}
+	expired, err := d.ExpireLots(ctx, userID, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expired != types.NewAmount(30) {
+		t.Errorf("expired %s, want 30", expired)
+	}
+	checkLots(t, d, userID, 0)
+}
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+)
+
+func (d *DB) GetNotifications(ctx context.Context, userID int) ([]*types.Notification, error) {
+	rows, err := d.Conn.Query(ctx, "select id, user_id, kind, message, created_at, read_at from notifications where user_id = $1 order by created_at desc, id desc", userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.Notification])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+func (d *DB) MarkNotificationsRead(ctx context.Context, userID int) error {
+	_, err := d.Conn.Exec(ctx, "update notifications set read_at = now() where user_id = $1 and read_at is null", userID)
+	if err != nil {
+		return errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	return nil
+}
+
+// insertNotification создает уведомление, должен вызываться в той же транзакции что и событие, о котором оно
+func insertNotification(ctx context.Context, tx pgx.Tx, userID int, kind, message string) error {
+	_, err := tx.Exec(ctx, "insert into notifications (user_id, kind, message) values ($1, $2, $3)", userID, kind, message)
+	if err != nil {
+		return errors.Wrap(err, "insert into notifications failed: ")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// insertOutbox записывает событие в outbox, должен вызываться в той же транзакции что и само событие:
+// откат не оставит опубликованных событий, а закоммиченное изменение не останется без события
+func insertOutbox(ctx context.Context, tx pgx.Tx, eventType string, data any) error {
+	event := &types.WebhookEvent{ID: uuid.New().String(), Type: eventType, CreatedAt: time.Now(), Data: data}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal failed: ")
+	}
+	_, err = tx.Exec(ctx, "insert into outbox (event_id, event_type, payload, created_at) values ($1, $2, $3, $4)",
+		event.ID, eventType, payload, event.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, "insert into outbox failed: ")
+	}
+	return nil
+}
+
+// ClaimOutbox берет на публикацию до limit неопубликованных сообщений, которым пора уйти, в порядке записи
+// и откладывает их до leaseUntil: если публикация не закончится, сообщения повторят после leaseUntil
+func (d *DB) ClaimOutbox(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*types.OutboxMessage, error) {
+	rows, err := d.Conn.Query(ctx, `with claimed as (
+    update outbox set attempts = attempts + 1, next_attempt_at = $2
+    where id in (select id from outbox where published_at is null and next_attempt_at <= $1
+                 order by id limit $3 for update skip locked)
+    returning id, event_id, event_type, payload, attempts, created_at
+)
+select id, event_id::text, event_type, payload, attempts, created_at from claimed order by id`, now, leaseUntil, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[types.OutboxMessage])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+func (d *DB) MarkOutboxPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	_, err := d.Conn.Exec(ctx, "update outbox set published_at = $2, last_error = null where id = $1", id, publishedAt)
+	if err != nil {
+		return errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	return nil
+}
+
+// RecordOutboxFailure откладывает сообщение до nextAttemptAt и запоминает, почему публикация не удалась
+func (d *DB) RecordOutboxFailure(ctx context.Context, id int64, message string, nextAttemptAt time.Time) error {
+	_, err := d.Conn.Exec(ctx, "update outbox set next_attempt_at = $2, last_error = $3 where id = $1", id, nextAttemptAt, message)
+	if err != nil {
+		return errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	return nil
+}
+
+// DeletePublishedOutbox удаляет сообщения, опубликованные раньше before, возвращает сколько удалено
+func (d *DB) DeletePublishedOutbox(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := d.Conn.Exec(ctx, "delete from outbox where published_at < $1", before)
+	if err != nil {
+		return 0, errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	return tag.RowsAffected(), nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/SakuraBurst/denet/internal/referrer/types"
 	"github.com/go-faster/errors"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
@@ -15,6 +16,10 @@ import (
 type DB struct {
 	Conn   *pgxpool.Pool
 	logger *zap.Logger
+	// expirationMonths через сколько месяцев сгорают начисленные баллы, 0 - не сгорают
+	expirationMonths int
+	// timezone в которой считаются дни для серий заданий
+	timezone string
 }
 
 func NewDB(cfg *config.Config, logger *zap.Logger) (*DB, error) {
@@ -22,7 +27,7 @@ func NewDB(cfg *config.Config, logger *zap.Logger) (*DB, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "InitDatabase failed")
 	}
-	return &DB{Conn: conn, logger: logger.Named("db")}, nil
+	return &DB{Conn: conn, logger: logger.Named("db"), expirationMonths: cfg.Expiration.Months, timezone: cfg.LeaderBoard.Timezone}, nil
 }
 
 func initDatabase(cfg *config.Config) (*pgxpool.Pool, error) {
@@ -37,29 +42,51 @@ func initDatabase(cfg *config.Config) (*pgxpool.Pool, error) {
 }
 
 func (d *DB) CreateNewUser(ctx context.Context, user *types.UserRequest, referrerCode string) error {
-	row := d.Conn.QueryRow(ctx, "insert into users (first_name, last_name, user_name, password, balance, referrer_code) values ($1, $2, $3, $4, $5, $6) on conflict (user_name) do nothing returning id", user.FirstName, user.LastName, user.UserName, user.Password, 0, referrerCode)
-	var id int
-	err := row.Scan(&id)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return ErrUserAlreadyExist
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
 	}
+
+	row := tx.QueryRow(ctx, "insert into users (first_name, last_name, user_name, password, balance, referrer_code) values ($1, $2, $3, $4, $5, $6) on conflict (user_name) do nothing returning id", user.FirstName, user.LastName, user.UserName, user.Password, 0, referrerCode)
+	var id int
+	err = row.Scan(&id)
 	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserAlreadyExist
+		}
 		return errors.Wrap(err, "row.Scan failed: ")
 	}
-	return nil
+	err = insertOutbox(ctx, tx, types.WebhookUserRegistered, &types.UserRegisteredWebhook{
+		UserID:    id,
+		UserName:  user.UserName,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+	})
+	if err != nil {
+		rollback()
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (d *DB) GetFullUserInfo(ctx context.Context, userID int) (*types.FullUser, error) {
-	row := d.Conn.QueryRow(ctx, "select id, first_name, last_name, user_name, password, balance, referrer_code from users where id = $1", userID)
+	row := d.Conn.QueryRow(ctx, "select id, first_name, last_name, user_name, password, balance, referrer_code, xp from users where id = $1", userID)
 	user := &types.FullUser{}
-	err := row.Scan(&user.ID, &user.FirstName, &user.LastName, &user.UserName, &user.Password, &user.Balance, &user.ReferrerCode)
+	err := row.Scan(&user.ID, &user.FirstName, &user.LastName, &user.UserName, &user.Password, &user.Balance, &user.ReferrerCode, &user.XP)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrUserNotExist
 	}
 	if err != nil {
 		return nil, errors.Wrap(err, "row.Scan failed: ")
 	}
-	rows, err := d.Conn.Query(ctx, "select t2.* from tasks_to_users t1 left join tasks t2 on t2.id = t1.task_id where t1.user_id = $1", userID)
+	rows, err := d.Conn.Query(ctx, "select t2.* from tasks_to_users t1 left join tasks t2 on t2.id = t1.task_id where t1.user_id = $1 and t1.revoked_at is null", userID)
 	if err != nil {
 		return nil, errors.Wrap(err, "conn.Query failed: ")
 	}
@@ -69,14 +96,22 @@ func (d *DB) GetFullUserInfo(ctx context.Context, userID int) (*types.FullUser,
 		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
 	}
 	user.CompletedTasks = result
+	user.Balances, err = d.getBalances(ctx, userID, user.Balance)
+	if err != nil {
+		return nil, err
+	}
+	user.Achievements, err = d.getUserAchievements(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
 // GetUserByUserName возвращает весего юзера на всякий случай, вдруг где-то еще пригодиться
 func (d *DB) GetUserByUserName(ctx context.Context, userName string) (*types.User, error) {
-	row := d.Conn.QueryRow(ctx, "select id, first_name, last_name, user_name, password, balance from users where user_name = $1", userName)
+	row := d.Conn.QueryRow(ctx, "select id, first_name, last_name, user_name, password, balance, role from users where user_name = $1", userName)
 	user := &types.User{}
-	err := row.Scan(&user.ID, &user.FirstName, &user.LastName, &user.UserName, &user.Password, &user.Balance)
+	err := row.Scan(&user.ID, &user.FirstName, &user.LastName, &user.UserName, &user.Password, &user.Balance, &user.Role)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrUserNotExist
 	}
@@ -100,22 +135,56 @@ func (d *DB) GetUserByReferrerCode(ctx context.Context, referrerCode string) (*t
 	return user, nil
 }
 
-func (d *DB) GetTopUsersByBalance(ctx context.Context, limit int) ([]*types.User, error) {
-	rows, err := d.Conn.Query(ctx, "select * from users order by balance limit $1", limit)
+// leaderBoardQuery ранжирует пользователей по балансу, при равном балансе выше тот, кто зарегистрировался раньше
+const leaderBoardQuery = `select id, first_name, last_name, user_name, balance,
+       rank() over (order by balance desc) as rank,
+       dense_rank() over (order by balance desc) as dense_rank,
+       row_number() over (order by balance desc, id) as position
+from users`
+
+func (d *DB) GetLeaderBoard(ctx context.Context, limit, offset int) ([]*types.RankedUser, int, error) {
+	rows, err := d.Conn.Query(ctx, leaderBoardQuery+" order by position limit $1 offset $2", limit, offset)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.RankedUser])
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	var total int
+	err = d.Conn.QueryRow(ctx, "select count(*) from users").Scan(&total)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return result, total, nil
+}
+
+// GetUserRank возвращает пользователя и его соседей по таблице, по neighbors штук сверху и снизу
+func (d *DB) GetUserRank(ctx context.Context, userID, neighbors int) ([]*types.RankedUser, error) {
+	rows, err := d.Conn.Query(ctx, "with ranked as ("+leaderBoardQuery+`),
+     target as (select position from ranked where id = $1)
+select ranked.* from ranked, target
+where ranked.position between target.position - $2 and target.position + $2
+order by ranked.position`, userID, neighbors)
 	if err != nil {
 		return nil, errors.Wrap(err, "Conn.Query failed: ")
 	}
-	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.User])
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.RankedUser])
 	if err != nil {
 		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
 	}
+	if len(result) == 0 {
+		return nil, ErrUserNotExist
+	}
 	return result, nil
 }
 
-func (d *DB) CompleteTask(ctx context.Context, taskID, userID int) (int, error) {
+// CompleteTask отмечает задание выполненным и начисляет награду в валюте задания с множителем уровня пользователя,
+// возвращает новый баланс в этой валюте. Если сработало ограничение policy, возвращает *LimitExceededError
+func (d *DB) CompleteTask(ctx context.Context, taskID, userID int, policy types.EarningPolicy) (*types.Balance, error) {
 	tx, err := d.Conn.Begin(ctx)
 	if err != nil {
-		return 0, errors.Wrap(err, "conn.Begin failed: ")
+		return nil, errors.Wrap(err, "conn.Begin failed: ")
 	}
 
 	rollback := func() {
@@ -124,25 +193,27 @@ func (d *DB) CompleteTask(ctx context.Context, taskID, userID int) (int, error)
 		}
 	}
 
-	var balance int
-	row := tx.QueryRow(ctx, "select balance from users where id = $1 for update ", userID)
-	err = row.Scan(&balance)
+	var role string
+	var xp types.Amount
+	row := tx.QueryRow(ctx, "select role, xp from users where id = $1 for update ", userID)
+	err = row.Scan(&role, &xp)
 	if err != nil {
 		rollback()
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, ErrUserNotExist
+			return nil, ErrUserNotExist
 		}
-		return 0, err
+		return nil, err
 	}
-	var reward int
-	row = tx.QueryRow(ctx, "select reward from tasks where id = $1", taskID)
-	err = row.Scan(&reward)
+	var reward types.Amount
+	var currency string
+	row = tx.QueryRow(ctx, "select reward, currency from tasks where id = $1", taskID)
+	err = row.Scan(&reward, &currency)
 	if err != nil {
 		rollback()
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, ErrTaskNotExist
+			return nil, ErrTaskNotExist
 		}
-		return 0, err
+		return nil, err
 	}
 
 	var taskToUserId int
@@ -151,26 +222,88 @@ func (d *DB) CompleteTask(ctx context.Context, taskID, userID int) (int, error)
 	if err != nil {
 		rollback()
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, ErrAlreadyCompletedTask
+			return nil, ErrAlreadyCompletedTask
 		}
-		return 0, err
+		return nil, err
+	}
+	reward, err = reward.MulRatio(int64(policy.Levels.For(xp).RewardPercent), 100)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "reward.MulRatio failed: ")
+	}
+	limits := policy.For(role)
+	err = checkCompletionLimits(ctx, tx, userID, taskToUserId, limits, policy.Now)
+	if err == nil && currency == types.CurrencyPoints {
+		err = checkDailyReward(ctx, tx, userID, reward, limits, policy)
+	}
+	if err != nil {
+		rollback()
+		return nil, err
 	}
 
-	_, err = tx.Exec(ctx, "update users set balance = $2 where id = $1", userID, balance+reward)
+	balance, err := addBalance(ctx, tx, userID, currency, reward, types.RewardSourceTask, taskID)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	err = insertRewardEvent(ctx, tx, userID, reward, currency, types.RewardSourceTask, taskID)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	// сезоны, команды, сгорание и опыт считаются только в баллах
+	if currency == types.CurrencyPoints {
+		err = addXP(ctx, tx, userID, xp, reward, policy.Levels)
+		if err != nil {
+			rollback()
+			return nil, err
+		}
+		err = accrueSeasonPoints(ctx, tx, userID, reward)
+		if err != nil {
+			rollback()
+			return nil, err
+		}
+		err = accrueTeamPoints(ctx, tx, userID, reward)
+		if err != nil {
+			rollback()
+			return nil, err
+		}
+		err = d.addLot(ctx, tx, userID, reward, types.RewardSourceTask)
+		if err != nil {
+			rollback()
+			return nil, err
+		}
+	}
+	err = d.awardAchievements(ctx, tx, userID, types.AchievementTasksCompleted, types.AchievementStreakDays, types.AchievementLeaderBoardRank)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	err = insertOutbox(ctx, tx, types.WebhookTaskCompleted, &types.TaskCompletedWebhook{UserID: userID, TaskID: taskID, Reward: reward, Currency: currency})
 	if err != nil {
 		rollback()
-		return 0, errors.Wrap(err, "tx.Exec failed: ")
+		return nil, err
 	}
-	return balance + reward, tx.Commit(ctx)
+	// бонус за достижение мог изменить баланс в баллах
+	balance, err = getBalance(ctx, tx, userID, currency)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	return &types.Balance{Currency: currency, Amount: balance}, tx.Commit(ctx)
 }
 
 func (d *DB) CreateNewTask(ctx context.Context, task *types.Task) (int, error) {
-	row := d.Conn.QueryRow(ctx, "insert into tasks (description, reward) values ($1, $2) on conflict (description) do nothing returning id", task.Description, task.Reward)
+	row := d.Conn.QueryRow(ctx, "insert into tasks (description, reward, currency) values ($1, $2, $3) on conflict (description) do nothing returning id", task.Description, task.Reward, task.Currency)
 	var id int
 	err := row.Scan(&id)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return 0, ErrTaskAlreadyExist
 	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+		return 0, ErrUnknownCurrency
+	}
 	if err != nil {
 		return 0, errors.Wrap(err, "row.Scan failed: ")
 	}
@@ -178,9 +311,9 @@ func (d *DB) CreateNewTask(ctx context.Context, task *types.Task) (int, error) {
 }
 
 func (d *DB) GetTaskById(ctx context.Context, taskID int) (*types.Task, error) {
-	row := d.Conn.QueryRow(ctx, "select id, description, reward from tasks where id = $1", taskID)
+	row := d.Conn.QueryRow(ctx, "select id, description, reward, currency from tasks where id = $1", taskID)
 	task := &types.Task{}
-	err := row.Scan(&task.ID, &task.Description, &task.Reward)
+	err := row.Scan(&task.ID, &task.Description, &task.Reward, &task.Currency)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrTaskNotExist
 	}
@@ -202,12 +335,37 @@ func (d *DB) GetAllTasks(ctx context.Context) ([]*types.Task, error) {
 	return result, nil
 }
 
-func (d *DB) UpdateTaskReward(ctx context.Context, id, newReward int) error {
+func (d *DB) UpdateTaskReward(ctx context.Context, id int, newReward types.Amount) error {
 	_, err := d.Conn.Exec(ctx, "update tasks set reward = $2 where id = $1", id, newReward)
 	return err
 }
 
-func (d *DB) RewardUser(ctx context.Context, userID, rewardValue int) error {
-	_, err := d.Conn.Exec(ctx, "update users set balance = balance + $2 where id = $1", userID, rewardValue)
-	return err
+// RewardUser начисляет пользователю баллы
+func (d *DB) RewardUser(ctx context.Context, userID int, rewardValue types.Amount, source string) error {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "conn.Begin failed: ")
+	}
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	_, err = addBalance(ctx, tx, userID, types.CurrencyPoints, rewardValue, source, 0)
+	if err != nil {
+		rollback()
+		return err
+	}
+	err = insertRewardEvent(ctx, tx, userID, rewardValue, types.CurrencyPoints, source, 0)
+	if err != nil {
+		rollback()
+		return err
+	}
+	err = d.addLot(ctx, tx, userID, rewardValue, source)
+	if err != nil {
+		rollback()
+		return err
+	}
+	return tx.Commit(ctx)
 }
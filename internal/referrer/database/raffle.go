@@ -0,0 +1,336 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// seed отдается только после розыгрыша, до этого виден только его хэш
+const raffleColumns = "id, name, description, ticket_price, max_tickets_per_user, draw_at, seed_hash, case when drawn_at is not null then seed end as seed, tickets_hash, tickets_sold, drawn_at, created_at"
+
+// CreateRaffle создает розыгрыш с загаданным seed, хэш которого должен быть уже посчитан в raffle.SeedHash
+func (d *DB) CreateRaffle(ctx context.Context, raffle *types.Raffle, seed string) (int, error) {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	var id int
+	err = tx.QueryRow(ctx, "insert into raffles (name, description, ticket_price, max_tickets_per_user, draw_at, seed, seed_hash) values ($1, $2, $3, $4, $5, $6, $7) returning id",
+		raffle.Name, raffle.Description, raffle.TicketPrice, raffle.MaxTicketsPerUser, raffle.DrawAt, seed, raffle.SeedHash).Scan(&id)
+	if err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	for _, prize := range raffle.Prizes {
+		_, err = tx.Exec(ctx, "insert into raffle_prizes (raffle_id, place, reward) values ($1, $2, $3)", id, prize.Place, prize.Reward)
+		if err != nil {
+			rollback()
+			return 0, errors.Wrap(err, "tx.Exec failed: ")
+		}
+	}
+	return id, tx.Commit(ctx)
+}
+
+func (d *DB) GetRaffles(ctx context.Context) ([]*types.Raffle, error) {
+	rows, err := d.Conn.Query(ctx, "select "+raffleColumns+" from raffles order by draw_at desc, id")
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	raffles, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByNameLax[types.Raffle])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	byID := make(map[int]*types.Raffle, len(raffles))
+	for _, r := range raffles {
+		r.Prizes = []*types.RafflePrize{}
+		byID[r.ID] = r
+	}
+	rows, err = d.Conn.Query(ctx, "select raffle_id, place, reward from raffle_prizes order by raffle_id, place")
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var raffleID int
+		prize := &types.RafflePrize{}
+		if err = rows.Scan(&raffleID, &prize.Place, &prize.Reward); err != nil {
+			return nil, errors.Wrap(err, "rows.Scan failed: ")
+		}
+		if r, ok := byID[raffleID]; ok {
+			r.Prizes = append(r.Prizes, prize)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows.Err: ")
+	}
+	return raffles, nil
+}
+
+// GetRaffle возвращает розыгрыш с призами и, если он уже прошел, победителями
+func (d *DB) GetRaffle(ctx context.Context, raffleID int) (*types.Raffle, error) {
+	rows, err := d.Conn.Query(ctx, "select "+raffleColumns+" from raffles where id = $1", raffleID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	raffle, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByNameLax[types.Raffle])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRaffleNotExist
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectOneRow failed: ")
+	}
+	rows, err = d.Conn.Query(ctx, "select place, reward from raffle_prizes where raffle_id = $1 order by place", raffleID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	raffle.Prizes, err = pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.RafflePrize])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	rows, err = d.Conn.Query(ctx, "select place, ticket_number, user_id, reward from raffle_winners where raffle_id = $1 order by place", raffleID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	raffle.Winners, err = pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.RaffleWinner])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return raffle, nil
+}
+
+// GetRaffleTickets возвращает все билеты розыгрыша, по ним и раскрытому seed можно пересчитать победителей
+func (d *DB) GetRaffleTickets(ctx context.Context, raffleID int) ([]*types.RaffleTicket, error) {
+	var exists bool
+	err := d.Conn.QueryRow(ctx, "select exists(select 1 from raffles where id = $1)", raffleID).Scan(&exists)
+	if err != nil {
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if !exists {
+		return nil, ErrRaffleNotExist
+	}
+	rows, err := d.Conn.Query(ctx, "select number, user_id from raffle_tickets where raffle_id = $1 order by number", raffleID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.RaffleTicket])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+// BuyRaffleTickets списывает с баланса стоимость quantity билетов и выдает их с номерами подряд.
+// Розыгрыш блокируется раньше пользователя: при розыгрыше победители становятся известны только
+// после блокировки розыгрыша, поэтому там пользователи блокируются после него
+func (d *DB) BuyRaffleTickets(ctx context.Context, userID, raffleID, quantity int) (*types.RaffleTickets, error) {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	var ticketPrice types.Amount
+	var maxTickets *int
+	var sold int
+	var open bool
+	err = tx.QueryRow(ctx, "select ticket_price, max_tickets_per_user, tickets_sold, drawn_at is null and draw_at > now() from raffles where id = $1 for update", raffleID).
+		Scan(&ticketPrice, &maxTickets, &sold, &open)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRaffleNotExist
+		}
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if !open {
+		rollback()
+		return nil, ErrRaffleClosed
+	}
+
+	var balance types.Amount
+	err = tx.QueryRow(ctx, "select balance from users where id = $1 for update", userID).Scan(&balance)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotExist
+		}
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if maxTickets != nil {
+		var bought int
+		err = tx.QueryRow(ctx, "select count(*) from raffle_tickets where raffle_id = $1 and user_id = $2", raffleID, userID).Scan(&bought)
+		if err != nil {
+			rollback()
+			return nil, errors.Wrap(err, "row.Scan failed: ")
+		}
+		if bought+quantity > *maxTickets {
+			rollback()
+			return nil, ErrTicketLimitExceeded
+		}
+	}
+	price, err := ticketPrice.Mul(quantity)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "ticketPrice.Mul failed: ")
+	}
+	if balance < price {
+		rollback()
+		return nil, ErrInsufficientBalance
+	}
+
+	balance, err = addBalance(ctx, tx, userID, types.CurrencyPoints, -price, types.LedgerRaffleTickets, raffleID)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	_, err = consumeLots(ctx, tx, userID, price)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	_, err = tx.Exec(ctx, "insert into raffle_tickets (raffle_id, number, user_id, price) select $1, n, $2, $3 from generate_series($4::int, $5::int) n",
+		raffleID, userID, ticketPrice, sold+1, sold+quantity)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "tx.Exec failed: ")
+	}
+	_, err = tx.Exec(ctx, "update raffles set tickets_sold = tickets_sold + $2 where id = $1", raffleID, quantity)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "tx.Exec failed: ")
+	}
+	result := &types.RaffleTickets{RaffleID: raffleID, FirstNumber: sold + 1, LastNumber: sold + quantity, Price: price, Balance: balance}
+	return result, tx.Commit(ctx)
+}
+
+// GetRafflesToDraw возвращает розыгрыши, время которых пришло к моменту now
+func (d *DB) GetRafflesToDraw(ctx context.Context, now time.Time) ([]int, error) {
+	rows, err := d.Conn.Query(ctx, "select id from raffles where drawn_at is null and draw_at <= $1 order by draw_at, id", now)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return ids, nil
+}
+
+// DrawRaffle разыгрывает призы по types.DrawRaffleTickets, начисляет их победителям и уведомляет их.
+// Розыгрыш, время которого еще не пришло или который уже прошел, пропускается
+func (d *DB) DrawRaffle(ctx context.Context, raffleID int) error {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	var name, seed string
+	err = tx.QueryRow(ctx, "select name, seed from raffles where id = $1 and drawn_at is null and draw_at <= now() for update", raffleID).
+		Scan(&name, &seed)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return errors.Wrap(err, "row.Scan failed: ")
+	}
+	rows, err := tx.Query(ctx, "select place, reward from raffle_prizes where raffle_id = $1 order by place", raffleID)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Query failed: ")
+	}
+	prizes, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.RafflePrize])
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+
+	// продажи закрыты, список билетов больше не меняется: его хэш нельзя было знать, загадывая seed
+	rows, err = tx.Query(ctx, "select number, user_id from raffle_tickets where raffle_id = $1 order by number", raffleID)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Query failed: ")
+	}
+	tickets, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.RaffleTicket])
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	ticketsHash := types.RaffleTicketsHash(tickets)
+	numbers := types.DrawRaffleTickets(types.RaffleDrawKey(seed, ticketsHash), len(tickets), len(prizes))
+	owners := make(map[int]int, len(tickets))
+	for _, ticket := range tickets {
+		owners[ticket.Number] = ticket.UserID
+	}
+	winnerIDs := make([]int, 0, len(numbers))
+	for _, number := range numbers {
+		winnerIDs = append(winnerIDs, owners[number])
+	}
+	slices.Sort(winnerIDs)
+	// победители блокируются по возрастанию id, как везде где блокируется несколько пользователей
+	_, err = tx.Exec(ctx, "select id from users where id = any($1) order by id for update", slices.Compact(winnerIDs))
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Exec failed: ")
+	}
+
+	for i, number := range numbers {
+		prize := prizes[i]
+		userID := owners[number]
+		_, err = tx.Exec(ctx, "insert into raffle_winners (raffle_id, place, ticket_number, user_id, reward) values ($1, $2, $3, $4, $5)",
+			raffleID, prize.Place, number, userID, prize.Reward)
+		if err != nil {
+			rollback()
+			return errors.Wrap(err, "tx.Exec failed: ")
+		}
+		if _, err = addBalance(ctx, tx, userID, types.CurrencyPoints, prize.Reward, types.RewardSourceRaffle, raffleID); err != nil {
+			rollback()
+			return err
+		}
+		err = insertRewardEvent(ctx, tx, userID, prize.Reward, types.CurrencyPoints, types.RewardSourceRaffle, raffleID)
+		if err != nil {
+			rollback()
+			return err
+		}
+		if err = d.addLot(ctx, tx, userID, prize.Reward, types.RewardSourceRaffle); err != nil {
+			rollback()
+			return err
+		}
+		message := fmt.Sprintf("Билет №%d выиграл %d место в розыгрыше «%s», начислено %s", number, prize.Place, name, prize.Reward)
+		if err = insertNotification(ctx, tx, userID, types.NotificationRaffle, message); err != nil {
+			rollback()
+			return err
+		}
+	}
+	_, err = tx.Exec(ctx, "update raffles set drawn_at = now(), tickets_hash = $2 where id = $1", raffleID, ticketsHash)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Exec failed: ")
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,214 @@
+package database
+
+import (
+	"context"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// CreateReferral запоминает кто пригласил пользователя и начисляет reward обоим с множителями их уровней.
+// Реферальный код можно ввести только один раз. Если рефералу не позволяет дневной лимит policy, возвращает
+// *LimitExceededError, а рефереру начисляется столько, сколько позволяет его лимит, чтобы накрутка
+// приглашений не приносила больше лимита
+func (d *DB) CreateReferral(ctx context.Context, refereeID int, referrerCode string, reward types.Amount, policy types.EarningPolicy) (int, error) {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	var referrerID int
+	err = tx.QueryRow(ctx, "select id from users where referrer_code = $1", referrerCode).Scan(&referrerID)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrUserNotExist
+		}
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if referrerID == refereeID {
+		rollback()
+		return 0, ErrSelfReferral
+	}
+
+	// блокируем обоих всегда в одном порядке, чтобы встречные запросы не словили дедлок
+	rows, err := tx.Query(ctx, "select id, role, xp from users where id in ($1, $2) order by id for update", refereeID, referrerID)
+	if err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "tx.Query failed: ")
+	}
+	roles := make(map[int]string, 2)
+	xps := make(map[int]types.Amount, 2)
+	for rows.Next() {
+		var id int
+		var role string
+		var xp types.Amount
+		if err = rows.Scan(&id, &role, &xp); err != nil {
+			rows.Close()
+			rollback()
+			return 0, errors.Wrap(err, "rows.Scan failed: ")
+		}
+		roles[id], xps[id] = role, xp
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "rows.Err: ")
+	}
+
+	var currentReferrer *int
+	err = tx.QueryRow(ctx, "select referrer_id from users where id = $1", refereeID).Scan(&currentReferrer)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrUserNotExist
+		}
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if currentReferrer != nil {
+		rollback()
+		return 0, ErrAlreadyReferred
+	}
+
+	var cycle bool
+	err = tx.QueryRow(ctx, `with recursive ancestors as (
+    select id, referrer_id from users where id = $1
+    union
+    select u.id, u.referrer_id from users u join ancestors a on u.id = a.referrer_id
+)
+select exists(select 1 from ancestors where id = $2)`, referrerID, refereeID).Scan(&cycle)
+	if err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if cycle {
+		rollback()
+		return 0, ErrReferralCycle
+	}
+
+	refereeReward, err := reward.MulRatio(int64(policy.Levels.For(xps[refereeID]).ReferralPercent), 100)
+	if err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "reward.MulRatio failed: ")
+	}
+	err = checkDailyReward(ctx, tx, refereeID, refereeReward, policy.For(roles[refereeID]), policy)
+	if err != nil {
+		rollback()
+		return 0, err
+	}
+	referrerReward, err := reward.MulRatio(int64(policy.Levels.For(xps[referrerID]).ReferralPercent), 100)
+	if err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "reward.MulRatio failed: ")
+	}
+	left, limited, err := dailyRewardLeft(ctx, tx, referrerID, policy.For(roles[referrerID]), policy)
+	if err != nil {
+		rollback()
+		return 0, err
+	}
+	if limited {
+		referrerReward = min(referrerReward, left)
+	}
+
+	_, err = tx.Exec(ctx, "update users set referrer_id = $2 where id = $1", refereeID, referrerID)
+	if err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "tx.Exec failed: ")
+	}
+	// поощряем и того кто ввел код, и того чей код был введен. Начисление пишется в журнал даже если
+	// лимит реферера исчерпан, чтобы при отзыве реферала не списать с него то, что он не получал
+	credits := []struct {
+		userID int
+		reward types.Amount
+	}{{refereeID, refereeReward}, {referrerID, referrerReward}}
+	for _, credit := range credits {
+		err = insertRewardEvent(ctx, tx, credit.userID, credit.reward, types.CurrencyPoints, types.RewardSourceReferral, refereeID)
+		if err != nil {
+			rollback()
+			return 0, err
+		}
+		if credit.reward <= 0 {
+			continue
+		}
+		_, err = addBalance(ctx, tx, credit.userID, types.CurrencyPoints, credit.reward, types.RewardSourceReferral, refereeID)
+		if err != nil {
+			rollback()
+			return 0, err
+		}
+		err = addXP(ctx, tx, credit.userID, xps[credit.userID], credit.reward, policy.Levels)
+		if err != nil {
+			rollback()
+			return 0, err
+		}
+		err = accrueSeasonPoints(ctx, tx, credit.userID, credit.reward)
+		if err != nil {
+			rollback()
+			return 0, err
+		}
+		err = d.addLot(ctx, tx, credit.userID, credit.reward, types.RewardSourceReferral)
+		if err != nil {
+			rollback()
+			return 0, err
+		}
+	}
+	err = d.awardAchievements(ctx, tx, referrerID, types.AchievementReferrals, types.AchievementLeaderBoardRank)
+	if err != nil {
+		rollback()
+		return 0, err
+	}
+	err = d.awardAchievements(ctx, tx, refereeID, types.AchievementLeaderBoardRank)
+	if err != nil {
+		rollback()
+		return 0, err
+	}
+	err = insertOutbox(ctx, tx, types.WebhookReferralCreated, &types.ReferralCreatedWebhook{
+		RefereeID:      refereeID,
+		ReferrerID:     referrerID,
+		RefereeReward:  refereeReward,
+		ReferrerReward: referrerReward,
+	})
+	if err != nil {
+		rollback()
+		return 0, err
+	}
+	return referrerID, tx.Commit(ctx)
+}
+
+// GetNetworkLeaderBoard ранжирует пользователя среди того кто его пригласил, тех кого пригласил он сам
+// и тех кого пригласил его реферер
+func (d *DB) GetNetworkLeaderBoard(ctx context.Context, userID int) ([]*types.NetworkRankedUser, error) {
+	rows, err := d.Conn.Query(ctx, `with me as (select id, referrer_id from users where id = $1),
+     network as (select id, 'self' as relation from me
+                 union all
+                 select u.id, 'referrer' from users u join me on u.id = me.referrer_id
+                 union all
+                 select u.id, 'referee' from users u join me on u.referrer_id = me.id
+                 union all
+                 select u.id, 'sibling' from users u join me on u.referrer_id = me.referrer_id and u.id <> me.id)
+select u.id, u.first_name, u.last_name, u.user_name, u.balance, n.relation,
+       rank() over (order by u.balance desc) as rank,
+       dense_rank() over (order by u.balance desc) as dense_rank,
+       row_number() over (order by u.balance desc, u.id) as position
+from network n
+join users u on u.id = n.id
+order by position`, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.NetworkRankedUser])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	if len(result) == 0 {
+		return nil, ErrUserNotExist
+	}
+	return result, nil
+}
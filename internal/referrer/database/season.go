@@ -0,0 +1,267 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// seasonLeaderBoardQuery ранжирует участников сезона $1 по очкам и подставляет приз за занятое место
+const seasonLeaderBoardQuery = `select r.*, coalesce(pr.reward, 0) as prize
+from (select u.id, u.first_name, u.last_name, u.user_name, u.balance, p.points,
+             rank() over (order by p.points desc) as rank,
+             dense_rank() over (order by p.points desc) as dense_rank,
+             row_number() over (order by p.points desc, u.id) as position
+      from season_points p
+      join users u on u.id = p.user_id
+      where p.season_id = $1) r
+left join season_prizes pr on pr.season_id = $1 and pr.place = r.position`
+
+func (d *DB) CreateSeason(ctx context.Context, season *types.Season) (int, error) {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	var id int
+	err = tx.QueryRow(ctx, "insert into seasons (name, starts_at, ends_at) values ($1, $2, $3) returning id", season.Name, season.StartsAt, season.EndsAt).Scan(&id)
+	if err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	for _, prize := range season.Prizes {
+		_, err = tx.Exec(ctx, "insert into season_prizes (season_id, place, reward) values ($1, $2, $3)", id, prize.Place, prize.Reward)
+		if err != nil {
+			rollback()
+			return 0, errors.Wrap(err, "tx.Exec failed: ")
+		}
+	}
+	return id, tx.Commit(ctx)
+}
+
+func (d *DB) GetSeasons(ctx context.Context) ([]*types.Season, error) {
+	rows, err := d.Conn.Query(ctx, "select id, name, starts_at, ends_at, closed_at from seasons order by starts_at desc, id")
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	seasons, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByNameLax[types.Season])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	byID := make(map[int]*types.Season, len(seasons))
+	for _, s := range seasons {
+		s.Prizes = []*types.SeasonPrize{}
+		byID[s.ID] = s
+	}
+	rows, err = d.Conn.Query(ctx, "select season_id, place, reward from season_prizes order by season_id, place")
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var seasonID int
+		prize := &types.SeasonPrize{}
+		if err = rows.Scan(&seasonID, &prize.Place, &prize.Reward); err != nil {
+			return nil, errors.Wrap(err, "rows.Scan failed: ")
+		}
+		if s, ok := byID[seasonID]; ok {
+			s.Prizes = append(s.Prizes, prize)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "rows.Err: ")
+	}
+	return seasons, nil
+}
+
+func (d *DB) GetSeason(ctx context.Context, seasonID int) (*types.Season, error) {
+	season := &types.Season{}
+	row := d.Conn.QueryRow(ctx, "select id, name, starts_at, ends_at, closed_at from seasons where id = $1", seasonID)
+	err := row.Scan(&season.ID, &season.Name, &season.StartsAt, &season.EndsAt, &season.ClosedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSeasonNotExist
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	rows, err := d.Conn.Query(ctx, "select place, reward from season_prizes where season_id = $1 order by place", seasonID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	season.Prizes, err = pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.SeasonPrize])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return season, nil
+}
+
+// GetSeasonLeaderBoard считает таблицу сезона по текущим очкам
+func (d *DB) GetSeasonLeaderBoard(ctx context.Context, seasonID, limit, offset int) ([]*types.SeasonRankedUser, int, error) {
+	rows, err := d.Conn.Query(ctx, seasonLeaderBoardQuery+" order by r.position limit $2 offset $3", seasonID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.SeasonRankedUser])
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	var total int
+	err = d.Conn.QueryRow(ctx, "select count(*) from season_points where season_id = $1", seasonID).Scan(&total)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return result, total, nil
+}
+
+// GetSeasonResults возвращает итоги закрытого сезона
+func (d *DB) GetSeasonResults(ctx context.Context, seasonID, limit, offset int) ([]*types.SeasonRankedUser, int, error) {
+	rows, err := d.Conn.Query(ctx, `select u.id, u.first_name, u.last_name, u.user_name, u.balance, r.points, r.rank, r.dense_rank, r.position, r.prize
+from season_results r
+join users u on u.id = r.user_id
+where r.season_id = $1
+order by r.position limit $2 offset $3`, seasonID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.SeasonRankedUser])
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	var total int
+	err = d.Conn.QueryRow(ctx, "select count(*) from season_results where season_id = $1", seasonID).Scan(&total)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return result, total, nil
+}
+
+// GetSeasonsToClose возвращает id сезонов, которые закончились к моменту now, но еще не закрыты
+func (d *DB) GetSeasonsToClose(ctx context.Context, now time.Time) ([]int, error) {
+	rows, err := d.Conn.Query(ctx, "select id from seasons where ends_at <= $1 and closed_at is null order by ends_at", now)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+// CloseSeason замораживает таблицу сезона в season_results, начисляет призы и уведомляет победителей. Баланс пользователей не сбрасывается.
+// Сезон, который еще не начался, закрыть нельзя
+func (d *DB) CloseSeason(ctx context.Context, seasonID int) error {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	var closedAt *time.Time
+	var started bool
+	err = tx.QueryRow(ctx, "select closed_at, starts_at <= now() from seasons where id = $1 for update", seasonID).Scan(&closedAt, &started)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrSeasonNotExist
+		}
+		return errors.Wrap(err, "row.Scan failed: ")
+	}
+	if closedAt != nil {
+		rollback()
+		return ErrSeasonAlreadyClosed
+	}
+	// у неначавшегося сезона ends_at стал бы раньше starts_at, такой сезон можно только дождаться
+	if !started {
+		rollback()
+		return ErrSeasonNotStarted
+	}
+
+	_, err = tx.Exec(ctx, "update seasons set closed_at = now(), ends_at = least(ends_at, now()) where id = $1", seasonID)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Exec failed: ")
+	}
+	_, err = tx.Exec(ctx, `insert into season_results (season_id, user_id, points, rank, dense_rank, position, prize)
+select $1, id, points, rank, dense_rank, position, prize from (`+seasonLeaderBoardQuery+`) ranked`, seasonID)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Exec failed: ")
+	}
+	var name string
+	if err = tx.QueryRow(ctx, "select name from seasons where id = $1", seasonID).Scan(&name); err != nil {
+		rollback()
+		return errors.Wrap(err, "row.Scan failed: ")
+	}
+	// победители блокируются по возрастанию id, как везде где блокируется несколько пользователей
+	rows, err := tx.Query(ctx, `select r.user_id, r.position, r.prize from season_results r
+join users u on u.id = r.user_id
+where r.season_id = $1 and r.prize > 0
+order by r.user_id
+for update of u`, seasonID)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Query failed: ")
+	}
+	type winner struct {
+		userID, position int
+		prize            types.Amount
+	}
+	winners, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (winner, error) {
+		var w winner
+		err := row.Scan(&w.userID, &w.position, &w.prize)
+		return w, err
+	})
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	for _, w := range winners {
+		if _, err = addBalance(ctx, tx, w.userID, types.CurrencyPoints, w.prize, types.RewardSourceSeasonPrize, seasonID); err != nil {
+			rollback()
+			return err
+		}
+		err = insertRewardEvent(ctx, tx, w.userID, w.prize, types.CurrencyPoints, types.RewardSourceSeasonPrize, seasonID)
+		if err != nil {
+			rollback()
+			return err
+		}
+		if err = d.addLot(ctx, tx, w.userID, w.prize, types.RewardSourceSeasonPrize); err != nil {
+			rollback()
+			return err
+		}
+		message := fmt.Sprintf("%d место в сезоне «%s», начислено %s", w.position, name, w.prize)
+		if err = insertNotification(ctx, tx, w.userID, types.NotificationSeasonPrize, message); err != nil {
+			rollback()
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// accrueSeasonPoints начисляет очки во все идущие сезоны, должен вызываться в той же транзакции что и изменение баланса
+func accrueSeasonPoints(ctx context.Context, tx pgx.Tx, userID int, points types.Amount) error {
+	_, err := tx.Exec(ctx, `insert into season_points (season_id, user_id, points)
+select id, $1, $2 from seasons where starts_at <= now() and ends_at > now() and closed_at is null
+on conflict (season_id, user_id) do update set points = season_points.points + excluded.points`, userID, points)
+	if err != nil {
+		return errors.Wrap(err, "insert into season_points failed: ")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestCloseSeasonPaysPrizes(t *testing.T) {
+	d := newTestDB(t, 12)
+	ctx := context.Background()
+	users := make([]int, 2)
+	for i, userName := range []string{"winner", "runner_up"} {
+		err := d.Conn.QueryRow(ctx, "insert into users (first_name, last_name, user_name, password, balance) values ('', '', $1, '', 0) returning id",
+			userName).Scan(&users[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	seasonID, err := d.CreateSeason(ctx, &types.Season{
+		Name:     "Осень",
+		StartsAt: time.Now().Add(-time.Hour),
+		EndsAt:   time.Now().Add(time.Hour),
+		Prizes:   []*types.SeasonPrize{{Place: 1, Reward: types.NewAmount(100)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// первый набрал больше очков и получит приз, второму приза не хватило
+	for i, userID := range users {
+		inTx(t, d, userID, func(tx pgx.Tx) error {
+			return accrueSeasonPoints(ctx, tx, userID, types.NewAmount(int64(10-i)))
+		})
+	}
+
+	if err = d.CloseSeason(ctx, seasonID); err != nil {
+		t.Fatal(err)
+	}
+
+	checkLots(t, d, users[0], types.NewAmount(100))
+	checkLots(t, d, users[1], 0)
+	for i, want := range []int{1, 0} {
+		var events, notifications int
+		err = d.Conn.QueryRow(ctx, `select (select count(*) from reward_events where user_id = $1 and source = $2 and reference_id = $3),
+(select count(*) from notifications where user_id = $1 and kind = $4)`,
+			users[i], types.RewardSourceSeasonPrize, seasonID, types.NotificationSeasonPrize).Scan(&events, &notifications)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if events != want || notifications != want {
+			t.Errorf("user %d: %d reward events and %d notifications, want %d", i+1, events, notifications, want)
+		}
+	}
+}
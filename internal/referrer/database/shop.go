@@ -0,0 +1,256 @@
+package database
+
+import (
+	"context"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+)
+
+const orderColumns = "id, user_id, item_id, quantity, price, status, created_at, updated_at"
+
+func (d *DB) CreateShopItem(ctx context.Context, item *types.ShopItem) (int, error) {
+	row := d.Conn.QueryRow(ctx, "insert into shop_items (name, description, price, stock, per_user_limit, active) values ($1, $2, $3, $4, $5, $6) on conflict (name) do nothing returning id",
+		item.Name, item.Description, item.Price, item.Stock, item.PerUserLimit, item.Active)
+	var id int
+	err := row.Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrItemAlreadyExist
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return id, nil
+}
+
+func (d *DB) UpdateShopItem(ctx context.Context, item *types.ShopItem) error {
+	tag, err := d.Conn.Exec(ctx, "update shop_items set name = $2, description = $3, price = $4, stock = $5, per_user_limit = $6, active = $7 where id = $1",
+		item.ID, item.Name, item.Description, item.Price, item.Stock, item.PerUserLimit, item.Active)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return ErrItemAlreadyExist
+	}
+	if err != nil {
+		return errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrItemNotExist
+	}
+	return nil
+}
+
+// GetShopItems возвращает товары, onlyActive скрывает снятые с продажи
+func (d *DB) GetShopItems(ctx context.Context, onlyActive bool) ([]*types.ShopItem, error) {
+	rows, err := d.Conn.Query(ctx, "select id, name, description, price, stock, per_user_limit, active from shop_items where active or not $1 order by id", onlyActive)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.ShopItem])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+// Redeem списывает с баланса стоимость товара и создает заказ. Баланс пользователя и остаток товара
+// блокируются до конца транзакции, поэтому параллельные покупки не уведут баланс в минус
+func (d *DB) Redeem(ctx context.Context, userID int, request *types.RedeemRequest) (*types.Order, error) {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	var balance types.Amount
+	err = tx.QueryRow(ctx, "select balance from users where id = $1 for update", userID).Scan(&balance)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotExist
+		}
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+
+	item := &types.ShopItem{}
+	err = tx.QueryRow(ctx, "select id, price, stock, per_user_limit, active from shop_items where id = $1 for update", request.ItemID).
+		Scan(&item.ID, &item.Price, &item.Stock, &item.PerUserLimit, &item.Active)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrItemNotExist
+		}
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if !item.Active {
+		rollback()
+		return nil, ErrItemNotExist
+	}
+	if item.Stock != nil && *item.Stock < request.Quantity {
+		rollback()
+		return nil, ErrOutOfStock
+	}
+	if item.PerUserLimit != nil {
+		var bought int
+		err = tx.QueryRow(ctx, "select coalesce(sum(quantity), 0) from orders where user_id = $1 and item_id = $2 and status <> $3", userID, item.ID, types.OrderStatusCancelled).Scan(&bought)
+		if err != nil {
+			rollback()
+			return nil, errors.Wrap(err, "row.Scan failed: ")
+		}
+		if bought+request.Quantity > *item.PerUserLimit {
+			rollback()
+			return nil, ErrPurchaseLimitExceeded
+		}
+	}
+	price, err := item.Price.Mul(request.Quantity)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "item.Price.Mul failed: ")
+	}
+	if balance < price {
+		rollback()
+		return nil, ErrInsufficientBalance
+	}
+
+	_, err = tx.Exec(ctx, "update shop_items set stock = stock - $2 where id = $1 and stock is not null", item.ID, request.Quantity)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "tx.Exec failed: ")
+	}
+	rows, err := tx.Query(ctx, "insert into orders (user_id, item_id, quantity, price, status) values ($1, $2, $3, $4, $5) returning "+orderColumns,
+		userID, item.ID, request.Quantity, price, types.OrderStatusPending)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "tx.Query failed: ")
+	}
+	order, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByName[types.Order])
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "pgx.CollectOneRow failed: ")
+	}
+	_, err = addBalance(ctx, tx, userID, types.CurrencyPoints, -price, types.LedgerOrder, order.ID)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	parts, err := consumeLots(ctx, tx, userID, price)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	// сроки списанных партий нужны, чтобы при отмене заказа вернуть баллы с ними же
+	for _, part := range parts {
+		_, err = tx.Exec(ctx, "insert into order_lots (order_id, amount, expires_at) values ($1, $2, $3)", order.ID, part.amount, part.expiresAt)
+		if err != nil {
+			rollback()
+			return nil, errors.Wrap(err, "tx.Exec failed: ")
+		}
+	}
+	return order, tx.Commit(ctx)
+}
+
+// GetOrders возвращает заказы пользователя, userID == 0 - заказы всех пользователей, status == "" - в любом статусе
+func (d *DB) GetOrders(ctx context.Context, userID int, status string) ([]*types.Order, error) {
+	rows, err := d.Conn.Query(ctx, "select "+orderColumns+" from orders where ($1 = 0 or user_id = $1) and ($2 = '' or status = $2) order by created_at desc, id desc", userID, status)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.Order])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+// FulfilOrder отмечает заказ выданным
+func (d *DB) FulfilOrder(ctx context.Context, orderID int) error {
+	tag, err := d.Conn.Exec(ctx, "update orders set status = $2, updated_at = now() where id = $1 and status = $3", orderID, types.OrderStatusFulfilled, types.OrderStatusPending)
+	if err != nil {
+		return errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	if tag.RowsAffected() == 0 {
+		return d.orderNotPendingError(ctx, orderID)
+	}
+	return nil
+}
+
+// CancelOrder отменяет заказ, возвращает пользователю списанный баланс и товар на склад
+func (d *DB) CancelOrder(ctx context.Context, orderID int) error {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	var userID, itemID, quantity int
+	var price types.Amount
+	err = tx.QueryRow(ctx, "update orders set status = $2, updated_at = now() where id = $1 and status = $3 returning user_id, item_id, quantity, price",
+		orderID, types.OrderStatusCancelled, types.OrderStatusPending).Scan(&userID, &itemID, &quantity, &price)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return d.orderNotPendingError(ctx, orderID)
+		}
+		return errors.Wrap(err, "row.Scan failed: ")
+	}
+	_, err = addBalance(ctx, tx, userID, types.CurrencyPoints, price, types.LedgerOrderRefund, orderID)
+	if err != nil {
+		rollback()
+		return err
+	}
+	// баллы возвращаются с теми сроками сгорания, что были у потраченных партий, у заказов до order_lots - с новым сроком
+	rows, err := tx.Query(ctx, "select amount, expires_at from order_lots where order_id = $1 order by expires_at nulls last", orderID)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Query failed: ")
+	}
+	parts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (lotPart, error) {
+		var part lotPart
+		err := row.Scan(&part.amount, &part.expiresAt)
+		return part, err
+	})
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	err = d.moveLots(ctx, tx, userID, price, parts, types.LotSourceRefund)
+	if err != nil {
+		rollback()
+		return err
+	}
+	_, err = tx.Exec(ctx, "update shop_items set stock = stock + $2 where id = $1 and stock is not null", itemID, quantity)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Exec failed: ")
+	}
+	return tx.Commit(ctx)
+}
+
+func (d *DB) orderNotPendingError(ctx context.Context, orderID int) error {
+	var exists bool
+	err := d.Conn.QueryRow(ctx, "select exists(select 1 from orders where id = $1)", orderID).Scan(&exists)
+	if err != nil {
+		return errors.Wrap(err, "row.Scan failed: ")
+	}
+	if !exists {
+		return ErrOrderNotExist
+	}
+	return ErrOrderNotPending
+}
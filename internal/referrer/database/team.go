@@ -0,0 +1,371 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+const teamGoalColumns = "id, team_id, description, target, progress, bonus, reached_at, paid_at, created_at"
+
+// CreateTeam создает команду, владельцем которой становится создатель, и сразу добавляет его в нее
+func (d *DB) CreateTeam(ctx context.Context, userID int, name, inviteCode string) (int, error) {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	if err = lockTeamlessUser(ctx, tx, userID); err != nil {
+		rollback()
+		return 0, err
+	}
+	var id int
+	err = tx.QueryRow(ctx, "insert into teams (name, invite_code, owner_id) values ($1, $2, $3) on conflict (name) do nothing returning id", name, inviteCode, userID).Scan(&id)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrTeamAlreadyExist
+		}
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	_, err = tx.Exec(ctx, "update users set team_id = $2, team_joined_at = now() where id = $1", userID, id)
+	if err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "tx.Exec failed: ")
+	}
+	return id, tx.Commit(ctx)
+}
+
+// JoinTeam добавляет пользователя в команду с кодом приглашения inviteCode, maxMembers 0 - без ограничения.
+// teamID не 0 - код должен быть от этой команды
+func (d *DB) JoinTeam(ctx context.Context, userID, teamID int, inviteCode string, maxMembers int) (int, error) {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	// пользователь блокируется раньше команды, как и при начислении очков команде в CompleteTask
+	if err = lockTeamlessUser(ctx, tx, userID); err != nil {
+		rollback()
+		return 0, err
+	}
+	// блокировка команды не дает двум одновременным вступлениям превысить maxMembers
+	err = tx.QueryRow(ctx, "select id from teams where invite_code = $1 and ($2 = 0 or id = $2) for update", inviteCode, teamID).Scan(&teamID)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrTeamNotExist
+		}
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if maxMembers > 0 {
+		var members int
+		err = tx.QueryRow(ctx, "select count(*) from users where team_id = $1", teamID).Scan(&members)
+		if err != nil {
+			rollback()
+			return 0, errors.Wrap(err, "row.Scan failed: ")
+		}
+		if members >= maxMembers {
+			rollback()
+			return 0, ErrTeamFull
+		}
+	}
+	_, err = tx.Exec(ctx, "update users set team_id = $2, team_joined_at = now() where id = $1", userID, teamID)
+	if err != nil {
+		rollback()
+		return 0, errors.Wrap(err, "tx.Exec failed: ")
+	}
+	return teamID, tx.Commit(ctx)
+}
+
+// LeaveTeam выводит пользователя из команды. Если уходит владелец, команда переходит к участнику
+// с наименьшим id, последний ушедший оставляет команду без владельца, очки и цели команды сохраняются
+func (d *DB) LeaveTeam(ctx context.Context, userID int) error {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	var teamID *int
+	err = tx.QueryRow(ctx, "select team_id from users where id = $1 for update", userID).Scan(&teamID)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotExist
+		}
+		return errors.Wrap(err, "row.Scan failed: ")
+	}
+	if teamID == nil {
+		rollback()
+		return ErrNotInTeam
+	}
+	_, err = tx.Exec(ctx, "update users set team_id = null, team_joined_at = null where id = $1", userID)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Exec failed: ")
+	}
+	_, err = tx.Exec(ctx, `update teams set owner_id = (select min(id) from users where team_id = $1)
+where id = $1 and owner_id = $2`, *teamID, userID)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Exec failed: ")
+	}
+	return tx.Commit(ctx)
+}
+
+// lockTeamlessUser блокирует пользователя и проверяет, что он еще не состоит в команде
+func lockTeamlessUser(ctx context.Context, tx pgx.Tx, userID int) error {
+	var teamID *int
+	err := tx.QueryRow(ctx, "select team_id from users where id = $1 for update", userID).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrUserNotExist
+	}
+	if err != nil {
+		return errors.Wrap(err, "row.Scan failed: ")
+	}
+	if teamID != nil {
+		return ErrAlreadyInTeam
+	}
+	return nil
+}
+
+// GetUserTeam возвращает команду пользователя с участниками и целями
+func (d *DB) GetUserTeam(ctx context.Context, userID int) (*types.Team, error) {
+	var teamID *int
+	err := d.Conn.QueryRow(ctx, "select team_id from users where id = $1", userID).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotExist
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if teamID == nil {
+		return nil, ErrNotInTeam
+	}
+
+	team := &types.Team{}
+	row := d.Conn.QueryRow(ctx, "select id, name, invite_code, owner_id, points, created_at from teams where id = $1", *teamID)
+	err = row.Scan(&team.ID, &team.Name, &team.InviteCode, &team.OwnerID, &team.Points, &team.CreatedAt)
+	if err != nil {
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	rows, err := d.Conn.Query(ctx, "select id, first_name, last_name, user_name, team_joined_at from users where team_id = $1 order by team_joined_at, id", team.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	team.Members, err = pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.TeamMember])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	rows, err = d.Conn.Query(ctx, "select "+teamGoalColumns+" from team_goals where team_id = $1 order by created_at, id", team.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	team.Goals, err = pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.TeamGoal])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return team, nil
+}
+
+// GetTeamLeaderBoard ранжирует команды по очкам
+func (d *DB) GetTeamLeaderBoard(ctx context.Context, limit, offset int) ([]*types.RankedTeam, int, error) {
+	rows, err := d.Conn.Query(ctx, `select t.id, t.name, t.points,
+       (select count(*) from users u where u.team_id = t.id)::int as members,
+       rank() over (order by t.points desc) as rank,
+       dense_rank() over (order by t.points desc) as dense_rank,
+       row_number() over (order by t.points desc, t.id) as position
+from teams t
+order by position
+limit $1 offset $2`, limit, offset)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.RankedTeam])
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	var total int
+	err = d.Conn.QueryRow(ctx, "select count(*) from teams").Scan(&total)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return result, total, nil
+}
+
+// CreateTeamGoal ставит команде цель, прогресс по ней считается с момента создания
+func (d *DB) CreateTeamGoal(ctx context.Context, goal *types.TeamGoal) (int, error) {
+	var id int
+	err := d.Conn.QueryRow(ctx, "insert into team_goals (team_id, description, target, bonus) values ($1, $2, $3, $4) returning id",
+		goal.TeamID, goal.Description, goal.Target, goal.Bonus).Scan(&id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+		return 0, ErrTeamNotExist
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return id, nil
+}
+
+// accrueTeamPoints начисляет очки команде пользователя и продвигает ее цели, отмечая достигнутые.
+// Должен вызываться в той же транзакции что и изменение баланса, пользователь должен быть заблокирован.
+// Бонусы за цели выплачиваются отдельно в PayTeamGoal, чтобы не блокировать других участников здесь
+func accrueTeamPoints(ctx context.Context, tx pgx.Tx, userID int, points types.Amount) error {
+	var teamID int
+	err := tx.QueryRow(ctx, "update teams set points = points + $2 where id = (select team_id from users where id = $1) returning id", userID, points).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "update teams failed: ")
+	}
+	_, err = tx.Exec(ctx, `update team_goals
+set progress = progress + $2, reached_at = case when progress + $2 >= target then now() end
+where team_id = $1 and reached_at is null`, teamID, points)
+	if err != nil {
+		return errors.Wrap(err, "update team_goals failed: ")
+	}
+	return nil
+}
+
+// revokeTeamPoints забирает у текущей команды пользователя очки отозванной награды и откатывает прогресс ее
+// невыплаченных целей, цель, которая после этого не дотягивает до target, снова считается недостигнутой.
+// Выплаченные цели не трогаются. Пользователь должен быть заблокирован
+func revokeTeamPoints(ctx context.Context, tx pgx.Tx, userID int, points types.Amount) error {
+	var teamID int
+	err := tx.QueryRow(ctx, "update teams set points = greatest(points - $2, 0) where id = (select team_id from users where id = $1) returning id", userID, points).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "update teams failed: ")
+	}
+	_, err = tx.Exec(ctx, `update team_goals
+set progress = greatest(progress - $2, 0), reached_at = case when progress - $2 >= target then reached_at end
+where team_id = $1 and paid_at is null`, teamID, points)
+	if err != nil {
+		return errors.Wrap(err, "update team_goals failed: ")
+	}
+	return nil
+}
+
+// GetTeamGoalsToPay возвращает достигнутые цели, бонусы за которые еще не выплачены
+func (d *DB) GetTeamGoalsToPay(ctx context.Context) ([]int, error) {
+	rows, err := d.Conn.Query(ctx, "select id from team_goals where reached_at is not null and paid_at is null order by reached_at, id")
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return ids, nil
+}
+
+// PayTeamGoal начисляет бонус цели тем, кто вступил в команду не позже достижения цели и все еще в ней состоит,
+// и уведомляет их. Уже выплаченная цель пропускается
+func (d *DB) PayTeamGoal(ctx context.Context, goalID int) error {
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	var teamID int
+	var teamName, description string
+	var bonus types.Amount
+	var reachedAt time.Time
+	err = tx.QueryRow(ctx, `select g.team_id, t.name, g.description, g.bonus, g.reached_at
+from team_goals g join teams t on t.id = g.team_id
+where g.id = $1 and g.reached_at is not null and g.paid_at is null`, goalID).Scan(&teamID, &teamName, &description, &bonus, &reachedAt)
+	if err != nil {
+		rollback()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return errors.Wrap(err, "row.Scan failed: ")
+	}
+
+	// участники блокируются раньше цели и по возрастанию id, как везде где блокируется несколько пользователей.
+	// Вступившие после достижения цели ее не зарабатывали и бонус не получают
+	rows, err := tx.Query(ctx, "select id from users where team_id = $1 and team_joined_at <= $2 order by id for update", teamID, reachedAt)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Query failed: ")
+	}
+	members, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+
+	tag, err := tx.Exec(ctx, "update team_goals set paid_at = now() where id = $1 and reached_at is not null and paid_at is null", goalID)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Exec failed: ")
+	}
+	if tag.RowsAffected() == 0 {
+		rollback()
+		return nil
+	}
+
+	message := fmt.Sprintf("Команда «%s» достигла цели", teamName)
+	if description != "" {
+		message += fmt.Sprintf(" «%s»", description)
+	}
+	if bonus > 0 {
+		message += fmt.Sprintf(", начислено %s", bonus)
+	}
+	for _, memberID := range members {
+		if bonus > 0 {
+			if _, err = addBalance(ctx, tx, memberID, types.CurrencyPoints, bonus, types.RewardSourceTeamGoal, goalID); err != nil {
+				rollback()
+				return err
+			}
+			err = insertRewardEvent(ctx, tx, memberID, bonus, types.CurrencyPoints, types.RewardSourceTeamGoal, goalID)
+			if err != nil {
+				rollback()
+				return err
+			}
+			if err = d.addLot(ctx, tx, memberID, bonus, types.RewardSourceTeamGoal); err != nil {
+				rollback()
+				return err
+			}
+		}
+		if err = insertNotification(ctx, tx, memberID, types.NotificationTeamGoal, message); err != nil {
+			rollback()
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const transferColumns = "id, from_user_id, to_user_id, amount, created_at"
+
+// Transfer переводит amount с баланса fromUserID на баланс получателя. Повтор запроса с тем же idempotencyKey
+// возвращает уже выполненный перевод, а не переводит еще раз
+func (d *DB) Transfer(ctx context.Context, fromUserID int, request *types.TransferRequest, idempotencyKey string, limits types.TransferLimits) (*types.Transfer, error) {
+	if fromUserID == request.ToUserID {
+		return nil, ErrSelfTransfer
+	}
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	// блокируем обоих всегда в порядке id, чтобы встречные переводы не словили дедлок
+	rows, err := tx.Query(ctx, "select id, balance, created_at from users where id in ($1, $2) order by id for update", fromUserID, request.ToUserID)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "tx.Query failed: ")
+	}
+	var senderBalance types.Amount
+	var senderCreatedAt time.Time
+	found := 0
+	for rows.Next() {
+		var id int
+		var balance types.Amount
+		var createdAt time.Time
+		if err = rows.Scan(&id, &balance, &createdAt); err != nil {
+			rows.Close()
+			rollback()
+			return nil, errors.Wrap(err, "rows.Scan failed: ")
+		}
+		if id == fromUserID {
+			senderBalance, senderCreatedAt = balance, createdAt
+		}
+		found++
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "rows.Err: ")
+	}
+	if found != 2 {
+		rollback()
+		return nil, ErrUserNotExist
+	}
+
+	// отправитель заблокирован, поэтому повтор с тем же ключом увидит уже закоммиченный перевод
+	rows, err = tx.Query(ctx, "select "+transferColumns+" from transfers where from_user_id = $1 and idempotency_key = $2", fromUserID, idempotencyKey)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "tx.Query failed: ")
+	}
+	existing, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByNameLax[types.Transfer])
+	if err == nil {
+		rollback()
+		if existing.ToUserID != request.ToUserID || existing.Amount != request.Amount {
+			return nil, ErrIdempotencyKeyReused
+		}
+		existing.Replayed = true
+		return existing, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		rollback()
+		return nil, errors.Wrap(err, "pgx.CollectOneRow failed: ")
+	}
+
+	if senderCreatedAt.After(limits.RegisteredBefore) {
+		rollback()
+		return nil, ErrAccountTooNew
+	}
+	if limits.DailyLimit > 0 {
+		var sent types.Amount
+		err = tx.QueryRow(ctx, "select coalesce(sum(amount), 0) from transfers where from_user_id = $1 and created_at >= $2", fromUserID, limits.DayStart).Scan(&sent)
+		if err != nil {
+			rollback()
+			return nil, errors.Wrap(err, "row.Scan failed: ")
+		}
+		// сумма, которая не помещается в Amount, лимит точно превышает
+		total, err := sent.Add(request.Amount)
+		if err != nil || total > limits.DailyLimit {
+			rollback()
+			return nil, ErrTransferLimitExceeded
+		}
+	}
+	if senderBalance < request.Amount {
+		rollback()
+		return nil, ErrInsufficientBalance
+	}
+
+	rows, err = tx.Query(ctx, "insert into transfers (from_user_id, to_user_id, amount, idempotency_key) values ($1, $2, $3, $4) returning "+transferColumns,
+		fromUserID, request.ToUserID, request.Amount, idempotencyKey)
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "tx.Query failed: ")
+	}
+	transfer, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByNameLax[types.Transfer])
+	if err != nil {
+		rollback()
+		return nil, errors.Wrap(err, "pgx.CollectOneRow failed: ")
+	}
+	_, err = addBalance(ctx, tx, fromUserID, types.CurrencyPoints, -request.Amount, types.LedgerTransferOut, transfer.ID)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	parts, err := consumeLots(ctx, tx, fromUserID, request.Amount)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	_, err = addBalance(ctx, tx, request.ToUserID, types.CurrencyPoints, request.Amount, types.LedgerTransferIn, transfer.ID)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	// баллы переходят получателю с тем же сроком сгорания, иначе переводом туда и обратно срок можно было бы продлевать
+	err = d.moveLots(ctx, tx, request.ToUserID, request.Amount, parts, types.LotSourceTransfer)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	return transfer, tx.Commit(ctx)
+}
+
+// GetTransfers возвращает входящие и исходящие переводы пользователя
+func (d *DB) GetTransfers(ctx context.Context, userID int) ([]*types.Transfer, error) {
+	rows, err := d.Conn.Query(ctx, "select "+transferColumns+" from transfers where from_user_id = $1 or to_user_id = $1 order by created_at desc, id desc", userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByNameLax[types.Transfer])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
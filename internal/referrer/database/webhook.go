@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const webhookColumns = "id, url, event_types, active, created_at"
+
+const webhookDeliveryColumns = "id, webhook_id, event_id::text as event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, delivered_at, created_at, replay_of"
+
+func (d *DB) CreateWebhook(ctx context.Context, webhook *types.WebhookRequest) (int, error) {
+	var id int
+	err := d.Conn.QueryRow(ctx, "insert into webhooks (url, event_types, secret, active) values ($1, $2, $3, $4) returning id",
+		webhook.URL, webhook.EventTypes, webhook.Secret, webhook.Active).Scan(&id)
+	if err != nil {
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return id, nil
+}
+
+func (d *DB) UpdateWebhook(ctx context.Context, id int, webhook *types.WebhookRequest) error {
+	tag, err := d.Conn.Exec(ctx, "update webhooks set url = $2, event_types = $3, secret = $4, active = $5 where id = $1",
+		id, webhook.URL, webhook.EventTypes, webhook.Secret, webhook.Active)
+	if err != nil {
+		return errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotExist
+	}
+	return nil
+}
+
+func (d *DB) GetWebhooks(ctx context.Context) ([]*types.Webhook, error) {
+	rows, err := d.Conn.Query(ctx, "select "+webhookColumns+" from webhooks order by id")
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.Webhook])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+// GetWebhookDeliveries возвращает доставки подписки от новых к старым, пустой status - во всех статусах
+func (d *DB) GetWebhookDeliveries(ctx context.Context, webhookID int, status string, limit, offset int) ([]*types.WebhookDelivery, error) {
+	var exists bool
+	err := d.Conn.QueryRow(ctx, "select exists(select 1 from webhooks where id = $1)", webhookID).Scan(&exists)
+	if err != nil {
+		return nil, errors.Wrap(err, "row.Scan failed: ")
+	}
+	if !exists {
+		return nil, ErrWebhookNotExist
+	}
+	rows, err := d.Conn.Query(ctx, "select "+webhookDeliveryColumns+" from webhook_deliveries where webhook_id = $1 and ($2 = '' or status = $2) order by id desc limit $3 offset $4",
+		webhookID, status, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.WebhookDelivery])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+// GetWebhookDelivery возвращает доставку вместе со всеми попытками
+func (d *DB) GetWebhookDelivery(ctx context.Context, id int) (*types.WebhookDelivery, error) {
+	rows, err := d.Conn.Query(ctx, "select "+webhookDeliveryColumns+" from webhook_deliveries where id = $1", id)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	delivery, err := pgx.CollectExactlyOneRow(rows, pgx.RowToAddrOfStructByName[types.WebhookDelivery])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookDeliveryNotExist
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectExactlyOneRow failed: ")
+	}
+	rows, err = d.Conn.Query(ctx, "select status_code, error, duration_ms, attempted_at from webhook_delivery_attempts where delivery_id = $1 order by id", id)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	delivery.AttemptLog, err = pgx.CollectRows(rows, pgx.RowToAddrOfStructByName[types.WebhookAttempt])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return delivery, nil
+}
+
+// ReplayWebhookDelivery ставит событие доставки в очередь заново отдельной доставкой, старая остается в журнале как была.
+// Событие уходит с тем же id, поэтому получатель, уже принявший его, может отбросить повтор. Повтор ссылается
+// на первую доставку события, на повторы уникальность события у подписки не распространяется
+func (d *DB) ReplayWebhookDelivery(ctx context.Context, id int) (int, error) {
+	var replayID int
+	err := d.Conn.QueryRow(ctx, `insert into webhook_deliveries (webhook_id, event_id, event_type, payload, replay_of)
+select webhook_id, event_id, event_type, payload, coalesce(replay_of, id) from webhook_deliveries where id = $1 returning id`, id).Scan(&replayID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrWebhookDeliveryNotExist
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "row.Scan failed: ")
+	}
+	return replayID, nil
+}
+
+// ClaimWebhookDeliveries берет на отправку до limit доставок, которым пора уйти, и откладывает их до leaseUntil:
+// если отправитель упадет, не записав результат, доставку повторят после leaseUntil. Доставки отключенных
+// подписок ждут, пока подписку не включат
+func (d *DB) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*types.PendingWebhookDelivery, error) {
+	rows, err := d.Conn.Query(ctx, `with claimed as (
+    update webhook_deliveries set attempts = attempts + 1, next_attempt_at = $2
+    where id in (select d.id from webhook_deliveries d join webhooks w on w.id = d.webhook_id
+                 where d.status = $4 and d.next_attempt_at <= $1 and w.active
+                 order by d.next_attempt_at limit $3 for update of d skip locked)
+    returning id, webhook_id, event_id, event_type, payload, attempts
+)
+select c.id, c.event_id::text, c.event_type, c.payload, c.attempts, w.url, w.secret from claimed c join webhooks w on w.id = c.webhook_id`,
+		now, leaseUntil, limit, types.WebhookDeliveryPending)
+	if err != nil {
+		return nil, errors.Wrap(err, "Conn.Query failed: ")
+	}
+	result, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[types.PendingWebhookDelivery])
+	if err != nil {
+		return nil, errors.Wrap(err, "pgx.CollectRows failed: ")
+	}
+	return result, nil
+}
+
+// RecordWebhookAttempt пишет попытку в журнал и переводит доставку в status. Для pending nextAttemptAt -
+// когда повторить, для остальных статусов не используется
+func (d *DB) RecordWebhookAttempt(ctx context.Context, deliveryID int, attempt *types.WebhookAttempt, status string, nextAttemptAt time.Time) error {
+	var next, delivered *time.Time
+	switch status {
+	case types.WebhookDeliveryPending:
+		next = &nextAttemptAt
+	case types.WebhookDeliveryDelivered:
+		delivered = &attempt.AttemptedAt
+	}
+
+	tx, err := d.Conn.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "conn.Begin failed: ")
+	}
+
+	rollback := func() {
+		if err := tx.Rollback(ctx); err != nil {
+			d.logger.Error("tx.Rollback failed", zap.Error(err))
+		}
+	}
+
+	_, err = tx.Exec(ctx, "insert into webhook_delivery_attempts (delivery_id, status_code, error, duration_ms, attempted_at) values ($1, $2, $3, $4, $5)",
+		deliveryID, attempt.StatusCode, attempt.Error, attempt.DurationMs, attempt.AttemptedAt)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Exec failed: ")
+	}
+	_, err = tx.Exec(ctx, `update webhook_deliveries set status = $2, last_status_code = $3, last_error = $4,
+    next_attempt_at = coalesce($5, next_attempt_at), delivered_at = coalesce($6, delivered_at)
+where id = $1`, deliveryID, status, attempt.StatusCode, attempt.Error, next, delivered)
+	if err != nil {
+		rollback()
+		return errors.Wrap(err, "tx.Exec failed: ")
+	}
+	return tx.Commit(ctx)
+}
+
+// EnqueueWebhooks ставит событие из outbox в очередь всем активным подпискам на его тип. Подписки, которым
+// событие уже поставлено, пропускаются по уникальному индексу, в том числе при одновременной публикации:
+// outbox может опубликовать сообщение повторно
+func (d *DB) EnqueueWebhooks(ctx context.Context, message *types.OutboxMessage) error {
+	_, err := d.Conn.Exec(ctx, `insert into webhook_deliveries (webhook_id, event_id, event_type, payload)
+select w.id, $1::uuid, $2::varchar, $3::jsonb from webhooks w
+where w.active and $2 = any(w.event_types)
+on conflict (event_id, webhook_id) where replay_of is null do nothing`,
+		message.EventID, message.EventType, []byte(message.Payload))
+	if err != nil {
+		return errors.Wrap(err, "Conn.Exec failed: ")
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/config"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// сколько событий может ждать отправки в Postgres, дальше новые отбрасываются
+const outgoingBuffer = 1024
+
+type notifier interface {
+	Notify(ctx context.Context, channel, payload string) error
+	Listen(ctx context.Context, channel string, handle func(payload string)) error
+}
+
+// envelope событие в канале NOTIFY. Instance - кто его отправил, свои события экземпляр уже доставил сам
+type envelope struct {
+	Instance string       `json:"instance"`
+	Event    *types.Event `json:"event"`
+}
+
+// Bus доставляет события подписчикам этого экземпляра и через Postgres LISTEN/NOTIFY подписчикам остальных.
+// События не хранятся: пропущенное во время обрыва соединения не придет, клиент должен перечитать состояние
+type Bus struct {
+	notifier notifier
+	cfg      config.EventsConfig
+	logger   *zap.Logger
+	instance string
+	outgoing chan *types.Event
+
+	mu          sync.Mutex
+	subscribers map[int]map[*Subscription]struct{}
+	closed      bool
+}
+
+// Subscription события одного пользователя. Канал закрывается, когда подписку закрыли, клиент не успевает
+// разбирать события или шина остановлена
+type Subscription struct {
+	bus    *Bus
+	userID int
+	events chan *types.Event
+}
+
+func (s *Subscription) Events() <-chan *types.Event {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	s.bus.remove(s)
+	s.bus.mu.Unlock()
+}
+
+func NewBus(n notifier, cfg config.EventsConfig, logger *zap.Logger) *Bus {
+	return &Bus{
+		notifier:    n,
+		cfg:         cfg,
+		logger:      logger.Named("events"),
+		instance:    uuid.New().String(),
+		outgoing:    make(chan *types.Event, outgoingBuffer),
+		subscribers: map[int]map[*Subscription]struct{}{},
+	}
+}
+
+func (b *Bus) Subscribe(userID int) *Subscription {
+	s := &Subscription{bus: b, userID: userID, events: make(chan *types.Event, b.cfg.Buffer)}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(s.events)
+		return s
+	}
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = map[*Subscription]struct{}{}
+	}
+	b.subscribers[userID][s] = struct{}{}
+	return s
+}
+
+// Publish доставляет события подписчикам сразу и ставит их в очередь на отправку остальным экземплярам, не блокируется
+func (b *Bus) Publish(events ...*types.Event) {
+	for _, event := range events {
+		b.deliver(event)
+		select {
+		case b.outgoing <- event:
+		default:
+			b.logger.Error("outgoing queue is full, event dropped", zap.String("kind", event.Kind), zap.Int("user_id", event.UserID))
+		}
+	}
+}
+
+// HasSubscribers есть ли подписчики у этого экземпляра
+func (b *Bus) HasSubscribers() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers) > 0
+}
+
+// Deliver доставляет события только подписчикам этого экземпляра, для событий, которые каждый экземпляр
+// вычисляет сам
+func (b *Bus) Deliver(events ...*types.Event) {
+	for _, event := range events {
+		b.deliver(event)
+	}
+}
+
+// Run отправляет события в Postgres и слушает события остальных экземпляров, пока не отменен ctx
+func (b *Bus) Run(ctx context.Context) {
+	go b.send(ctx)
+	for {
+		err := b.notifier.Listen(ctx, b.cfg.Channel, b.receive)
+		if ctx.Err() != nil {
+			return
+		}
+		b.logger.Error("notifier.Listen failed: ", zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(b.cfg.ReconnectInterval):
+		}
+	}
+}
+
+// Close закрывает все подписки, чтобы открытые соединения клиентов завершились
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for _, subscriptions := range b.subscribers {
+		for s := range subscriptions {
+			b.remove(s)
+		}
+	}
+}
+
+func (b *Bus) send(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-b.outgoing:
+			payload, err := json.Marshal(envelope{Instance: b.instance, Event: event})
+			if err != nil {
+				b.logger.Error("json.Marshal failed: ", zap.Error(err))
+				continue
+			}
+			if err = b.notifier.Notify(ctx, b.cfg.Channel, string(payload)); err != nil {
+				b.logger.Error("notifier.Notify failed: ", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (b *Bus) receive(payload string) {
+	var message envelope
+	if err := json.Unmarshal([]byte(payload), &message); err != nil || message.Event == nil {
+		b.logger.Error("malformed event notification", zap.String("payload", payload))
+		return
+	}
+	if message.Instance == b.instance {
+		return
+	}
+	b.deliver(message.Event)
+}
+
+func (b *Bus) deliver(event *types.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subscribers[event.UserID] {
+		select {
+		case s.events <- event:
+		default:
+			// лучше оборвать соединение, чем молча терять события: клиент переподключится и перечитает состояние
+			b.remove(s)
+		}
+	}
+}
+
+// remove вызывается под b.mu
+func (b *Bus) remove(s *Subscription) {
+	subscriptions := b.subscribers[s.userID]
+	if _, ok := subscriptions[s]; !ok {
+		return
+	}
+	delete(subscriptions, s)
+	if len(subscriptions) == 0 {
+		delete(b.subscribers, s.userID)
+	}
+	close(s.events)
+}
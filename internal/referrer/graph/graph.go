@@ -0,0 +1,138 @@
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"strconv"
+
+	"github.com/SakuraBurst/denet/internal/referrer/config"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+//go:embed schema.graphql
+var schemaString string
+
+type controller interface {
+	GetAllTasks(ctx context.Context) ([]*types.Task, error)
+	GetTopUsers(ctx context.Context, limit, offset int) (*types.LeaderBoard, error)
+	GetUsersByIDs(ctx context.Context, userIDs []int) ([]*types.User, error)
+	GetRefereesByUserIDs(ctx context.Context, userIDs []int) ([]*types.User, error)
+	GetUserRanks(ctx context.Context, userIDs []int) ([]*types.RankedUser, error)
+	GetCompletionsByUserIDs(ctx context.Context, userIDs []int) ([]*types.Completion, error)
+	GetTasksByIDs(ctx context.Context, taskIDs []int) ([]*types.Task, error)
+}
+
+// Request тело запроса GraphQL по HTTP
+type Request struct {
+	Query         string         `json:"query" validate:"required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+const CodeComplexityLimitExceeded = "COMPLEXITY_LIMIT_EXCEEDED"
+
+// Executor выполняет запросы GraphQL. Глубина запроса ограничивается при выполнении,
+// сложность считается заранее: каждое поле стоит 1, поля внутри списка - столько раз,
+// сколько элементов может в нем быть
+type Executor struct {
+	controller controller
+	schema     *graphql.Schema
+	// typed схема для подсчета сложности, graphql-go не отдает разобранный запрос
+	typed *ast.Schema
+	cfg   config.GraphQLConfig
+}
+
+func NewExecutor(c controller, cfg config.GraphQLConfig) *Executor {
+	return &Executor{
+		controller: c,
+		schema: graphql.MustParseSchema(schemaString, &queryResolver{controller: c},
+			graphql.MaxDepth(cfg.MaxDepth),
+			graphql.MaxParallelism(cfg.MaxParallelism),
+			graphql.UseStringDescriptions(),
+		),
+		typed: gqlparser.MustLoadSchema(&ast.Source{Name: "schema.graphql", Input: schemaString}),
+		cfg:   cfg,
+	}
+}
+
+// Execute выполняет запрос от имени пользователя userID. Ошибки резолверов остаются в ответе
+// с исходными ошибками в ResolverError, переводить их в сообщения для клиента должен вызывающий
+func (e *Executor) Execute(ctx context.Context, userID int, req *Request) *graphql.Response {
+	if complexity := e.complexity(req); complexity > e.cfg.MaxComplexity {
+		err := gqlerrors.Errorf("Запрос слишком сложный: %d при максимуме %d", complexity, e.cfg.MaxComplexity)
+		err.Extensions = map[string]any{"code": CodeComplexityLimitExceeded}
+		return &graphql.Response{Errors: []*gqlerrors.QueryError{err}}
+	}
+	ctx = context.WithValue(ctx, requestKey{}, &request{loaders: newLoaders(e.controller, e.cfg.BatchWait), userID: userID})
+	return e.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+}
+
+// complexity сложность запроса, 0 если он не разбирается - тогда ошибку вернет выполнение
+func (e *Executor) complexity(req *Request) int {
+	doc, errs := gqlparser.LoadQuery(e.typed, req.Query)
+	if errs != nil {
+		return 0
+	}
+	var operation *ast.OperationDefinition
+	if req.OperationName == "" && len(doc.Operations) == 1 {
+		operation = doc.Operations[0]
+	} else {
+		operation = doc.Operations.ForName(req.OperationName)
+	}
+	if operation == nil {
+		return 0
+	}
+	return e.selectionComplexity(operation.SelectionSet, req.Variables, 0)
+}
+
+// selectionComplexity pageSize - limit родительского поля, столько элементов будет в его списках
+func (e *Executor) selectionComplexity(set ast.SelectionSet, variables map[string]any, pageSize int) int {
+	total := 0
+	for _, selection := range set {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			multiplier := 1
+			if selection.Definition != nil && selection.Definition.Type.Elem != nil {
+				multiplier = e.cfg.ListSize
+				if pageSize > 0 {
+					multiplier = pageSize
+				}
+			}
+			children := e.selectionComplexity(selection.SelectionSet, variables, limitArgument(selection, variables))
+			total += multiplier * (1 + children)
+		case *ast.InlineFragment:
+			total += e.selectionComplexity(selection.SelectionSet, variables, pageSize)
+		case *ast.FragmentSpread:
+			if selection.Definition != nil {
+				total += e.selectionComplexity(selection.Definition.SelectionSet, variables, pageSize)
+			}
+		}
+	}
+	return total
+}
+
+// limitArgument значение аргумента limit поля, 0 если его нет
+func limitArgument(field *ast.Field, variables map[string]any) int {
+	argument := field.Arguments.ForName("limit")
+	if argument == nil {
+		return 0
+	}
+	value, err := argument.Value.Value(variables)
+	if err != nil {
+		return 0
+	}
+	switch value := value.(type) {
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	case string:
+		limit, _ := strconv.Atoi(value)
+		return limit
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// loader собирает ключи, которые резолверы одного запроса просят за время wait, и загружает их
+// одним вызовом fetch. Результаты запоминаются до конца запроса, ключа без результата в fetch соответствует нулевое значение
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+	wait  time.Duration
+
+	mu      sync.Mutex
+	results map[K]*loadResult[V]
+	pending map[K]*loadResult[V]
+}
+
+type loadResult[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func newLoader[K comparable, V any](wait time.Duration, fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, wait: wait, results: map[K]*loadResult[V]{}}
+}
+
+func (l *loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	result, ok := l.results[key]
+	if !ok {
+		result = &loadResult[V]{done: make(chan struct{})}
+		l.results[key] = result
+		if l.pending == nil {
+			l.pending = map[K]*loadResult[V]{}
+			time.AfterFunc(l.wait, func() { l.dispatch(ctx) })
+		}
+		l.pending[key] = result
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-result.done:
+		return result.value, result.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// Prime кладет уже известное значение, чтобы его не загружать
+func (l *loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.results[key]; ok {
+		return
+	}
+	result := &loadResult[V]{done: make(chan struct{}), value: value}
+	close(result.done)
+	l.results[key] = result
+}
+
+func (l *loader[K, V]) dispatch(ctx context.Context) {
+	l.mu.Lock()
+	batch := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	keys := make([]K, 0, len(batch))
+	for key := range batch {
+		keys = append(keys, key)
+	}
+	values, err := l.fetch(ctx, keys)
+	for key, result := range batch {
+		result.value, result.err = values[key], err
+		close(result.done)
+	}
+}
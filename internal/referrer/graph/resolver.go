@@ -0,0 +1,275 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/database"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+)
+
+// loaders загрузчики одного запроса, все связи между объектами идут через них
+type loaders struct {
+	users       *loader[int, *types.User]
+	referrals   *loader[int, []*types.User]
+	ranks       *loader[int, *types.RankedUser]
+	completions *loader[int, []*types.Completion]
+	tasks       *loader[int, *types.Task]
+}
+
+func newLoaders(c controller, wait time.Duration) *loaders {
+	return &loaders{
+		users: newLoader(wait, func(ctx context.Context, ids []int) (map[int]*types.User, error) {
+			users, err := c.GetUsersByIDs(ctx, ids)
+			return byKey(users, err, func(u *types.User) int { return u.ID })
+		}),
+		referrals: newLoader(wait, func(ctx context.Context, ids []int) (map[int][]*types.User, error) {
+			users, err := c.GetRefereesByUserIDs(ctx, ids)
+			return groupByKey(users, err, func(u *types.User) int { return *u.ReferrerID })
+		}),
+		ranks: newLoader(wait, func(ctx context.Context, ids []int) (map[int]*types.RankedUser, error) {
+			ranks, err := c.GetUserRanks(ctx, ids)
+			return byKey(ranks, err, func(r *types.RankedUser) int { return r.ID })
+		}),
+		completions: newLoader(wait, func(ctx context.Context, ids []int) (map[int][]*types.Completion, error) {
+			completions, err := c.GetCompletionsByUserIDs(ctx, ids)
+			return groupByKey(completions, err, func(c *types.Completion) int { return c.UserID })
+		}),
+		tasks: newLoader(wait, func(ctx context.Context, ids []int) (map[int]*types.Task, error) {
+			tasks, err := c.GetTasksByIDs(ctx, ids)
+			return byKey(tasks, err, func(t *types.Task) int { return t.ID })
+		}),
+	}
+}
+
+func byKey[V any](values []V, err error, key func(V) int) (map[int]V, error) {
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int]V, len(values))
+	for _, value := range values {
+		result[key(value)] = value
+	}
+	return result, nil
+}
+
+func groupByKey[V any](values []V, err error, key func(V) int) (map[int][]V, error) {
+	if err != nil {
+		return nil, err
+	}
+	result := map[int][]V{}
+	for _, value := range values {
+		result[key(value)] = append(result[key(value)], value)
+	}
+	return result, nil
+}
+
+// queryResolver корень схемы, общий для всех запросов. Загрузчики и пользователь запроса лежат в контексте
+type queryResolver struct {
+	controller controller
+}
+
+type requestKey struct{}
+
+type request struct {
+	loaders *loaders
+	userID  int
+}
+
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
+
+func (q *queryResolver) Me(ctx context.Context) (*userResolver, error) {
+	user, err := q.user(ctx, requestFrom(ctx).userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, database.ErrUserNotExist
+	}
+	return user, nil
+}
+
+func (q *queryResolver) User(ctx context.Context, args struct{ ID int32 }) (*userResolver, error) {
+	return q.user(ctx, int(args.ID))
+}
+
+// user возвращает nil, если пользователя нет
+func (q *queryResolver) user(ctx context.Context, id int) (*userResolver, error) {
+	loaders := requestFrom(ctx).loaders
+	user, err := loaders.users.Load(ctx, id)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	return &userResolver{user: user, loaders: loaders}, nil
+}
+
+func (q *queryResolver) Task(ctx context.Context, args struct{ ID int32 }) (*taskResolver, error) {
+	task, err := requestFrom(ctx).loaders.tasks.Load(ctx, int(args.ID))
+	if err != nil || task == nil {
+		return nil, err
+	}
+	return &taskResolver{task: task}, nil
+}
+
+func (q *queryResolver) Tasks(ctx context.Context) ([]*taskResolver, error) {
+	tasks, err := q.controller.GetAllTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*taskResolver, 0, len(tasks))
+	for _, task := range tasks {
+		requestFrom(ctx).loaders.tasks.Prime(task.ID, task)
+		result = append(result, &taskResolver{task: task})
+	}
+	return result, nil
+}
+
+func (q *queryResolver) Leaderboard(ctx context.Context, args struct{ Limit, Offset int32 }) (*leaderBoardResolver, error) {
+	leaderBoard, err := q.controller.GetTopUsers(ctx, int(args.Limit), int(args.Offset))
+	if err != nil {
+		return nil, err
+	}
+	return &leaderBoardResolver{leaderBoard: leaderBoard, loaders: requestFrom(ctx).loaders}, nil
+}
+
+type userResolver struct {
+	user    *types.User
+	loaders *loaders
+}
+
+func (u *userResolver) ID() int32            { return int32(u.user.ID) }
+func (u *userResolver) FirstName() string    { return u.user.FirstName }
+func (u *userResolver) LastName() string     { return u.user.LastName }
+func (u *userResolver) UserName() string     { return u.user.UserName }
+func (u *userResolver) ReferrerCode() string { return u.user.ReferrerCode }
+func (u *userResolver) Balance() string      { return u.user.Balance.String() }
+
+func (u *userResolver) Rank(ctx context.Context) (*rankResolver, error) {
+	rank, err := u.loaders.ranks.Load(ctx, u.user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if rank == nil {
+		return nil, errors.Wrap(database.ErrUserNotExist, "rank not found: ")
+	}
+	return &rankResolver{rank: rank}, nil
+}
+
+func (u *userResolver) Referrer(ctx context.Context) (*userResolver, error) {
+	if u.user.ReferrerID == nil {
+		return nil, nil
+	}
+	referrer, err := u.loaders.users.Load(ctx, *u.user.ReferrerID)
+	if err != nil || referrer == nil {
+		return nil, err
+	}
+	return &userResolver{user: referrer, loaders: u.loaders}, nil
+}
+
+func (u *userResolver) Referrals(ctx context.Context) ([]*userResolver, error) {
+	referrals, err := u.loaders.referrals.Load(ctx, u.user.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*userResolver, 0, len(referrals))
+	for _, referral := range referrals {
+		u.loaders.users.Prime(referral.ID, referral)
+		result = append(result, &userResolver{user: referral, loaders: u.loaders})
+	}
+	return result, nil
+}
+
+func (u *userResolver) Completions(ctx context.Context) ([]*completionResolver, error) {
+	completions, err := u.loaders.completions.Load(ctx, u.user.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*completionResolver, 0, len(completions))
+	for _, completion := range completions {
+		result = append(result, &completionResolver{completion: completion, loaders: u.loaders})
+	}
+	return result, nil
+}
+
+type rankResolver struct {
+	rank *types.RankedUser
+}
+
+func (r *rankResolver) Rank() int32      { return int32(r.rank.Rank) }
+func (r *rankResolver) DenseRank() int32 { return int32(r.rank.DenseRank) }
+func (r *rankResolver) Position() int32  { return int32(r.rank.Position) }
+
+type completionResolver struct {
+	completion *types.Completion
+	loaders    *loaders
+}
+
+func (c *completionResolver) Task(ctx context.Context) (*taskResolver, error) {
+	task, err := c.loaders.tasks.Load(ctx, c.completion.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, database.ErrTaskNotExist
+	}
+	return &taskResolver{task: task}, nil
+}
+
+func (c *completionResolver) CompletedAt() *string {
+	if c.completion.CompletedAt == nil {
+		return nil
+	}
+	completedAt := c.completion.CompletedAt.Format(time.RFC3339)
+	return &completedAt
+}
+
+type taskResolver struct {
+	task *types.Task
+}
+
+func (t *taskResolver) ID() int32           { return int32(t.task.ID) }
+func (t *taskResolver) Description() string { return t.task.Description }
+func (t *taskResolver) Reward() string      { return t.task.Reward.String() }
+func (t *taskResolver) Currency() string    { return t.task.Currency }
+
+type leaderBoardResolver struct {
+	leaderBoard *types.LeaderBoard
+	loaders     *loaders
+}
+
+func (l *leaderBoardResolver) Users() []*rankedUserResolver {
+	result := make([]*rankedUserResolver, 0, len(l.leaderBoard.Users))
+	for _, user := range l.leaderBoard.Users {
+		l.loaders.ranks.Prime(user.ID, user)
+		result = append(result, &rankedUserResolver{user: user, loaders: l.loaders})
+	}
+	return result
+}
+
+func (l *leaderBoardResolver) Total() int32  { return int32(l.leaderBoard.Total) }
+func (l *leaderBoardResolver) Limit() int32  { return int32(l.leaderBoard.Limit) }
+func (l *leaderBoardResolver) Offset() int32 { return int32(l.leaderBoard.Offset) }
+
+type rankedUserResolver struct {
+	user    *types.RankedUser
+	loaders *loaders
+}
+
+func (r *rankedUserResolver) User(ctx context.Context) (*userResolver, error) {
+	user, err := r.loaders.users.Load(ctx, r.user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, database.ErrUserNotExist
+	}
+	return &userResolver{user: user, loaders: r.loaders}, nil
+}
+
+func (r *rankedUserResolver) Balance() string  { return r.user.Balance.String() }
+func (r *rankedUserResolver) Rank() int32      { return int32(r.user.Rank) }
+func (r *rankedUserResolver) DenseRank() int32 { return int32(r.user.DenseRank) }
+func (r *rankedUserResolver) Position() int32  { return int32(r.user.Position) }
@@ -0,0 +1,87 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/SakuraBurst/denet/internal/referrer/config"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// заголовки сообщений в брокерах
+const (
+	eventIDHeader   = "Event-ID"
+	eventTypeHeader = "Event-Type"
+)
+
+// NATS публикует сообщение в subject_prefix + тип события. В заголовке Nats-Msg-Id id события,
+// JetStream поток на эти темы по нему сам отбрасывает повторы в пределах своего окна дублей
+type NATS struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+func NewNATS(cfg config.OutboxNATSConfig) (*NATS, error) {
+	conn, err := nats.Connect(cfg.URL, nats.Name("referrer-outbox"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, errors.Wrap(err, "nats.Connect failed: ")
+	}
+	return &NATS{conn: conn, prefix: cfg.SubjectPrefix}, nil
+}
+
+func (p *NATS) Publish(ctx context.Context, message *types.OutboxMessage) error {
+	msg := nats.NewMsg(p.prefix + message.EventType)
+	msg.Data = message.Payload
+	msg.Header.Set(nats.MsgIdHdr, message.EventID)
+	msg.Header.Set(eventIDHeader, message.EventID)
+	msg.Header.Set(eventTypeHeader, message.EventType)
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return errors.Wrap(err, "conn.PublishMsg failed: ")
+	}
+	// без Flush сообщение могло остаться в буфере клиента
+	if err := p.conn.FlushWithContext(ctx); err != nil {
+		return errors.Wrap(err, "conn.FlushWithContext failed: ")
+	}
+	return nil
+}
+
+func (p *NATS) Close() error {
+	return p.conn.Drain()
+}
+
+// Kafka публикует сообщение в topic с ключом - id события, так повторы попадают в ту же партицию.
+// Запись ждет подтверждения всех реплик
+type Kafka struct {
+	writer *kafka.Writer
+}
+
+func NewKafka(cfg config.OutboxKafkaConfig) *Kafka {
+	return &Kafka{writer: &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}}
+}
+
+func (p *Kafka) Publish(ctx context.Context, message *types.OutboxMessage) error {
+	err := p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(message.EventID),
+		Value: message.Payload,
+		Headers: []kafka.Header{
+			{Key: eventIDHeader, Value: []byte(message.EventID)},
+			{Key: eventTypeHeader, Value: []byte(message.EventType)},
+		},
+		Time: message.CreatedAt,
+	})
+	if err != nil {
+		return errors.Wrap(err, "writer.WriteMessages failed: ")
+	}
+	return nil
+}
+
+func (p *Kafka) Close() error {
+	return p.writer.Close()
+}
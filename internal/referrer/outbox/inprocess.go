@@ -0,0 +1,43 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+)
+
+// Handler обработчик сообщений внутри процесса, ошибка отправит сообщение на повтор
+type Handler func(ctx context.Context, message *types.OutboxMessage) error
+
+// InProcess передает сообщения обработчикам этого же процесса, например постановке вебхуков в очередь
+type InProcess struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewInProcess() *InProcess {
+	return &InProcess{}
+}
+
+// Handle добавляет обработчик всех сообщений
+func (p *InProcess) Handle(handler Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers = append(p.handlers, handler)
+}
+
+func (p *InProcess) Publish(ctx context.Context, message *types.OutboxMessage) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, handler := range p.handlers {
+		if err := handler(ctx, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *InProcess) Close() error {
+	return nil
+}
@@ -0,0 +1,69 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"go.uber.org/zap"
+)
+
+// Log пишет сообщения в лог приложения, удобно для отладки
+type Log struct {
+	logger *zap.Logger
+}
+
+func NewLog(logger *zap.Logger) *Log {
+	return &Log{logger: logger.Named("outbox")}
+}
+
+func (p *Log) Publish(_ context.Context, message *types.OutboxMessage) error {
+	p.logger.Info("outbox message",
+		zap.String("event_id", message.EventID),
+		zap.String("event_type", message.EventType),
+		zap.Int("attempts", message.Attempts),
+		zap.ByteString("payload", message.Payload))
+	return nil
+}
+
+func (p *Log) Close() error {
+	return nil
+}
+
+// File дописывает сообщения в файл по одному JSON на строку
+type File struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFile(path string) (*File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, errors.Wrap(err, "os.OpenFile failed: ")
+	}
+	return &File{file: file}, nil
+}
+
+func (p *File) Publish(_ context.Context, message *types.OutboxMessage) error {
+	line, err := json.Marshal(message)
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal failed: ")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err = p.file.Write(append(line, '\n')); err != nil {
+		return errors.Wrap(err, "file.Write failed: ")
+	}
+	// сообщение считается опубликованным только когда строка на диске
+	if err = p.file.Sync(); err != nil {
+		return errors.Wrap(err, "file.Sync failed: ")
+	}
+	return nil
+}
+
+func (p *File) Close() error {
+	return p.file.Close()
+}
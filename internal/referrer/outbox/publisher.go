@@ -0,0 +1,75 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/SakuraBurst/denet/internal/referrer/config"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"go.uber.org/zap"
+)
+
+// названия публикаторов в outbox.publishers
+const (
+	PublisherLog   = "log"
+	PublisherFile  = "file"
+	PublisherNATS  = "nats"
+	PublisherKafka = "kafka"
+)
+
+// Publisher отправляет сообщение outbox дальше. Ошибка означает, что сообщение нужно повторить,
+// поэтому публикация должна быть идемпотентной или получатель должен отбрасывать дубли по EventID
+type Publisher interface {
+	Publish(ctx context.Context, message *types.OutboxMessage) error
+	Close() error
+}
+
+// Multi публикует сообщение во все публикаторы по очереди. Если один из них не справился, сообщение
+// повторят целиком, и остальные получат его еще раз
+type Multi []Publisher
+
+func (m Multi) Publish(ctx context.Context, message *types.OutboxMessage) error {
+	for _, publisher := range m {
+		if err := publisher.Publish(ctx, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m Multi) Close() error {
+	var result error
+	for _, publisher := range m {
+		if err := publisher.Close(); err != nil {
+			result = errors.Join(result, err)
+		}
+	}
+	return result
+}
+
+// NewPublisher собирает публикаторы из outbox.publishers
+func NewPublisher(cfg config.OutboxConfig, logger *zap.Logger) (Publisher, error) {
+	result := make(Multi, 0, len(cfg.Publishers))
+	for _, name := range cfg.Publishers {
+		var publisher Publisher
+		var err error
+		switch name {
+		case PublisherLog:
+			publisher = NewLog(logger)
+		case PublisherFile:
+			publisher, err = NewFile(cfg.File)
+		case PublisherNATS:
+			publisher, err = NewNATS(cfg.NATS)
+		case PublisherKafka:
+			publisher = NewKafka(cfg.Kafka)
+		default:
+			err = errors.Errorf("unknown outbox publisher %q", name)
+		}
+		if err != nil {
+			result.Close()
+			return nil, err
+		}
+		result = append(result, publisher)
+	}
+	return result, nil
+}
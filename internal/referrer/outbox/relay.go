@@ -0,0 +1,100 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/pkg/backoff"
+	"github.com/SakuraBurst/denet/internal/referrer/config"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"go.uber.org/zap"
+)
+
+type store interface {
+	ClaimOutbox(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*types.OutboxMessage, error)
+	MarkOutboxPublished(ctx context.Context, id int64, publishedAt time.Time) error
+	RecordOutboxFailure(ctx context.Context, id int64, message string, nextAttemptAt time.Time) error
+	DeletePublishedOutbox(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Relay переносит сообщения из outbox сначала в обработчики этого процесса, потом в публикаторы из
+// outbox.publishers. Сообщение отмечается опубликованным только после успеха всех, поэтому при сбое между
+// ними оно уйдет еще раз. Неудачные повторяются без ограничения числа попыток с удвоением задержки до outbox.max_backoff
+type Relay struct {
+	store     store
+	publisher Publisher
+	cfg       config.OutboxConfig
+	logger    *zap.Logger
+}
+
+// NewRelay собирает relay. inProcess подключен всегда, независимо от outbox.publishers: от него зависят вебхуки
+func NewRelay(s store, inProcess *InProcess, publishers Publisher, cfg config.OutboxConfig, logger *zap.Logger) *Relay {
+	return &Relay{store: s, publisher: Multi{inProcess, publishers}, cfg: cfg, logger: logger.Named("outbox")}
+}
+
+// Publish публикует сообщения, которым пора уйти к моменту now, пока они не кончатся. Внутри пачки
+// сообщения уходят в порядке записи
+func (r *Relay) Publish(ctx context.Context, now time.Time) error {
+	for {
+		messages, err := r.publishBatch(ctx, now)
+		if err != nil {
+			return err
+		}
+		if messages < r.cfg.BatchSize {
+			return nil
+		}
+		now = time.Now()
+	}
+}
+
+// publishBatch берет пачку на outbox.publish_timeout и публикует ее, пока не истечет это время: потом
+// сообщения может взять другой экземпляр. Неопубликованные к этому моменту останутся до следующего раза
+func (r *Relay) publishBatch(ctx context.Context, now time.Time) (int, error) {
+	messages, err := r.store.ClaimOutbox(ctx, now, now.Add(r.cfg.PublishTimeout), r.cfg.BatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "store.ClaimOutbox failed: ")
+	}
+	publishCtx, cancel := context.WithTimeout(ctx, r.cfg.PublishTimeout)
+	defer cancel()
+	for _, message := range messages {
+		if publishCtx.Err() != nil {
+			return 0, errors.Wrap(publishCtx.Err(), "outbox batch is not published in time: ")
+		}
+		if err = r.publish(ctx, publishCtx, message); err != nil {
+			return 0, err
+		}
+	}
+	return len(messages), nil
+}
+
+// publish публикует одно сообщение в publishCtx и записывает результат в ctx, ошибка - только если результат не записался
+func (r *Relay) publish(ctx, publishCtx context.Context, message *types.OutboxMessage) error {
+	err := r.publisher.Publish(publishCtx, message)
+	if err == nil {
+		if err = r.store.MarkOutboxPublished(ctx, message.ID, time.Now()); err != nil {
+			return errors.Wrap(err, "store.MarkOutboxPublished failed: ")
+		}
+		return nil
+	}
+	r.logger.Error("publisher.Publish failed: ", zap.String("event_id", message.EventID), zap.Int("attempts", message.Attempts), zap.Error(err))
+	nextAttemptAt := time.Now().Add(backoff.Exponential(message.Attempts, r.cfg.RetryBackoff, r.cfg.MaxBackoff))
+	if err = r.store.RecordOutboxFailure(ctx, message.ID, err.Error(), nextAttemptAt); err != nil {
+		return errors.Wrap(err, "store.RecordOutboxFailure failed: ")
+	}
+	return nil
+}
+
+// Cleanup удаляет сообщения, опубликованные больше outbox.retention назад
+func (r *Relay) Cleanup(ctx context.Context) error {
+	_, err := r.store.DeletePublishedOutbox(ctx, time.Now().Add(-r.cfg.Retention))
+	if err != nil {
+		return errors.Wrap(err, "store.DeletePublishedOutbox failed: ")
+	}
+	return nil
+}
+
+// Close закрывает соединения публикаторов
+func (r *Relay) Close() error {
+	return r.publisher.Close()
+}
@@ -0,0 +1,84 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (r *HttpRouter) RevokeCompletion(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	taskId, err := paramInt(ctx, "taskId")
+	if err != nil {
+		return err
+	}
+	request, err := bind[types.RevokeRequest](ctx)
+	if err != nil {
+		return err
+	}
+	clawback, err := r.controller.RevokeCompletion(ctx.Context(), userId, taskId, request.Reason)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusOK)
+	return ctx.JSON(clawback)
+}
+
+func (r *HttpRouter) RevokeReferral(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	request, err := bind[types.RevokeRequest](ctx)
+	if err != nil {
+		return err
+	}
+	clawbacks, err := r.controller.RevokeReferral(ctx.Context(), userId, request.Reason)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusOK)
+	return ctx.JSON(clawbacks)
+}
+
+// GetClawbacks отдает админу отзывы начислений, ?user_id= фильтрует по пользователю
+func (r *HttpRouter) GetClawbacks(ctx *fiber.Ctx) error {
+	userId, err := queryInt(ctx, "user_id", 0)
+	if err != nil {
+		return err
+	}
+	clawbacks, err := r.controller.GetClawbacks(ctx.Context(), userId)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(clawbacks)
+}
+
+func (r *HttpRouter) GetNotifications(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	notifications, err := r.controller.GetNotifications(ctx.Context(), userId)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(notifications)
+}
+
+func (r *HttpRouter) MarkNotificationsRead(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	err = r.controller.MarkNotificationsRead(ctx.Context(), userId)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusOK)
+	return nil
+}
@@ -0,0 +1,108 @@
+package router
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/SakuraBurst/denet/internal/referrer/apierrors"
+	"github.com/SakuraBurst/denet/internal/referrer/database"
+	"github.com/go-faster/errors"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// APIError ошибка, которую видит клиент. Обработчики возвращают ее или ошибки database и service,
+// а ответ из нее пишет errorHandler
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	// Details дополнительные данные для клиента, например нарушенные ограничения
+	Details any
+	// RetryAfter через сколько секунд можно повторить запрос, 0 - заголовок Retry-After не ставится
+	RetryAfter int
+}
+
+func (e *APIError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+func newAPIError(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+// errorResponse тело ответа с ошибкой
+type errorResponse struct {
+	Status  string `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+var errMalformedBody = newAPIError(http.StatusBadRequest, apierrors.CodeMalformedBody, "Тело запроса не разбирается")
+var errInternal = newAPIError(http.StatusInternalServerError, apierrors.CodeInternal, apierrors.InternalMessage)
+
+// invalidParameter ошибка неправильного параметра пути или query
+func invalidParameter(name string) *APIError {
+	return newAPIError(http.StatusBadRequest, apierrors.CodeInvalidParameter, "Неправильный параметр "+name)
+}
+
+// validationFailed ошибка содержимого запроса, который разобрался, но не подходит
+func validationFailed(message string) *APIError {
+	return newAPIError(http.StatusUnprocessableEntity, apierrors.CodeValidationFailed, message)
+}
+
+// fiberErrorCodes коды для ошибок самого fiber и middleware, которые возвращают *fiber.Error
+var fiberErrorCodes = map[int]string{
+	http.StatusBadRequest:          apierrors.CodeMalformedBody,
+	http.StatusUnauthorized:        apierrors.CodeUnauthorized,
+	http.StatusForbidden:           apierrors.CodeForbidden,
+	http.StatusNotFound:            apierrors.CodeNotFound,
+	http.StatusMethodNotAllowed:    apierrors.CodeMethodNotAllowed,
+	http.StatusUnprocessableEntity: apierrors.CodeValidationFailed,
+}
+
+// toAPIError переводит ошибку обработчика в ошибку API, неизвестные ошибки становятся INTERNAL_ERROR
+func toAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	var limitErr *database.LimitExceededError
+	if errors.As(err, &limitErr) {
+		retryAfter := max(int(math.Ceil(limitErr.RetryAfter.Seconds())), 1)
+		return &APIError{
+			Status:     http.StatusTooManyRequests,
+			Code:       apierrors.CodeEarningLimitExceeded,
+			Message:    apierrors.LimitMessages[limitErr.Limit],
+			Details:    fiber.Map{"limit": limitErr.Limit, "retry_after": retryAfter},
+			RetryAfter: retryAfter,
+		}
+	}
+	if known, ok := apierrors.Lookup(err); ok {
+		return newAPIError(known.Status, known.Code, known.Message)
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		code, ok := fiberErrorCodes[fiberErr.Code]
+		if !ok {
+			return errInternal
+		}
+		return newAPIError(fiberErr.Code, code, fiberErr.Message)
+	}
+	return errInternal
+}
+
+// errorHandler единственное место, где ошибки превращаются в ответы. Пишет в лог только ошибки сервера
+func (r *HttpRouter) errorHandler(ctx *fiber.Ctx, err error) error {
+	apiErr := toAPIError(err)
+	if apiErr.Status >= http.StatusInternalServerError {
+		r.appLogger.Error(ctx.Method()+" "+ctx.Path()+" failed: ", zap.Error(err))
+	}
+	if apiErr.RetryAfter > 0 {
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(apiErr.RetryAfter))
+	}
+	ctx.Status(apiErr.Status)
+	return ctx.JSON(errorResponse{Status: "error", Code: apiErr.Code, Message: apiErr.Message, Details: apiErr.Details})
+}
@@ -0,0 +1,113 @@
+package router
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/apierrors"
+	"github.com/SakuraBurst/denet/internal/referrer/router/middleware"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// ключ Locals, через который id пользователя попадает в WebSocket соединение
+const eventsUserIDLocal = "eventsUserID"
+
+var errUpgradeRequired = newAPIError(http.StatusUpgradeRequired, apierrors.CodeUpgradeRequired, "Нужен запрос на WebSocket соединение")
+
+// StreamEvents отдает события пользователя из токена как Server-Sent Events: "event: <kind>" и "data: <Event в JSON>".
+// Поток заканчивается, если клиент не успевает разбирать события, тогда нужно переподключиться и перечитать статус
+func (r *HttpRouter) StreamEvents(ctx *fiber.Ctx) error {
+	subscription := r.controller.Subscribe(middleware.UserID(ctx))
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	// nginx иначе копит ответ в буфере
+	ctx.Set("X-Accel-Buffering", "no")
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer subscription.Close()
+		heartbeat := time.NewTicker(r.heartbeat)
+		defer heartbeat.Stop()
+		// комментарий сразу отправляет заголовки, клиент видит, что подписка открыта
+		w.WriteString(": connected\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+		for {
+			select {
+			case event, ok := <-subscription.Events():
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(event)
+				if err != nil {
+					r.appLogger.Error("json.Marshal failed: ", zap.Error(err))
+					continue
+				}
+				w.WriteString("event: " + event.Kind + "\ndata: ")
+				w.Write(data)
+				w.WriteString("\n\n")
+			case <-heartbeat.C:
+				// закрытое клиентом соединение замечается только на записи
+				w.WriteString(": ping\n\n")
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// upgradeEvents пропускает к SocketEvents только запросы на WebSocket соединение
+func (r *HttpRouter) upgradeEvents(ctx *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(ctx) {
+		return errUpgradeRequired
+	}
+	ctx.Locals(eventsUserIDLocal, middleware.UserID(ctx))
+	return ctx.Next()
+}
+
+// SocketEvents отдает события пользователя из токена по WebSocket, каждое сообщение - Event в JSON.
+// Сообщения клиента не читаются, кроме служебных
+func (r *HttpRouter) SocketEvents(conn *websocket.Conn) {
+	userID, _ := conn.Locals(eventsUserIDLocal).(int)
+	subscription := r.controller.Subscribe(userID)
+	defer subscription.Close()
+
+	// чтение нужно, чтобы отвечать на ping и заметить, что клиент закрыл соединение
+	disconnected := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(r.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case event, ok := <-subscription.Events():
+			if !ok {
+				message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+				conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(r.heartbeat)); err != nil {
+				return
+			}
+		case <-disconnected:
+			return
+		}
+	}
+}
@@ -4,12 +4,17 @@ import (
 	"context"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/SakuraBurst/denet/internal/referrer/apierrors"
 	"github.com/SakuraBurst/denet/internal/referrer/config"
 	"github.com/SakuraBurst/denet/internal/referrer/database"
+	"github.com/SakuraBurst/denet/internal/referrer/events"
+	"github.com/SakuraBurst/denet/internal/referrer/graph"
 	"github.com/SakuraBurst/denet/internal/referrer/router/middleware"
 	"github.com/SakuraBurst/denet/internal/referrer/types"
 	"github.com/go-faster/errors"
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"go.uber.org/zap"
@@ -20,13 +25,61 @@ type controller interface {
 	CreateNewUser(ctx context.Context, user *types.UserRequest) error
 	AuthorizeUser(ctx context.Context, user *types.UserRequest) (string, error)
 	GetUserStatus(ctx context.Context, id int) (*types.FullUser, error)
-	CompleteTask(ctx context.Context, userID int, taskID int) (int, error)
+	CompleteTask(ctx context.Context, userID int, taskID int) (*types.Balance, error)
 	Referrer(ctx context.Context, id int, referrerCode string) error
 	CreateNewTask(ctx context.Context, task *types.Task) (int, error)
 	GetTask(ctx context.Context, id int) (*types.Task, error)
-	UpdateTaskReward(ctx context.Context, id, newReward int) error
+	UpdateTaskReward(ctx context.Context, id int, newReward types.Amount) error
 	GetAllTasks(ctx context.Context) ([]*types.Task, error)
-	GetTopUsers(ctx context.Context) ([]*types.User, error)
+	CreateSeason(ctx context.Context, season *types.Season) (int, error)
+	GetSeasons(ctx context.Context) ([]*types.Season, error)
+	GetSeasonLeaderBoard(ctx context.Context, seasonID, limit, offset int) (*types.SeasonLeaderBoard, error)
+	CloseSeason(ctx context.Context, seasonID int) error
+	CreateShopItem(ctx context.Context, item *types.ShopItem) (int, error)
+	UpdateShopItem(ctx context.Context, item *types.ShopItem) error
+	GetShopItems(ctx context.Context, onlyActive bool) ([]*types.ShopItem, error)
+	Redeem(ctx context.Context, userID int, request *types.RedeemRequest) (*types.Order, error)
+	GetOrders(ctx context.Context, userID int, status string) ([]*types.Order, error)
+	FulfilOrder(ctx context.Context, orderID int) error
+	CancelOrder(ctx context.Context, orderID int) error
+	Transfer(ctx context.Context, fromUserID int, request *types.TransferRequest, idempotencyKey string) (*types.Transfer, error)
+	GetTransfers(ctx context.Context, userID int) ([]*types.Transfer, error)
+	StartIdempotentRequest(ctx context.Context, scope, key, fingerprint string) (*types.IdempotentResponse, error)
+	FinishIdempotentRequest(ctx context.Context, scope, key string, response *types.IdempotentResponse) error
+	ReleaseIdempotentRequest(ctx context.Context, scope, key string) error
+	RevokeCompletion(ctx context.Context, userID, taskID int, reason string) (*types.Clawback, error)
+	RevokeReferral(ctx context.Context, refereeID int, reason string) ([]*types.Clawback, error)
+	GetClawbacks(ctx context.Context, userID int) ([]*types.Clawback, error)
+	GetNotifications(ctx context.Context, userID int) ([]*types.Notification, error)
+	GetAchievements(ctx context.Context) ([]*types.Achievement, error)
+	MarkNotificationsRead(ctx context.Context, userID int) error
+	GetTopUsers(ctx context.Context, limit, offset int) (*types.LeaderBoard, error)
+	GetUserRank(ctx context.Context, id, neighbors int) (*types.UserRank, error)
+	GetNetworkLeaderBoard(ctx context.Context, id int) (*types.NetworkLeaderBoard, error)
+	GetPeriodLeaderBoard(ctx context.Context, period types.Period, at, from, to time.Time, limit, offset int) (*types.PeriodLeaderBoard, error)
+	CreateTeam(ctx context.Context, userID int, request *types.CreateTeamRequest) (int, error)
+	JoinTeam(ctx context.Context, userID, teamID int, inviteCode string) (int, error)
+	LeaveTeam(ctx context.Context, userID int) error
+	GetUserTeam(ctx context.Context, userID int) (*types.Team, error)
+	GetTeamLeaderBoard(ctx context.Context, limit, offset int) (*types.TeamLeaderBoard, error)
+	CreateTeamGoal(ctx context.Context, goal *types.TeamGoal) (int, error)
+	CreateRaffle(ctx context.Context, raffle *types.Raffle) (int, error)
+	GetRaffles(ctx context.Context) ([]*types.Raffle, error)
+	GetRaffle(ctx context.Context, raffleID int) (*types.Raffle, error)
+	GetRaffleTickets(ctx context.Context, raffleID int) ([]*types.RaffleTicket, error)
+	BuyRaffleTickets(ctx context.Context, userID, raffleID int, request *types.BuyRaffleTicketsRequest) (*types.RaffleTickets, error)
+	GetUsersByIDs(ctx context.Context, userIDs []int) ([]*types.User, error)
+	GetRefereesByUserIDs(ctx context.Context, userIDs []int) ([]*types.User, error)
+	GetUserRanks(ctx context.Context, userIDs []int) ([]*types.RankedUser, error)
+	GetCompletionsByUserIDs(ctx context.Context, userIDs []int) ([]*types.Completion, error)
+	GetTasksByIDs(ctx context.Context, taskIDs []int) ([]*types.Task, error)
+	Subscribe(userID int) *events.Subscription
+	CreateWebhook(ctx context.Context, webhook *types.WebhookRequest) (int, error)
+	UpdateWebhook(ctx context.Context, id int, webhook *types.WebhookRequest) error
+	GetWebhooks(ctx context.Context) ([]*types.Webhook, error)
+	GetWebhookDeliveries(ctx context.Context, webhookID int, status string, limit, offset int) ([]*types.WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, id int) (*types.WebhookDelivery, error)
+	ReplayWebhookDelivery(ctx context.Context, id int) (int, error)
 	Close() error
 }
 
@@ -35,11 +88,14 @@ type HttpRouter struct {
 	*fiber.App
 	appLogger *zap.Logger
 	httpPort  string
+	location  *time.Location
+	graph     *graph.Executor
+	// heartbeat как часто слать пустое сообщение в открытые WebSocket и SSE соединения
+	heartbeat time.Duration
+	// openAPI спецификация API, собирается в CreateRouter после регистрации маршрутов
+	openAPI []byte
 }
 
-const internalServerErrorMessage = "Произошла ошибка на сервере"
-const badRequestMessage = "Неправильный формат данных или в них есть ошибка"
-
 func (r *HttpRouter) Run() error {
 	return r.App.Listen(":" + r.httpPort)
 }
@@ -51,17 +107,9 @@ func (r *HttpRouter) Close() error {
 }
 
 func (r *HttpRouter) Register(ctx *fiber.Ctx) error {
-	request := &types.UserRequest{}
-	err := ctx.BodyParser(request)
+	request, err := bind[types.UserRequest](ctx)
 	if err != nil {
-		r.appLogger.Error("ctx.BodyParser failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
-
-	}
-	if request.UserName == "" || request.Password == "" {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": badRequestMessage})
+		return err
 	}
 	if request.FirstName == "" {
 		request.FirstName = "Михал"
@@ -70,296 +118,395 @@ func (r *HttpRouter) Register(ctx *fiber.Ctx) error {
 		request.LastName = "Палыч"
 	}
 	err = r.controller.CreateNewUser(ctx.Context(), request)
-	if errors.Is(err, database.ErrUserAlreadyExist) {
-		r.appLogger.Error("service.CreateNewUser failed: ", zap.Error(err))
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Пользователь с таким ником уже существует"})
-	}
 	if err != nil {
-		r.appLogger.Error("service.CreateNewUser failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
+		return err
 	}
 	ctx.Status(http.StatusCreated)
 	return nil
 }
 
+var errInvalidCredentials = newAPIError(http.StatusUnauthorized, apierrors.CodeInvalidCredentials, "Неправильный логин или пароль")
+
 func (r *HttpRouter) Login(ctx *fiber.Ctx) error {
-	request := &types.UserRequest{}
-	err := ctx.BodyParser(request)
+	request, err := bind[types.LoginRequest](ctx)
 	if err != nil {
-		r.appLogger.Error("ctx.BodyParser failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
-
+		return err
 	}
-	if request.UserName == "" || request.Password == "" {
-		return fiber.NewError(http.StatusBadRequest, badRequestMessage)
-	}
-	token, err := r.controller.AuthorizeUser(ctx.Context(), request)
-	if errors.Is(err, database.ErrUserNotExist) {
-		r.appLogger.Error("service.AuthorizeUser failed: ", zap.Error(err))
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Неправильный логин или пароль"})
-	}
-	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-		r.appLogger.Error("service.AuthorizeUser failed: ", zap.Error(err))
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Неправильный логин или пароль"})
+	token, err := r.controller.AuthorizeUser(ctx.Context(), &types.UserRequest{UserName: request.UserName, Password: request.Password})
+	if errors.Is(err, database.ErrUserNotExist) || errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return errInvalidCredentials
 	}
 	if err != nil {
-		r.appLogger.Error("service.AuthorizeUser failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
+		return err
 	}
 	ctx.Status(http.StatusOK)
 	return ctx.JSON(fiber.Map{"status": "success", "message": token})
 }
 
 func (r *HttpRouter) GetUserStatus(ctx *fiber.Ctx) error {
-	id := ctx.Params("id")
-	if id == "" {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": badRequestMessage})
-	}
-	userId, err := strconv.Atoi(id)
+	userId, err := paramInt(ctx, "id")
 	if err != nil {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": badRequestMessage})
+		return err
 	}
 	user, err := r.controller.GetUserStatus(ctx.Context(), userId)
-	if errors.Is(err, database.ErrUserNotExist) {
-		r.appLogger.Error("service.GetUserStatus failed: ", zap.Error(err))
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Пользователя с таким id несуществует"})
-	}
 	if err != nil {
-		r.appLogger.Error("service.GetUserStatus failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
+		return err
 	}
 	ctx.Status(http.StatusOK)
 	return ctx.JSON(user)
 }
 
 func (r *HttpRouter) GetLeaderBoard(ctx *fiber.Ctx) error {
-	topUsers, err := r.controller.GetTopUsers(ctx.Context())
+	limit, err := queryInt(ctx, "limit", 0)
 	if err != nil {
-		r.appLogger.Error("service.GetUserStatus failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
+		return err
 	}
-	return ctx.JSON(topUsers)
+	offset, err := queryInt(ctx, "offset", 0)
+	if err != nil {
+		return err
+	}
+	leaderBoard, err := r.controller.GetTopUsers(ctx.Context(), limit, offset)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(leaderBoard)
 }
 
-func (r *HttpRouter) CompleteTask(ctx *fiber.Ctx) error {
-	id := ctx.Params("id")
-	if id == "" {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": badRequestMessage})
+func (r *HttpRouter) GetUserRank(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
 	}
-	userId, err := strconv.Atoi(id)
+	neighbors, err := queryInt(ctx, "neighbors", -1)
 	if err != nil {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": badRequestMessage})
+		return err
 	}
-	taskRequest := &types.CompleteTaskRequest{}
-	err = ctx.BodyParser(taskRequest)
+	rank, err := r.controller.GetUserRank(ctx.Context(), userId, neighbors)
 	if err != nil {
-		r.appLogger.Error("ctx.BodyParser failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
+		return err
+	}
+	return ctx.JSON(rank)
+}
 
+func (r *HttpRouter) GetNetworkLeaderBoard(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
 	}
-	if taskRequest.TaskId == 0 {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Необходим id задания"})
+	leaderBoard, err := r.controller.GetNetworkLeaderBoard(ctx.Context(), userId)
+	if err != nil {
+		return err
 	}
-	reward, err := r.controller.CompleteTask(ctx.Context(), userId, taskRequest.TaskId)
-	if errors.Is(err, database.ErrUserNotExist) {
-		r.appLogger.Error("service.GetUserStatus failed: ", zap.Error(err))
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Пользователя с таким id несуществует"})
+	return ctx.JSON(leaderBoard)
+}
+
+// GetPeriodLeaderBoard отдает таблицу лидеров за период: period=daily|weekly|monthly|all|custom,
+// date - любой день нужного периода (по умолчанию сегодня), from и to - границы [from, to) для custom
+func (r *HttpRouter) GetPeriodLeaderBoard(ctx *fiber.Ctx) error {
+	period := types.Period(ctx.Query("period", string(types.PeriodAllTime)))
+	limit, err := queryInt(ctx, "limit", 0)
+	if err != nil {
+		return err
+	}
+	offset, err := queryInt(ctx, "offset", 0)
+	if err != nil {
+		return err
 	}
-	if errors.Is(err, database.ErrTaskNotExist) {
-		r.appLogger.Error("service.GetUserStatus failed: ", zap.Error(err))
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Задания с таким id несуществует"})
+	at, err := queryTime(ctx, "date", time.Now(), r.location)
+	if err != nil {
+		return err
 	}
-	if errors.Is(err, database.ErrAlreadyCompletedTask) {
-		r.appLogger.Error("service.GetUserStatus failed: ", zap.Error(err))
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Пользователь уже выполнил это задание"})
+	from, err := queryTime(ctx, "from", time.Time{}, r.location)
+	if err != nil {
+		return err
 	}
+	to, err := queryTime(ctx, "to", time.Now(), r.location)
 	if err != nil {
-		r.appLogger.Error("service.GetUserStatus failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
+		return err
 	}
-	ctx.Status(http.StatusOK)
-	return ctx.JSON(fiber.Map{"status": "success", "reward": reward})
+	leaderBoard, err := r.controller.GetPeriodLeaderBoard(ctx.Context(), period, at, from, to, limit, offset)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(leaderBoard)
 }
 
-func (r *HttpRouter) Referrer(ctx *fiber.Ctx) error {
-	id := ctx.Params("id")
-	if id == "" {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": badRequestMessage})
+func (r *HttpRouter) CompleteTask(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	taskRequest, err := bind[types.CompleteTaskRequest](ctx)
+	if err != nil {
+		return err
 	}
-	userId, err := strconv.Atoi(id)
+	balance, err := r.controller.CompleteTask(ctx.Context(), userId, taskRequest.TaskId)
 	if err != nil {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": badRequestMessage})
+		return err
 	}
-	referrerRequest := &types.ReferrerRequest{}
-	err = ctx.BodyParser(referrerRequest)
+	ctx.Status(http.StatusOK)
+	return ctx.JSON(fiber.Map{"status": "success", "reward": balance.Amount, "currency": balance.Currency})
+}
+
+var errReferrerCodeNotFound = newAPIError(http.StatusNotFound, apierrors.CodeReferrerCodeNotFound, "Такого реферального кода не существует")
+
+func (r *HttpRouter) Referrer(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
 	if err != nil {
-		r.appLogger.Error("ctx.BodyParser failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
+		return err
 	}
-	if referrerRequest.ReferrerCode == "" {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Необходим реферальный код"})
+	referrerRequest, err := bind[types.ReferrerRequest](ctx)
+	if err != nil {
+		return err
 	}
 	err = r.controller.Referrer(ctx.Context(), userId, referrerRequest.ReferrerCode)
 	if errors.Is(err, database.ErrUserNotExist) {
-		r.appLogger.Error("service.Referrer failed: ", zap.Error(err))
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Такого реферального кода не существует"})
+		return errReferrerCodeNotFound
 	}
 	if err != nil {
-		r.appLogger.Error("service.GetUserStatus failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
+		return err
 	}
 	ctx.Status(http.StatusOK)
 	return nil
 }
 
 func (r *HttpRouter) CreateTask(ctx *fiber.Ctx) error {
-	request := &types.Task{}
-	err := ctx.BodyParser(request)
+	request, err := bind[types.Task](ctx)
 	if err != nil {
-		r.appLogger.Error("ctx.BodyParser failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
-
-	}
-	if request.Description == "" || request.Reward == 0 {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Заданию необходимо описание и награда"})
+		return err
 	}
-
 	id, err := r.controller.CreateNewTask(ctx.Context(), request)
-	if errors.Is(err, database.ErrTaskAlreadyExist) {
-		r.appLogger.Error("service.CreateNewTask failed: ", zap.Error(err))
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Задание с таким описанием уже существует"})
-	}
 	if err != nil {
-		r.appLogger.Error("service.CreateNewTask failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
+		return err
 	}
 	ctx.Status(http.StatusCreated)
 	return ctx.JSON(fiber.Map{"status": "success", "id": id})
 }
 
 func (r *HttpRouter) UpdateTaskReward(ctx *fiber.Ctx) error {
-	id := ctx.Params("id")
-	if id == "" {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": badRequestMessage})
-	}
-	taskId, err := strconv.Atoi(id)
+	taskId, err := paramInt(ctx, "id")
 	if err != nil {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": badRequestMessage})
+		return err
 	}
-
-	request := &types.Task{}
-	err = ctx.BodyParser(request)
+	request, err := bind[types.UpdateTaskRewardRequest](ctx)
 	if err != nil {
-		r.appLogger.Error("ctx.BodyParser failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
-
-	}
-	if request.Reward == 0 {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Заданию необходимо описание и награда"})
+		return err
 	}
-
 	err = r.controller.UpdateTaskReward(ctx.Context(), taskId, request.Reward)
-	if errors.Is(err, database.ErrTaskNotExist) {
-		r.appLogger.Error("service.UpdateTaskReward failed: ", zap.Error(err))
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Задания с таким id несуществует"})
-	}
 	if err != nil {
-		r.appLogger.Error("service.UpdateTaskReward failed: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
+		return err
 	}
 	ctx.Status(http.StatusOK)
 	return nil
 }
 
+func (r *HttpRouter) GetAchievements(ctx *fiber.Ctx) error {
+	achievements, err := r.controller.GetAchievements(ctx.Context())
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(achievements)
+}
+
 func (r *HttpRouter) GetAllTasks(ctx *fiber.Ctx) error {
 	tasks, err := r.controller.GetAllTasks(ctx.Context())
 	if err != nil {
-		r.appLogger.Error("service.GetAllTasks: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
+		return err
 	}
 	return ctx.JSON(tasks)
 }
 
 func (r *HttpRouter) GetTask(ctx *fiber.Ctx) error {
-	id := ctx.Params("id")
-	if id == "" {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": badRequestMessage})
-	}
-	taskId, err := strconv.Atoi(id)
+	taskId, err := paramInt(ctx, "id")
 	if err != nil {
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": badRequestMessage})
+		return err
 	}
 	task, err := r.controller.GetTask(ctx.Context(), taskId)
-	if errors.Is(err, database.ErrTaskNotExist) {
-		r.appLogger.Error("service.GetTask failed: ", zap.Error(err))
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(fiber.Map{"status": "error", "message": "Задания с таким id несуществует"})
-	}
 	if err != nil {
-		r.appLogger.Error("service.GetTask: ", zap.Error(err))
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(fiber.Map{"status": "error", "message": internalServerErrorMessage})
+		return err
 	}
 	return ctx.JSON(task)
 }
 
-func CreateRouter(c controller, cfg *config.Config, logger *zap.Logger) *HttpRouter {
-	appLogger := logger.Named("app")
-	app := fiber.New()
-	app.Use(recover.New(recover.Config{EnableStackTrace: true}))
-
-	r := &HttpRouter{controller: c, App: app, appLogger: appLogger, httpPort: cfg.HttpPort}
-	api := r.Group("/api/v1")
-	api.Post("/register", r.Register)
-	api.Post("/login", r.Login)
-	users := api.Group("/users", middleware.Protected([]byte(cfg.JWTSecret)))
-	users.Get("/:id/status", r.GetUserStatus)
+// registerRoutes регистрирует маршруты версии API version. Версии делят обработчики и отличаются только
+// путями: в v2 в путях нет глаголов, а маршруты v1, которые там называются по-другому, указывают замену через Successor.
+// Действия с побочными эффектами (закрыть сезон, выдать заказ, отозвать награду) и в v2 остаются POST на /:id/<действие>
+func (r *HttpRouter) registerRoutes(api fiber.Router, version int, cfg *config.Config) {
+	v1 := version == 1
+	protected := middleware.Protected([]byte(cfg.JWTSecret))
+
+	// маршруты раньше групп с тем же префиксом: middleware группы срабатывает на все пути под ним,
+	// а маршрут, объявленный раньше, отвечает до него
+	if v1 {
+		api.Post("/register", middleware.Successor("/api/v2/users"), r.Register)
+		api.Post("/login", middleware.Successor("/api/v2/sessions"), r.Login)
+	} else {
+		api.Post("/users", r.Register)
+		api.Post("/sessions", r.Login)
+	}
+	api.Get("/leaderboard", protected, r.GetPeriodLeaderBoard)
+	api.Get("/achievements", protected, r.GetAchievements)
+
+	users := api.Group("/users", protected)
 	users.Get("/leaderboard", r.GetLeaderBoard)
-	users.Post("/:id/task/complete", r.CompleteTask)
-	users.Post("/:id/referrer", r.Referrer)
+	if v1 {
+		users.Get("/:id/status", middleware.Successor("/api/v2/users/:id"), r.GetUserStatus)
+	} else {
+		users.Get("/:id", r.GetUserStatus)
+	}
+	users.Get("/:id/rank", r.GetUserRank)
+	users.Get("/:id/leaderboard/network", r.GetNetworkLeaderBoard)
+	own := users.Group("/:id", middleware.OwnerOnly())
+	// реферер выбирается один раз и навсегда, выбрать его за другого пользователя нельзя
+	own.Post("/referrer", r.Referrer)
+	if v1 {
+		own.Post("/task/complete", middleware.Successor("/api/v2/users/:id/completions"), r.CompleteTask)
+		own.Post("/redeem", middleware.Successor("/api/v2/users/:id/orders"), r.Redeem)
+		// замена - /teams/:id/members, id команды в запросе нет
+		own.Post("/team/join", middleware.Successor(""), r.JoinTeam)
+		own.Post("/team/leave", middleware.Successor("/api/v2/users/:id/team"), r.LeaveTeam)
+	} else {
+		own.Post("/completions", r.CompleteTask)
+		own.Post("/orders", r.Redeem)
+		own.Delete("/team", r.LeaveTeam)
+	}
+	own.Get("/orders", r.GetUserOrders)
+	own.Post("/transfers", r.CreateTransfer)
+	own.Get("/transfers", r.GetTransfers)
+	own.Post("/raffles/:raffleId/tickets", r.BuyRaffleTickets)
+	own.Get("/notifications", r.GetNotifications)
+	own.Post("/notifications/read", r.MarkNotificationsRead)
+	own.Get("/team", r.GetUserTeam)
+	own.Post("/team", r.CreateTeam)
 
-	tasks := api.Group("/tasks", middleware.Protected([]byte(cfg.JWTSecret)))
-	tasks.Get("/all", r.GetAllTasks)
+	stream := api.Group("/events", middleware.ProtectedStream([]byte(cfg.JWTSecret)))
+	stream.Get("/", r.StreamEvents)
+	stream.Get("/ws", r.upgradeEvents, websocket.New(r.SocketEvents))
+
+	tasks := api.Group("/tasks", protected)
+	if v1 {
+		tasks.Get("/all", middleware.Successor("/api/v2/tasks"), r.GetAllTasks)
+		tasks.Post("/create", middleware.Successor("/api/v2/tasks"), r.CreateTask)
+		tasks.Post("/:id/updateReward", middleware.Successor("/api/v2/tasks/:id"), r.UpdateTaskReward)
+	} else {
+		tasks.Get("/", r.GetAllTasks)
+		tasks.Post("/", r.CreateTask)
+		tasks.Patch("/:id", r.UpdateTaskReward)
+	}
 	tasks.Get("/:id", r.GetTask)
-	tasks.Post("/create", r.CreateTask)
-	tasks.Post("/:id/updateReward", r.UpdateTaskReward)
+
+	shop := api.Group("/shop", protected)
+	shop.Get("/items", r.GetShopItems)
+	shop.Post("/items", middleware.AdminOnly(), r.CreateShopItem)
+	if v1 {
+		shop.Post("/items/:id/update", middleware.AdminOnly(), middleware.Successor("/api/v2/shop/items/:id"), r.UpdateShopItem)
+	} else {
+		shop.Patch("/items/:id", middleware.AdminOnly(), r.UpdateShopItem)
+	}
+	shop.Get("/orders", middleware.AdminOnly(), r.GetOrders)
+	shop.Post("/orders/:id/fulfil", middleware.AdminOnly(), r.FulfilOrder)
+	shop.Post("/orders/:id/cancel", middleware.AdminOnly(), r.CancelOrder)
+
+	admin := api.Group("/admin", protected, middleware.AdminOnly())
+	admin.Get("/clawbacks", r.GetClawbacks)
+	admin.Post("/users/:id/completions/:taskId/revoke", r.RevokeCompletion)
+	admin.Post("/users/:id/referral/revoke", r.RevokeReferral)
+	admin.Get("/webhooks", r.GetWebhooks)
+	admin.Post("/webhooks", r.CreateWebhook)
+	if v1 {
+		admin.Post("/webhooks/:id/update", middleware.Successor("/api/v2/admin/webhooks/:id"), r.UpdateWebhook)
+	} else {
+		admin.Patch("/webhooks/:id", r.UpdateWebhook)
+	}
+	admin.Get("/webhooks/:id/deliveries", r.GetWebhookDeliveries)
+	admin.Get("/webhooks/deliveries/:id", r.GetWebhookDelivery)
+	admin.Post("/webhooks/deliveries/:id/replay", r.ReplayWebhookDelivery)
+
+	teams := api.Group("/teams", protected)
+	teams.Get("/leaderboard", r.GetTeamLeaderBoard)
+	if !v1 {
+		teams.Post("/:id/members", r.AddTeamMember)
+	}
+	teams.Post("/:id/goals", middleware.AdminOnly(), r.CreateTeamGoal)
+
+	raffles := api.Group("/raffles", protected)
+	raffles.Get("/", r.GetRaffles)
+	raffles.Get("/:id", r.GetRaffle)
+	raffles.Get("/:id/tickets", r.GetRaffleTickets)
+	raffles.Post("/", middleware.AdminOnly(), r.CreateRaffle)
+
+	seasons := api.Group("/seasons", protected)
+	seasons.Get("/", r.GetSeasons)
+	seasons.Get("/:id/leaderboard", r.GetSeasonLeaderBoard)
+	seasons.Post("/", middleware.AdminOnly(), r.CreateSeason)
+	seasons.Post("/:id/close", middleware.AdminOnly(), r.CloseSeason)
+}
+
+// paramInt достает из пути целочисленный параметр key
+func paramInt(ctx *fiber.Ctx, key string) (int, error) {
+	value, err := strconv.Atoi(ctx.Params(key))
+	if err != nil {
+		return 0, invalidParameter(key)
+	}
+	return value, nil
+}
+
+// queryInt достает из query параметр key, если его нет возвращает def
+func queryInt(ctx *fiber.Ctx, key string, def int) (int, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return def, nil
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, invalidParameter(key)
+	}
+	return result, nil
+}
+
+// queryTime достает из query дату (2006-01-02) в часовом поясе loc или время в RFC3339, если его нет возвращает def
+func queryTime(ctx *fiber.Ctx, key string, def time.Time, loc *time.Location) (time.Time, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return def, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, loc); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, invalidParameter(key)
+	}
+	return t, nil
+}
+
+func CreateRouter(c controller, cfg *config.Config, logger *zap.Logger) *HttpRouter {
+	appLogger := logger.Named("app")
+	r := &HttpRouter{controller: c, appLogger: appLogger, httpPort: cfg.HttpPort, location: cfg.LeaderBoard.Location, graph: graph.NewExecutor(c, cfg.GraphQL), heartbeat: cfg.Events.Heartbeat}
+	r.App = fiber.New(fiber.Config{ErrorHandler: r.errorHandler})
+	r.Use(recover.New(recover.Config{EnableStackTrace: true}))
+
+	// спецификация описывает обе версии, поэтому не входит ни в одну и не устаревает вместе с v1
+	r.Get(openAPIPath, r.GetOpenAPI)
+	r.Get(docsPath, r.GetDocs)
+
+	// токен нужен Idempotency, чтобы разделять ключи по пользователю, проверяет его Protected на маршрутах
+	identify := middleware.Identify([]byte(cfg.JWTSecret))
+	// v1 устарел целиком: Link в его ответах ведет на тот же путь в v2 или на маршрут, который его заменил
+	v1 := r.Group("/api/v1", middleware.Deprecated(cfg.Versioning.DeprecatedAt, cfg.Versioning.SunsetAt, "/api/v1", "/api/v2"), identify, middleware.Idempotency(c, appLogger))
+	r.registerRoutes(v1, 1, cfg)
+	v2 := r.Group("/api/v2", identify, middleware.Idempotency(c, appLogger))
+	r.registerRoutes(v2, 2, cfg)
+
+	r.Post("/api/graphql", middleware.Protected([]byte(cfg.JWTSecret)), r.GraphQL)
+
+	spec, undocumented, stale := buildOpenAPI(r.GetRoutes(true))
+	r.openAPI = spec
+	for _, route := range undocumented {
+		appLogger.Error("route is missing from OpenAPI operations: " + route)
+	}
+	for _, route := range stale {
+		appLogger.Error("OpenAPI operation has no registered route: " + route)
+	}
 	return r
 }
@@ -0,0 +1,32 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/SakuraBurst/denet/internal/referrer/graph"
+	"github.com/SakuraBurst/denet/internal/referrer/router/middleware"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// GraphQL выполняет запрос GraphQL. Ответ всегда 200 в формате GraphQL, ошибки резолверов
+// получают те же code и message, что и в REST API
+func (r *HttpRouter) GraphQL(ctx *fiber.Ctx) error {
+	request, err := bind[graph.Request](ctx)
+	if err != nil {
+		return err
+	}
+	response := r.graph.Execute(ctx.Context(), middleware.UserID(ctx), request)
+	for _, queryErr := range response.Errors {
+		if queryErr.ResolverError == nil {
+			continue
+		}
+		apiErr := toAPIError(queryErr.ResolverError)
+		if apiErr.Status >= http.StatusInternalServerError {
+			r.appLogger.Error("graphql resolver failed: ", zap.Error(queryErr.ResolverError))
+		}
+		queryErr.Message = apiErr.Message
+		queryErr.Extensions = map[string]any{"code": apiErr.Code}
+	}
+	return ctx.JSON(response)
+}
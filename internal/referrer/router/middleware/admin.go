@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AdminOnly пропускает только пользователей с ролью admin, должен стоять после Protected
+func AdminOnly() func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		if Role(c) != types.RoleAdmin {
+			return fiber.NewError(fiber.StatusForbidden, "Недостаточно прав")
+		}
+		return c.Next()
+	}
+}
+
+// OwnerOnly пропускает только запросы пользователя к самому себе: параметр пути id должен совпадать с id
+// из токена. Должен стоять после Protected
+func OwnerOnly() func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.Atoi(c.Params("id"))
+		if err != nil || id != UserID(c) {
+			return fiber.NewError(fiber.StatusForbidden, "Недостаточно прав")
+		}
+		return c.Next()
+	}
+}
+
+// UserID достает id пользователя из jwt токена, положенного Protected, 0 - токена нет
+func UserID(c *fiber.Ctx) int {
+	claims, ok := tokenClaims(c)
+	if !ok {
+		return 0
+	}
+	id, _ := claims["id"].(float64)
+	return int(id)
+}
+
+// Role достает роль пользователя из jwt токена, положенного Protected
+func Role(c *fiber.Ctx) string {
+	claims, ok := tokenClaims(c)
+	if !ok {
+		return ""
+	}
+	role, _ := claims["role"].(string)
+	return role
+}
+
+func tokenClaims(c *fiber.Ctx) (jwt.MapClaims, bool) {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	return claims, ok
+}
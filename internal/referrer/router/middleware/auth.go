@@ -12,9 +12,28 @@ func Protected(jwtSecret []byte) func(*fiber.Ctx) error {
 	})
 }
 
-func jwtError(c *fiber.Ctx, _ error) error {
+// Identify кладет токен в контекст, как Protected, но запрос без токена или с невалидным токеном пропускает
+// дальше без пользователя: отклонять его - дело Protected на маршруте
+func Identify(jwtSecret []byte) func(*fiber.Ctx) error {
+	return jwtware.New(jwtware.Config{
+		SigningKey: jwtware.SigningKey{Key: jwtSecret},
+		ErrorHandler: func(c *fiber.Ctx, _ error) error {
+			return c.Next()
+		},
+	})
+}
 
-	c.Status(fiber.StatusUnauthorized)
-	return c.JSON(fiber.Map{"status": "error", "message": "Необходима авторизация"})
+// ProtectedStream как Protected, но принимает токен еще и из параметра access_token:
+// браузерные EventSource и WebSocket не умеют ставить заголовки
+func ProtectedStream(jwtSecret []byte) func(*fiber.Ctx) error {
+	return jwtware.New(jwtware.Config{
+		SigningKey:   jwtware.SigningKey{Key: jwtSecret},
+		ErrorHandler: jwtError,
+		TokenLookup:  "header:Authorization,query:access_token",
+		AuthScheme:   "Bearer",
+	})
+}
 
+func jwtError(_ *fiber.Ctx, _ error) error {
+	return fiber.NewError(fiber.StatusUnauthorized, "Необходима авторизация")
 }
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Deprecated помечает ответы устаревшей версии API: Deprecation (RFC 9745) - с какого момента она устарела,
+// Sunset (RFC 8594) - когда ее могут убрать, Link - тот же путь в новой версии, путь from в начале меняется на to.
+// Маршрутам, которые в новой версии называются по-другому, Link исправляет Successor
+func Deprecated(deprecatedAt, sunset time.Time, from, to string) fiber.Handler {
+	deprecation := "@" + strconv.FormatInt(deprecatedAt.Unix(), 10)
+	sunsetValue := sunset.UTC().Format(http.TimeFormat)
+	return func(c *fiber.Ctx) error {
+		c.Set("Deprecation", deprecation)
+		c.Set("Sunset", sunsetValue)
+		c.Set(fiber.HeaderLink, successorLink(to+strings.TrimPrefix(c.Path(), from)))
+		return c.Next()
+	}
+}
+
+// Successor заменяет Link, поставленный Deprecated, на successor. Параметры пути в successor (:id)
+// подставляются из запроса, пустой successor убирает Link, если замену нельзя назвать без данных запроса
+func Successor(successor string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if successor == "" {
+			c.Response().Header.Del(fiber.HeaderLink)
+			return c.Next()
+		}
+		link := successor
+		for _, name := range c.Route().Params {
+			link = strings.ReplaceAll(link, ":"+name, c.Params(name))
+		}
+		c.Set(fiber.HeaderLink, successorLink(link))
+		return c.Next()
+	}
+}
+
+func successorLink(path string) string {
+	return "<" + path + `>; rel="successor-version"`
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+const idempotentReplayedHeader = "Idempotent-Replayed"
+
+type IdempotencyStore interface {
+	StartIdempotentRequest(ctx context.Context, scope, key, fingerprint string) (*types.IdempotentResponse, error)
+	FinishIdempotentRequest(ctx context.Context, scope, key string, response *types.IdempotentResponse) error
+	ReleaseIdempotentRequest(ctx context.Context, scope, key string) error
+}
+
+// Idempotency запоминает ответы на изменяющие запросы с заголовком Idempotency-Key и отдает их на повторы.
+// Ключи разделены по пользователю из токена, у запросов без токена - по адресу клиента, методу и пути.
+// Тот же ключ с другим телом запроса отклоняется. Ответы 5xx и 429 не сохраняются, чтобы запрос можно
+// было повторить. Ошибки обработчика сразу превращаются в ответ через ErrorHandler приложения, чтобы
+// сохранить и повторять именно его. Должен стоять после Identify
+func Idempotency(store IdempotencyStore, logger *zap.Logger) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(IdempotencyKeyHeader)
+		if key == "" || c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead || c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+		userID := UserID(c)
+		scope := "user:" + strconv.Itoa(userID)
+		if userID == 0 {
+			scope = hash([]byte("anonymous:" + c.IP() + " " + c.Method() + " " + c.Path()))
+		}
+		fingerprint := hash([]byte(c.Method()+" "+c.OriginalURL()+"\n"), c.Body())
+
+		stored, err := store.StartIdempotentRequest(c.Context(), scope, key, fingerprint)
+		if err != nil {
+			return errors.Wrap(err, "store.StartIdempotentRequest failed: ")
+		}
+		if stored != nil {
+			c.Set(idempotentReplayedHeader, "true")
+			if stored.ContentType != "" {
+				c.Set(fiber.HeaderContentType, stored.ContentType)
+			}
+			c.Status(stored.StatusCode)
+			return c.Send(stored.Body)
+		}
+
+		// ключ освобождается на любом выходе без сохраненного ответа, в том числе при панике обработчика,
+		// иначе он навсегда остался бы "в процессе" и повторы получали бы 409
+		finished := false
+		defer func() {
+			if finished {
+				return
+			}
+			if releaseErr := store.ReleaseIdempotentRequest(c.Context(), scope, key); releaseErr != nil {
+				logger.Error("store.ReleaseIdempotentRequest failed: ", zap.Error(releaseErr))
+			}
+		}()
+
+		if err = c.Next(); err != nil {
+			if err = c.App().Config().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError || status == fiber.StatusTooManyRequests {
+			return nil
+		}
+		response := &types.IdempotentResponse{
+			StatusCode:  status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        append([]byte(nil), c.Response().Body()...),
+		}
+		// действие уже выполнено, даже если ответ не удалось сохранить, ключ не освобождается, чтобы повтор его не задублировал
+		finished = true
+		if err = store.FinishIdempotentRequest(c.Context(), scope, key, response); err != nil {
+			logger.Error("store.FinishIdempotentRequest failed: ", zap.Error(err))
+		}
+		return nil
+	}
+}
+
+func hash(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
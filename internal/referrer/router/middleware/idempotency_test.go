@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// fakeIdempotencyStore считает, сколько ключей сохранено и освобождено, и запоминает области ключей
+type fakeIdempotencyStore struct {
+	finished int
+	released int
+	scopes   []string
+}
+
+func (s *fakeIdempotencyStore) StartIdempotentRequest(ctx context.Context, scope, key, fingerprint string) (*types.IdempotentResponse, error) {
+	s.scopes = append(s.scopes, scope)
+	return nil, nil
+}
+
+func (s *fakeIdempotencyStore) FinishIdempotentRequest(ctx context.Context, scope, key string, response *types.IdempotentResponse) error {
+	s.finished++
+	return nil
+}
+
+func (s *fakeIdempotencyStore) ReleaseIdempotentRequest(ctx context.Context, scope, key string) error {
+	s.released++
+	return nil
+}
+
+func TestIdempotencyReleasesKey(t *testing.T) {
+	cases := []struct {
+		name         string
+		handler      fiber.Handler
+		wantStatus   int
+		wantFinished int
+		wantReleased int
+	}{
+		{
+			name:         "success",
+			handler:      func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusCreated) },
+			wantStatus:   http.StatusCreated,
+			wantFinished: 1,
+		},
+		{
+			name:         "server error",
+			handler:      func(c *fiber.Ctx) error { return fiber.ErrServiceUnavailable },
+			wantStatus:   http.StatusServiceUnavailable,
+			wantReleased: 1,
+		},
+		{
+			name:         "panic",
+			handler:      func(c *fiber.Ctx) error { panic("boom") },
+			wantStatus:   http.StatusInternalServerError,
+			wantReleased: 1,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &fakeIdempotencyStore{}
+			app := fiber.New()
+			app.Use(recover.New())
+			app.Post("/", Idempotency(store, zap.NewNop()), tc.handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set(IdempotencyKeyHeader, "key")
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus {
+				t.Errorf("status: got %d, want %d", resp.StatusCode, tc.wantStatus)
+			}
+			if store.finished != tc.wantFinished || store.released != tc.wantReleased {
+				t.Errorf("finished %d released %d, want %d and %d", store.finished, store.released, tc.wantFinished, tc.wantReleased)
+			}
+		})
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	secret := []byte("secret")
+	token := func(id int, role string) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"id": id, "role": role}).SignedString(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + signed
+	}
+	store := &fakeIdempotencyStore{}
+	app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+	app.Use(Identify(secret), Idempotency(store, zap.NewNop()))
+	app.Post("/*", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusCreated) })
+
+	requests := []struct{ path, ip, authorization string }{
+		{path: "/register", ip: "10.0.0.1"},
+		{path: "/register", ip: "10.0.0.2"},
+		{path: "/transfers", authorization: token(5, "user")},
+		// тот же пользователь после повторного входа
+		{path: "/transfers", authorization: token(5, "admin")},
+		{path: "/transfers", authorization: token(6, "user")},
+	}
+	for _, r := range requests {
+		req := httptest.NewRequest(http.MethodPost, r.path, nil)
+		req.Header.Set(IdempotencyKeyHeader, "key")
+		req.Header.Set(fiber.HeaderXForwardedFor, r.ip)
+		if r.authorization != "" {
+			req.Header.Set(fiber.HeaderAuthorization, r.authorization)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	if len(store.scopes) != len(requests) {
+		t.Fatalf("got %d scopes, want %d", len(store.scopes), len(requests))
+	}
+	if store.scopes[0] == store.scopes[1] {
+		t.Errorf("anonymous clients share scope %q", store.scopes[0])
+	}
+	if store.scopes[2] != store.scopes[3] {
+		t.Errorf("same user got scopes %q and %q", store.scopes[2], store.scopes[3])
+	}
+	if store.scopes[2] == store.scopes[4] {
+		t.Errorf("different users share scope %q", store.scopes[2])
+	}
+}
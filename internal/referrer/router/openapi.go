@@ -0,0 +1,510 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/graph"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/SakuraBurst/denet/internal/referrer/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	openAPIPath = "/api/v1/openapi.json"
+	docsPath    = "/api/v1/docs"
+)
+
+// тела ответов, которые обработчики собирают через fiber.Map, описаны только для спецификации
+type createdResponse struct {
+	Status string `json:"status"`
+	ID     int    `json:"id"`
+}
+
+type tokenResponse struct {
+	Status string `json:"status"`
+	// Message JWT токен для заголовка Authorization: Bearer
+	Message string `json:"message"`
+}
+
+type rewardResponse struct {
+	Status   string       `json:"status"`
+	Reward   types.Amount `json:"reward"`
+	Currency string       `json:"currency"`
+}
+
+type graphResponse struct {
+	Data   map[string]any   `json:"data"`
+	Errors []map[string]any `json:"errors,omitempty"`
+}
+
+// queryParam параметр query, Type - тип схемы OpenAPI, для дат - string с форматом date
+type queryParam struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+var pageParams = []queryParam{
+	{Name: "limit", Type: "integer", Description: "Сколько записей отдать, по умолчанию и максимум задаются в конфиге"},
+	{Name: "offset", Type: "integer", Description: "Сколько записей пропустить"},
+}
+
+// streamParams браузерные EventSource и WebSocket не ставят заголовки, токен можно передать параметром
+var streamParams = []queryParam{{Name: "access_token", Type: "string", Description: "JWT, если нельзя передать заголовок Authorization"}}
+
+// operation описание маршрута для спецификации. Request и Response - значения типов тел запроса и ответа,
+// nil - тела нет. Status - код успешного ответа, 0 - 200
+type operation struct {
+	Summary  string
+	Request  any
+	Response any
+	Status   int
+	Query    []queryParam
+	// Public маршрут доступен без JWT
+	Public bool
+	Admin  bool
+	// Owner маршрут /users/:id, доступный только самому пользователю id
+	Owner bool
+	// Idempotent маршрут требует заголовок Idempotency-Key
+	Idempotent bool
+	// Deprecated маршрут устаревшей версии API, ставится для всех маршрутов v1
+	Deprecated bool
+}
+
+// operations описания всех маршрутов по ключу "МЕТОД путь". Маршрут, которого здесь нет,
+// попадает в спецификацию без схем, а CreateRouter пишет о нем в лог. Маршруты v2 описываются
+// по маршрутам v1, см. init
+var operations = map[string]operation{
+	"GET " + openAPIPath: {Summary: "Эта спецификация", Public: true},
+	"GET " + docsPath:    {Summary: "Документация API в браузере", Public: true},
+
+	"POST /api/v1/register": {Summary: "Регистрация", Request: types.UserRequest{}, Status: http.StatusCreated, Public: true},
+	"POST /api/v1/login":    {Summary: "Вход, возвращает JWT", Request: types.LoginRequest{}, Response: tokenResponse{}, Public: true},
+	"GET /api/v1/leaderboard": {Summary: "Таблица лидеров за период", Response: types.PeriodLeaderBoard{}, Query: append([]queryParam{
+		{Name: "period", Type: "string", Description: "daily, weekly, monthly, all или custom"},
+		{Name: "date", Type: "date", Description: "Любой день нужного периода, по умолчанию сегодня"},
+		{Name: "from", Type: "date", Description: "Начало периода custom"},
+		{Name: "to", Type: "date", Description: "Конец периода custom, не включается"},
+	}, pageParams...)},
+	"GET /api/v1/achievements": {Summary: "Все достижения", Response: []*types.Achievement{}},
+
+	"GET /api/v1/events":    {Summary: "События пользователя из токена как text/event-stream, в data каждого события - Event", Response: types.Event{}, Query: streamParams},
+	"GET /api/v1/events/ws": {Summary: "События пользователя из токена по WebSocket, каждое сообщение - Event", Response: types.Event{}, Status: http.StatusSwitchingProtocols, Query: streamParams},
+
+	"GET /api/v1/users/:id/status":                     {Summary: "Профиль и балансы пользователя", Response: types.FullUser{}},
+	"GET /api/v1/users/leaderboard":                    {Summary: "Общая таблица лидеров", Response: types.LeaderBoard{}, Query: pageParams},
+	"GET /api/v1/users/:id/rank":                       {Summary: "Место пользователя и соседи по таблице", Response: types.UserRank{}, Query: []queryParam{{Name: "neighbors", Type: "integer", Description: "Сколько соседей сверху и снизу"}}},
+	"GET /api/v1/users/:id/leaderboard/network":        {Summary: "Таблица лидеров реферальной сети пользователя", Response: types.NetworkLeaderBoard{}},
+	"POST /api/v1/users/:id/task/complete":             {Summary: "Выполнить задание", Request: types.CompleteTaskRequest{}, Response: rewardResponse{}, Owner: true},
+	"POST /api/v1/users/:id/referrer":                  {Summary: "Ввести реферальный код", Request: types.ReferrerRequest{}, Owner: true},
+	"POST /api/v1/users/:id/redeem":                    {Summary: "Купить товар", Request: types.RedeemRequest{}, Response: types.Order{}, Status: http.StatusCreated, Owner: true},
+	"GET /api/v1/users/:id/orders":                     {Summary: "Заказы пользователя", Response: []*types.Order{}, Query: []queryParam{{Name: "status", Type: "string", Description: "pending, fulfilled или cancelled"}}, Owner: true},
+	"POST /api/v1/users/:id/transfers":                 {Summary: "Перевести баланс другому пользователю", Request: types.TransferRequest{}, Response: types.Transfer{}, Status: http.StatusCreated, Idempotent: true, Owner: true},
+	"GET /api/v1/users/:id/transfers":                  {Summary: "Переводы пользователя", Response: []*types.Transfer{}, Owner: true},
+	"GET /api/v1/users/:id/notifications":              {Summary: "Уведомления пользователя", Response: []*types.Notification{}, Owner: true},
+	"POST /api/v1/users/:id/notifications/read":        {Summary: "Отметить уведомления прочитанными", Owner: true},
+	"GET /api/v1/users/:id/team":                       {Summary: "Команда пользователя", Response: types.Team{}, Owner: true},
+	"POST /api/v1/users/:id/team":                      {Summary: "Создать команду", Request: types.CreateTeamRequest{}, Response: createdResponse{}, Status: http.StatusCreated, Owner: true},
+	"POST /api/v1/users/:id/team/join":                 {Summary: "Вступить в команду по коду приглашения", Request: types.JoinTeamRequest{}, Response: createdResponse{}, Owner: true},
+	"POST /api/v1/users/:id/team/leave":                {Summary: "Выйти из команды", Owner: true},
+	"POST /api/v1/users/:id/raffles/:raffleId/tickets": {Summary: "Купить билеты розыгрыша", Request: types.BuyRaffleTicketsRequest{}, Response: types.RaffleTickets{}, Status: http.StatusCreated, Owner: true},
+
+	"GET /api/v1/tasks/all":                                   {Summary: "Все задания", Response: []*types.Task{}},
+	"GET /api/v1/tasks/:id":                                   {Summary: "Задание", Response: types.Task{}},
+	"POST /api/v1/tasks/create":                               {Summary: "Создать задание", Request: types.Task{}, Response: createdResponse{}, Status: http.StatusCreated},
+	"POST /api/v1/tasks/:id/updateReward":                     {Summary: "Изменить награду за задание", Request: types.UpdateTaskRewardRequest{}},
+	"GET /api/v1/shop/items":                                  {Summary: "Товары магазина", Response: []*types.ShopItem{}},
+	"POST /api/v1/shop/items":                                 {Summary: "Создать товар", Request: types.ShopItem{}, Response: createdResponse{}, Status: http.StatusCreated, Admin: true},
+	"POST /api/v1/shop/items/:id/update":                      {Summary: "Изменить товар", Request: types.ShopItem{}, Admin: true},
+	"GET /api/v1/shop/orders":                                 {Summary: "Все заказы", Response: []*types.Order{}, Query: []queryParam{{Name: "status", Type: "string", Description: "pending, fulfilled или cancelled"}}, Admin: true},
+	"POST /api/v1/shop/orders/:id/fulfil":                     {Summary: "Выдать заказ", Admin: true},
+	"POST /api/v1/shop/orders/:id/cancel":                     {Summary: "Отменить заказ и вернуть баланс", Admin: true},
+	"GET /api/v1/admin/clawbacks":                             {Summary: "Отзывы начислений", Response: []*types.Clawback{}, Query: []queryParam{{Name: "user_id", Type: "integer", Description: "Только отзывы у этого пользователя"}}, Admin: true},
+	"POST /api/v1/admin/users/:id/completions/:taskId/revoke": {Summary: "Отозвать награду за выполненное задание", Request: types.RevokeRequest{}, Response: types.Clawback{}, Admin: true},
+	"POST /api/v1/admin/users/:id/referral/revoke":            {Summary: "Отозвать реферальные награды", Request: types.RevokeRequest{}, Response: []*types.Clawback{}, Admin: true},
+
+	"GET /api/v1/admin/webhooks":                        {Summary: "Подписки на вебхуки", Response: []*types.Webhook{}, Admin: true},
+	"POST /api/v1/admin/webhooks":                       {Summary: "Подписать адрес на события", Request: types.WebhookRequest{}, Response: createdResponse{}, Status: http.StatusCreated, Admin: true},
+	"POST /api/v1/admin/webhooks/:id/update":            {Summary: "Заменить подписку", Request: types.WebhookRequest{}, Admin: true},
+	"GET /api/v1/admin/webhooks/:id/deliveries":         {Summary: "Журнал доставок подписки, новые первыми", Response: []*types.WebhookDelivery{}, Query: append([]queryParam{{Name: "status", Type: "string", Description: "pending, delivered или dead"}}, pageParams...), Admin: true},
+	"GET /api/v1/admin/webhooks/deliveries/:id":         {Summary: "Доставка со всеми попытками", Response: types.WebhookDelivery{}, Admin: true},
+	"POST /api/v1/admin/webhooks/deliveries/:id/replay": {Summary: "Отправить событие доставки еще раз новой доставкой", Response: createdResponse{}, Status: http.StatusCreated, Admin: true},
+
+	"GET /api/v1/teams/leaderboard":       {Summary: "Таблица команд", Response: types.TeamLeaderBoard{}, Query: pageParams},
+	"POST /api/v1/teams/:id/goals":        {Summary: "Создать цель команды", Request: types.TeamGoal{}, Response: createdResponse{}, Status: http.StatusCreated, Admin: true},
+	"GET /api/v1/raffles":                 {Summary: "Розыгрыши", Response: []*types.Raffle{}},
+	"GET /api/v1/raffles/:id":             {Summary: "Розыгрыш с призами и победителями", Response: types.Raffle{}},
+	"GET /api/v1/raffles/:id/tickets":     {Summary: "Проданные билеты розыгрыша", Response: []*types.RaffleTicket{}},
+	"POST /api/v1/raffles":                {Summary: "Создать розыгрыш", Request: types.Raffle{}, Response: createdResponse{}, Status: http.StatusCreated, Admin: true},
+	"GET /api/v1/seasons":                 {Summary: "Сезоны", Response: []*types.Season{}},
+	"GET /api/v1/seasons/:id/leaderboard": {Summary: "Таблица лидеров сезона", Response: types.SeasonLeaderBoard{}, Query: pageParams},
+	"POST /api/v1/seasons":                {Summary: "Создать сезон", Request: types.Season{}, Response: createdResponse{}, Status: http.StatusCreated, Admin: true},
+	"POST /api/v1/seasons/:id/close":      {Summary: "Закрыть сезон и выдать призы", Admin: true},
+
+	"POST /api/graphql": {Summary: "Запрос GraphQL, схема в internal/referrer/graph/schema.graphql", Request: graph.Request{}, Response: graphResponse{}},
+}
+
+// v2Routes маршруты v1, которые в v2 называются по-другому. Остальные в v2 на том же пути
+var v2Routes = map[string]string{
+	"POST /api/v1/register":                  "POST /api/v2/users",
+	"POST /api/v1/login":                     "POST /api/v2/sessions",
+	"GET /api/v1/users/:id/status":           "GET /api/v2/users/:id",
+	"POST /api/v1/users/:id/task/complete":   "POST /api/v2/users/:id/completions",
+	"POST /api/v1/users/:id/redeem":          "POST /api/v2/users/:id/orders",
+	"POST /api/v1/users/:id/team/join":       "POST /api/v2/teams/:id/members",
+	"POST /api/v1/users/:id/team/leave":      "DELETE /api/v2/users/:id/team",
+	"GET /api/v1/tasks/all":                  "GET /api/v2/tasks",
+	"POST /api/v1/tasks/create":              "POST /api/v2/tasks",
+	"POST /api/v1/tasks/:id/updateReward":    "PATCH /api/v2/tasks/:id",
+	"POST /api/v1/shop/items/:id/update":     "PATCH /api/v2/shop/items/:id",
+	"POST /api/v1/admin/webhooks/:id/update": "PATCH /api/v2/admin/webhooks/:id",
+}
+
+// init описывает маршруты v2 так же, как их предшественников в v1, а v1 помечает устаревшим
+func init() {
+	for key, op := range maps.Clone(operations) {
+		if !strings.Contains(key, " /api/v1/") || key == "GET "+openAPIPath || key == "GET "+docsPath {
+			continue
+		}
+		v2Key, ok := v2Routes[key]
+		if !ok {
+			v2Key = strings.Replace(key, " /api/v1/", " /api/v2/", 1)
+		}
+		v2Op := op
+		// в v2 вступление в команду - /teams/:id/members, пользователь берется из токена
+		v2Op.Owner = op.Owner && strings.Contains(v2Key, " /api/v2/users/:id")
+		operations[v2Key] = v2Op
+		op.Deprecated = true
+		operations[key] = op
+	}
+}
+
+var pathParamPattern = regexp.MustCompile(`:(\w+)`)
+
+// routeKey ключ маршрута в operations, завершающий / у корня группы не учитывается
+func routeKey(method, path string) string {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return method + " " + path
+}
+
+// buildOpenAPI собирает спецификацию OpenAPI 3.1 по зарегистрированным маршрутам и operations.
+// Возвращает и маршруты, которых нет в operations, и описания маршрутов, которых больше нет
+func buildOpenAPI(routes []fiber.Route) (spec []byte, undocumented []string, stale []string) {
+	schemas := &schemaBuilder{components: map[string]any{}}
+	schemas.components["Error"] = schemas.schema(reflect.TypeOf(errorResponse{}))
+	paths := map[string]map[string]any{}
+	seen := map[string]bool{}
+	for _, route := range routes {
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		key := routeKey(route.Method, route.Path)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		op, ok := operations[key]
+		if !ok {
+			undocumented = append(undocumented, key)
+		}
+		path := strings.TrimSuffix(pathParamPattern.ReplaceAllString(route.Path, "{$1}"), "/")
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+		paths[path][strings.ToLower(route.Method)] = schemas.operation(route, op)
+	}
+	for key := range operations {
+		if !seen[key] {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(undocumented)
+	sort.Strings(stale)
+
+	spec, err := json.Marshal(map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "Referrer API",
+			"version":     "2.0.0",
+			"description": "Задания, реферальная программа, таблицы лидеров, магазин, команды и розыгрыши. Ошибки отдаются в формате Error, поле code стабильно",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return spec, undocumented, stale
+}
+
+// schemaBuilder выводит схемы JSON из типов по тегам json и validate, именованные структуры
+// складываются в components и подставляются через $ref
+type schemaBuilder struct {
+	components map[string]any
+}
+
+func (b *schemaBuilder) operation(route fiber.Route, op operation) map[string]any {
+	result := map[string]any{
+		"summary": op.Summary,
+		"tags":    []string{routeTag(route.Path)},
+	}
+	var parameters []any
+	for _, match := range pathParamPattern.FindAllStringSubmatch(route.Path, -1) {
+		parameters = append(parameters, map[string]any{
+			"name": match[1], "in": "path", "required": true, "schema": map[string]any{"type": "integer"},
+		})
+	}
+	for _, param := range op.Query {
+		schema := map[string]any{"type": param.Type}
+		if param.Type == "date" {
+			schema = map[string]any{"type": "string", "format": "date"}
+		}
+		parameters = append(parameters, map[string]any{
+			"name": param.Name, "in": "query", "description": param.Description, "schema": schema,
+		})
+	}
+	if op.Idempotent {
+		parameters = append(parameters, map[string]any{
+			"name": "Idempotency-Key", "in": "header", "required": true, "schema": map[string]any{"type": "string"},
+		})
+	}
+	if parameters != nil {
+		result["parameters"] = parameters
+	}
+	if op.Request != nil {
+		result["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{fiber.MIMEApplicationJSON: map[string]any{"schema": b.schema(reflect.TypeOf(op.Request))}},
+		}
+	}
+	if !op.Public {
+		result["security"] = []any{map[string]any{"bearerAuth": []string{}}}
+	}
+	if op.Admin {
+		result["description"] = "Только для администраторов"
+	}
+	if op.Owner {
+		result["description"] = "Только для самого пользователя id, иначе 403"
+	}
+	if op.Deprecated {
+		result["deprecated"] = true
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]any{"description": http.StatusText(status)}
+	if op.Response != nil {
+		success["content"] = map[string]any{fiber.MIMEApplicationJSON: map[string]any{"schema": b.schema(reflect.TypeOf(op.Response))}}
+	}
+	errorContent := map[string]any{fiber.MIMEApplicationJSON: map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}}}
+	result["responses"] = map[string]any{
+		strconv.Itoa(status): success,
+		"default":            map[string]any{"description": "Ошибка", "content": errorContent},
+	}
+	return result
+}
+
+// routeTag группа маршрута в документации - первый сегмент пути после /api/v1, /api/v2 или /api
+func routeTag(path string) string {
+	path = strings.TrimPrefix(path, "/api/")
+	if version, rest, ok := strings.Cut(path, "/"); ok && (version == "v1" || version == "v2") {
+		path = rest
+	}
+	segment, _, _ := strings.Cut(path, "/")
+	return segment
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	amountType = reflect.TypeOf(types.Amount(0))
+)
+
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case amountType:
+		return map[string]any{"type": "string", "format": "decimal", "description": "Сумма с точностью до сотых, принимается и числом", "examples": []string{"12.5"}}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.schema(t.Elem())
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Struct:
+		name := componentName(t)
+		if _, ok := b.components[name]; !ok {
+			// сначала занимаем имя, чтобы не зациклиться на рекурсивных типах
+			b.components[name] = nil
+			b.components[name] = b.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]any{}
+	}
+}
+
+// object схема структуры, поля встроенных структур поднимаются наверх как в encoding/json
+func (b *schemaBuilder) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	b.fields(t, properties, &required)
+	result := map[string]any{"type": "object", "properties": properties}
+	if required != nil {
+		result["required"] = required
+	}
+	return result
+}
+
+func (b *schemaBuilder) fields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			b.fields(field.Type, properties, required)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema := b.schema(field.Type)
+		if _, isRef := schema["$ref"]; !isRef {
+			applyRules(schema, field.Tag.Get("validate"), required, name)
+		}
+		properties[name] = schema
+	}
+}
+
+// applyRules переносит в схему ограничения из тега validate, которые выражаются в JSON Schema
+func applyRules(schema map[string]any, tag string, required *[]string, name string) {
+	if tag == "" {
+		return
+	}
+	isString := schema["type"] == "string" && schema["format"] == nil
+	isArray := schema["type"] == "array"
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		rule, param, _ := strings.Cut(rule, "=")
+		switch rule {
+		case "required":
+			*required = append(*required, name)
+		case "min":
+			if isString {
+				schema["minLength"] = ruleParam(param)
+			} else if isArray {
+				schema["minItems"] = ruleParam(param)
+			} else {
+				schema["minimum"] = ruleParam(param)
+			}
+		case "max":
+			if isString {
+				schema["maxLength"] = ruleParam(param)
+			} else if isArray {
+				schema["maxItems"] = ruleParam(param)
+			} else {
+				schema["maximum"] = ruleParam(param)
+			}
+		case "gt":
+			if schema["type"] == "integer" {
+				schema["exclusiveMinimum"] = ruleParam(param)
+			} else {
+				schema["description"] = strings.TrimSpace(fmt.Sprint(schema["description"], " Больше ", param))
+			}
+		case "uuid":
+			schema["format"] = "uuid"
+		case "http_url":
+			schema["format"] = "uri"
+		case "oneof":
+			schema["enum"] = strings.Fields(param)
+		case "username":
+			schema["pattern"] = validation.UserNamePattern.String()
+		case "alpha":
+			schema["pattern"] = "^[a-zA-Z]+$"
+		case "dive":
+			// остальные правила относятся к элементам списка
+			if items, ok := schema["items"].(map[string]any); ok {
+				var itemRequired []string
+				applyRules(items, strings.Join(rules[i+1:], ","), &itemRequired, name)
+			}
+			return
+		}
+	}
+}
+
+// ruleParam параметр правила validate числом, если он числовой
+func ruleParam(param string) any {
+	if value, err := strconv.Atoi(param); err == nil {
+		return value
+	}
+	return param
+}
+
+// componentName имя схемы в components, неэкспортируемые типы пишутся с большой буквы
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	if name == "" {
+		return "Anonymous"
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// GetOpenAPI отдает спецификацию API
+func (r *HttpRouter) GetOpenAPI(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	return ctx.Send(r.openAPI)
+}
+
+// docsPage страница с Redoc, которая рисует спецификацию из openAPIPath
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Referrer API</title>
+<meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+<redoc spec-url="` + openAPIPath + `"></redoc>
+<script src="https://cdn.redoc.ly/redoc/v2.1.5/bundles/redoc.standalone.js"></script>
+</body>
+</html>`
+
+// GetDocs отдает страницу документации
+func (r *HttpRouter) GetDocs(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return ctx.SendString(docsPage)
+}
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/SakuraBurst/denet/internal/referrer/config"
+	"go.uber.org/zap"
+)
+
+// newTestRouter собирает роутер с конфигом из config/config.yaml, как при запуске
+func newTestRouter(t *testing.T, c controller) *HttpRouter {
+	t.Helper()
+	return CreateRouter(c, config.MustLoadPath("../../../config/config.yaml"), zap.NewNop())
+}
+
+func TestOpenAPIDescribesAllRoutes(t *testing.T) {
+	r := newTestRouter(t, nil)
+	_, undocumented, stale := buildOpenAPI(r.GetRoutes(true))
+	if len(undocumented) > 0 {
+		t.Errorf("routes missing from operations: %v", undocumented)
+	}
+	if len(stale) > 0 {
+		t.Errorf("operations without a registered route: %v", stale)
+	}
+}
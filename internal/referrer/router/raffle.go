@@ -0,0 +1,74 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (r *HttpRouter) CreateRaffle(ctx *fiber.Ctx) error {
+	request, err := bind[types.Raffle](ctx)
+	if err != nil {
+		return err
+	}
+	id, err := r.controller.CreateRaffle(ctx.Context(), request)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusCreated)
+	return ctx.JSON(fiber.Map{"status": "success", "id": id})
+}
+
+func (r *HttpRouter) GetRaffles(ctx *fiber.Ctx) error {
+	raffles, err := r.controller.GetRaffles(ctx.Context())
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(raffles)
+}
+
+func (r *HttpRouter) GetRaffle(ctx *fiber.Ctx) error {
+	raffleId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	raffle, err := r.controller.GetRaffle(ctx.Context(), raffleId)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(raffle)
+}
+
+func (r *HttpRouter) GetRaffleTickets(ctx *fiber.Ctx) error {
+	raffleId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	tickets, err := r.controller.GetRaffleTickets(ctx.Context(), raffleId)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(tickets)
+}
+
+func (r *HttpRouter) BuyRaffleTickets(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	raffleId, err := paramInt(ctx, "raffleId")
+	if err != nil {
+		return err
+	}
+	request, err := bind[types.BuyRaffleTicketsRequest](ctx)
+	if err != nil {
+		return err
+	}
+	tickets, err := r.controller.BuyRaffleTickets(ctx.Context(), userId, raffleId, request)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusCreated)
+	return ctx.JSON(tickets)
+}
@@ -0,0 +1,62 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (r *HttpRouter) CreateSeason(ctx *fiber.Ctx) error {
+	request, err := bind[types.Season](ctx)
+	if err != nil {
+		return err
+	}
+	id, err := r.controller.CreateSeason(ctx.Context(), request)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusCreated)
+	return ctx.JSON(fiber.Map{"status": "success", "id": id})
+}
+
+func (r *HttpRouter) GetSeasons(ctx *fiber.Ctx) error {
+	seasons, err := r.controller.GetSeasons(ctx.Context())
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(seasons)
+}
+
+func (r *HttpRouter) GetSeasonLeaderBoard(ctx *fiber.Ctx) error {
+	seasonId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	limit, err := queryInt(ctx, "limit", 0)
+	if err != nil {
+		return err
+	}
+	offset, err := queryInt(ctx, "offset", 0)
+	if err != nil {
+		return err
+	}
+	leaderBoard, err := r.controller.GetSeasonLeaderBoard(ctx.Context(), seasonId, limit, offset)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(leaderBoard)
+}
+
+func (r *HttpRouter) CloseSeason(ctx *fiber.Ctx) error {
+	seasonId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	err = r.controller.CloseSeason(ctx.Context(), seasonId)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusOK)
+	return nil
+}
@@ -0,0 +1,114 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/SakuraBurst/denet/internal/referrer/router/middleware"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetShopItems отдает товары в продаже, админ с ?all=true видит и снятые с продажи
+func (r *HttpRouter) GetShopItems(ctx *fiber.Ctx) error {
+	onlyActive := !(ctx.QueryBool("all") && middleware.Role(ctx) == types.RoleAdmin)
+	items, err := r.controller.GetShopItems(ctx.Context(), onlyActive)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(items)
+}
+
+func (r *HttpRouter) CreateShopItem(ctx *fiber.Ctx) error {
+	request := &types.ShopItem{Active: true}
+	if err := bindTo(ctx, request); err != nil {
+		return err
+	}
+	id, err := r.controller.CreateShopItem(ctx.Context(), request)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusCreated)
+	return ctx.JSON(fiber.Map{"status": "success", "id": id})
+}
+
+func (r *HttpRouter) UpdateShopItem(ctx *fiber.Ctx) error {
+	itemId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	request := &types.ShopItem{Active: true}
+	if err = bindTo(ctx, request); err != nil {
+		return err
+	}
+	request.ID = itemId
+	err = r.controller.UpdateShopItem(ctx.Context(), request)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusOK)
+	return nil
+}
+
+func (r *HttpRouter) Redeem(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	request, err := bind[types.RedeemRequest](ctx)
+	if err != nil {
+		return err
+	}
+	order, err := r.controller.Redeem(ctx.Context(), userId, request)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusCreated)
+	return ctx.JSON(order)
+}
+
+func (r *HttpRouter) GetUserOrders(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	orders, err := r.controller.GetOrders(ctx.Context(), userId, ctx.Query("status"))
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(orders)
+}
+
+// GetOrders отдает админу заказы всех пользователей, например ?status=pending
+func (r *HttpRouter) GetOrders(ctx *fiber.Ctx) error {
+	orders, err := r.controller.GetOrders(ctx.Context(), 0, ctx.Query("status"))
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(orders)
+}
+
+func (r *HttpRouter) FulfilOrder(ctx *fiber.Ctx) error {
+	orderId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	err = r.controller.FulfilOrder(ctx.Context(), orderId)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusOK)
+	return nil
+}
+
+func (r *HttpRouter) CancelOrder(ctx *fiber.Ctx) error {
+	orderId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	err = r.controller.CancelOrder(ctx.Context(), orderId)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusOK)
+	return nil
+}
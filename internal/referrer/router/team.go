@@ -0,0 +1,127 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/SakuraBurst/denet/internal/referrer/apierrors"
+	"github.com/SakuraBurst/denet/internal/referrer/database"
+	"github.com/SakuraBurst/denet/internal/referrer/router/middleware"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/gofiber/fiber/v2"
+)
+
+var errInviteCodeNotFound = newAPIError(http.StatusNotFound, apierrors.CodeInviteCodeNotFound, "Такого кода приглашения не существует")
+
+func (r *HttpRouter) CreateTeam(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	request, err := bind[types.CreateTeamRequest](ctx)
+	if err != nil {
+		return err
+	}
+	id, err := r.controller.CreateTeam(ctx.Context(), userId, request)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusCreated)
+	return ctx.JSON(fiber.Map{"status": "success", "id": id})
+}
+
+func (r *HttpRouter) JoinTeam(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	request, err := bind[types.JoinTeamRequest](ctx)
+	if err != nil {
+		return err
+	}
+	return r.joinTeam(ctx, userId, 0, request.InviteCode)
+}
+
+// AddTeamMember добавляет в команду id пользователя из токена, код приглашения должен быть от этой команды
+func (r *HttpRouter) AddTeamMember(ctx *fiber.Ctx) error {
+	teamId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	request, err := bind[types.JoinTeamRequest](ctx)
+	if err != nil {
+		return err
+	}
+	return r.joinTeam(ctx, middleware.UserID(ctx), teamId, request.InviteCode)
+}
+
+func (r *HttpRouter) joinTeam(ctx *fiber.Ctx, userId, teamId int, inviteCode string) error {
+	id, err := r.controller.JoinTeam(ctx.Context(), userId, teamId, inviteCode)
+	if errors.Is(err, database.ErrTeamNotExist) {
+		return errInviteCodeNotFound
+	}
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusOK)
+	return ctx.JSON(fiber.Map{"status": "success", "id": id})
+}
+
+func (r *HttpRouter) LeaveTeam(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	err = r.controller.LeaveTeam(ctx.Context(), userId)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusOK)
+	return nil
+}
+
+func (r *HttpRouter) GetUserTeam(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	team, err := r.controller.GetUserTeam(ctx.Context(), userId)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(team)
+}
+
+func (r *HttpRouter) GetTeamLeaderBoard(ctx *fiber.Ctx) error {
+	limit, err := queryInt(ctx, "limit", 0)
+	if err != nil {
+		return err
+	}
+	offset, err := queryInt(ctx, "offset", 0)
+	if err != nil {
+		return err
+	}
+	leaderBoard, err := r.controller.GetTeamLeaderBoard(ctx.Context(), limit, offset)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(leaderBoard)
+}
+
+func (r *HttpRouter) CreateTeamGoal(ctx *fiber.Ctx) error {
+	teamId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	goal, err := bind[types.TeamGoal](ctx)
+	if err != nil {
+		return err
+	}
+	goal.TeamID = teamId
+	id, err := r.controller.CreateTeamGoal(ctx.Context(), goal)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusCreated)
+	return ctx.JSON(fiber.Map{"status": "success", "id": id})
+}
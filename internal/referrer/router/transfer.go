@@ -0,0 +1,43 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/SakuraBurst/denet/internal/referrer/router/middleware"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateTransfer переводит баланс другому пользователю, заголовок Idempotency-Key обязателен
+func (r *HttpRouter) CreateTransfer(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	request, err := bind[types.TransferRequest](ctx)
+	if err != nil {
+		return err
+	}
+	transfer, err := r.controller.Transfer(ctx.Context(), userId, request, ctx.Get(middleware.IdempotencyKeyHeader))
+	if err != nil {
+		return err
+	}
+	if transfer.Replayed {
+		ctx.Status(http.StatusOK)
+	} else {
+		ctx.Status(http.StatusCreated)
+	}
+	return ctx.JSON(transfer)
+}
+
+func (r *HttpRouter) GetTransfers(ctx *fiber.Ctx) error {
+	userId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	transfers, err := r.controller.GetTransfers(ctx.Context(), userId)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(transfers)
+}
@@ -0,0 +1,34 @@
+package router
+
+import (
+	"github.com/SakuraBurst/denet/internal/referrer/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+// bind разбирает тело запроса в новое значение T и проверяет его по тегам validate
+func bind[T any](ctx *fiber.Ctx) (*T, error) {
+	out := new(T)
+	if err := bindTo(ctx, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// bindTo как bind, но в заранее заполненное значение, например с полями по умолчанию
+func bindTo(ctx *fiber.Ctx, out any) error {
+	if err := ctx.BodyParser(out); err != nil {
+		return errMalformedBody
+	}
+	return validateRequest(out)
+}
+
+// validateRequest возвращает все нарушения правил сразу одной ошибкой VALIDATION_FAILED
+func validateRequest(request any) error {
+	violations, err := validation.Struct(request)
+	if err != nil || violations == nil {
+		return err
+	}
+	apiErr := validationFailed("Запрос не прошел проверку")
+	apiErr.Details = violations
+	return apiErr
+}
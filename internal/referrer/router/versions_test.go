@@ -0,0 +1,301 @@
+package router
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SakuraBurst/denet/internal/referrer/config"
+	"github.com/SakuraBurst/denet/internal/referrer/database"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	stubUserID = 5
+	stubTeamID = 3
+)
+
+// stubController отвечает фиксированными данными на методы, которые есть у переименованных маршрутов,
+// остальные методы не реализованы и паникуют через nil интерфейс
+type stubController struct {
+	controller
+}
+
+func (s *stubController) CreateNewUser(ctx context.Context, user *types.UserRequest) error {
+	return nil
+}
+
+func (s *stubController) AuthorizeUser(ctx context.Context, user *types.UserRequest) (string, error) {
+	return "token-" + user.UserName, nil
+}
+
+func (s *stubController) GetUserStatus(ctx context.Context, id int) (*types.FullUser, error) {
+	return &types.FullUser{ID: id, UserName: "user", Balance: types.NewAmount(10)}, nil
+}
+
+func (s *stubController) CompleteTask(ctx context.Context, userID int, taskID int) (*types.Balance, error) {
+	return &types.Balance{Currency: types.CurrencyPoints, Amount: types.NewAmount(int64(taskID))}, nil
+}
+
+func (s *stubController) Referrer(ctx context.Context, id int, referrerCode string) error {
+	return nil
+}
+
+func (s *stubController) Redeem(ctx context.Context, userID int, request *types.RedeemRequest) (*types.Order, error) {
+	return &types.Order{ID: 1, UserID: userID, ItemID: request.ItemID, Quantity: request.Quantity, Status: types.OrderStatusPending}, nil
+}
+
+func (s *stubController) JoinTeam(ctx context.Context, userID, teamID int, inviteCode string) (int, error) {
+	if inviteCode != "code" || teamID != 0 && teamID != stubTeamID {
+		return 0, database.ErrTeamNotExist
+	}
+	return stubTeamID, nil
+}
+
+func (s *stubController) LeaveTeam(ctx context.Context, userID int) error {
+	return nil
+}
+
+func (s *stubController) GetAllTasks(ctx context.Context) ([]*types.Task, error) {
+	return []*types.Task{{ID: 1, Description: "task", Reward: types.NewAmount(5), Currency: types.CurrencyPoints}}, nil
+}
+
+func (s *stubController) CreateNewTask(ctx context.Context, task *types.Task) (int, error) {
+	return 7, nil
+}
+
+func (s *stubController) UpdateTaskReward(ctx context.Context, id int, newReward types.Amount) error {
+	return nil
+}
+
+func (s *stubController) UpdateShopItem(ctx context.Context, item *types.ShopItem) error {
+	return nil
+}
+
+func (s *stubController) UpdateWebhook(ctx context.Context, id int, webhook *types.WebhookRequest) error {
+	return nil
+}
+
+func (s *stubController) GetUserTeam(ctx context.Context, userID int) (*types.Team, error) {
+	return &types.Team{ID: stubTeamID, Name: "team", InviteCode: "code"}, nil
+}
+
+type versionedRequest struct {
+	method string
+	path   string
+}
+
+func TestV1AndV2RoutesMatch(t *testing.T) {
+	cfg := config.MustLoadPath("../../../config/config.yaml")
+	r := newTestRouter(t, &stubController{})
+	token := stubToken(t, cfg.JWTSecret)
+
+	cases := []struct {
+		name string
+		v1   versionedRequest
+		v2   versionedRequest
+		want int
+		body string
+		// Link, который v1 отдаёт как successor-version, пусто - заголовка нет
+		link string
+	}{
+		{
+			name: "register",
+			want: http.StatusCreated,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/register"},
+			v2:   versionedRequest{http.MethodPost, "/api/v2/users"},
+			body: `{"user_name":"newuser","password":"password1"}`,
+			link: "</api/v2/users>; rel=\"successor-version\"",
+		},
+		{
+			name: "login",
+			want: http.StatusOK,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/login"},
+			v2:   versionedRequest{http.MethodPost, "/api/v2/sessions"},
+			body: `{"user_name":"newuser","password":"password1"}`,
+			link: "</api/v2/sessions>; rel=\"successor-version\"",
+		},
+		{
+			name: "status",
+			want: http.StatusOK,
+			v1:   versionedRequest{http.MethodGet, "/api/v1/users/5/status"},
+			v2:   versionedRequest{http.MethodGet, "/api/v2/users/5"},
+			link: "</api/v2/users/5>; rel=\"successor-version\"",
+		},
+		{
+			name: "complete task",
+			want: http.StatusOK,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/users/5/task/complete"},
+			v2:   versionedRequest{http.MethodPost, "/api/v2/users/5/completions"},
+			body: `{"task_id":4}`,
+			link: "</api/v2/users/5/completions>; rel=\"successor-version\"",
+		},
+		{
+			name: "complete task for another user",
+			want: http.StatusForbidden,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/users/6/task/complete"},
+			v2:   versionedRequest{http.MethodPost, "/api/v2/users/6/completions"},
+			body: `{"task_id":4}`,
+			link: "</api/v2/users/6/task/complete>; rel=\"successor-version\"",
+		},
+		{
+			name: "referrer",
+			want: http.StatusOK,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/users/5/referrer"},
+			v2:   versionedRequest{http.MethodPost, "/api/v2/users/5/referrer"},
+			body: `{"referrer_code":"6f1d3c52-2a4e-4f6b-9a51-0c7d1e2b3a4f"}`,
+			link: "</api/v2/users/5/referrer>; rel=\"successor-version\"",
+		},
+		{
+			name: "referrer for another user",
+			want: http.StatusForbidden,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/users/6/referrer"},
+			v2:   versionedRequest{http.MethodPost, "/api/v2/users/6/referrer"},
+			body: `{"referrer_code":"6f1d3c52-2a4e-4f6b-9a51-0c7d1e2b3a4f"}`,
+			link: "</api/v2/users/6/referrer>; rel=\"successor-version\"",
+		},
+		{
+			name: "redeem",
+			want: http.StatusCreated,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/users/5/redeem"},
+			v2:   versionedRequest{http.MethodPost, "/api/v2/users/5/orders"},
+			body: `{"item_id":2,"quantity":1}`,
+			link: "</api/v2/users/5/orders>; rel=\"successor-version\"",
+		},
+		{
+			name: "join team",
+			want: http.StatusOK,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/users/5/team/join"},
+			v2:   versionedRequest{http.MethodPost, "/api/v2/teams/3/members"},
+			body: `{"invite_code":"code"}`,
+		},
+		{
+			name: "join team with unknown code",
+			want: http.StatusNotFound,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/users/5/team/join"},
+			v2:   versionedRequest{http.MethodPost, "/api/v2/teams/3/members"},
+			body: `{"invite_code":"other"}`,
+		},
+		{
+			name: "leave team",
+			want: http.StatusOK,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/users/5/team/leave"},
+			v2:   versionedRequest{http.MethodDelete, "/api/v2/users/5/team"},
+			link: "</api/v2/users/5/team>; rel=\"successor-version\"",
+		},
+		{
+			name: "user team",
+			want: http.StatusOK,
+			v1:   versionedRequest{http.MethodGet, "/api/v1/users/5/team"},
+			v2:   versionedRequest{http.MethodGet, "/api/v2/users/5/team"},
+			link: "</api/v2/users/5/team>; rel=\"successor-version\"",
+		},
+		{
+			name: "all tasks",
+			want: http.StatusOK,
+			v1:   versionedRequest{http.MethodGet, "/api/v1/tasks/all"},
+			v2:   versionedRequest{http.MethodGet, "/api/v2/tasks"},
+			link: "</api/v2/tasks>; rel=\"successor-version\"",
+		},
+		{
+			name: "create task",
+			want: http.StatusCreated,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/tasks/create"},
+			v2:   versionedRequest{http.MethodPost, "/api/v2/tasks"},
+			body: `{"description":"task","reward":"5"}`,
+			link: "</api/v2/tasks>; rel=\"successor-version\"",
+		},
+		{
+			name: "update task reward",
+			want: http.StatusOK,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/tasks/1/updateReward"},
+			v2:   versionedRequest{http.MethodPatch, "/api/v2/tasks/1"},
+			body: `{"reward":"6"}`,
+			link: "</api/v2/tasks/1>; rel=\"successor-version\"",
+		},
+		{
+			name: "update shop item",
+			want: http.StatusOK,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/shop/items/2/update"},
+			v2:   versionedRequest{http.MethodPatch, "/api/v2/shop/items/2"},
+			body: `{"name":"item","price":"3"}`,
+			link: "</api/v2/shop/items/2>; rel=\"successor-version\"",
+		},
+		{
+			name: "update webhook",
+			want: http.StatusOK,
+			v1:   versionedRequest{http.MethodPost, "/api/v1/admin/webhooks/1/update"},
+			v2:   versionedRequest{http.MethodPatch, "/api/v2/admin/webhooks/1"},
+			body: `{"url":"https://example.com/hook","event_types":["task.completed"],"secret":"0123456789abcdef"}`,
+			link: "</api/v2/admin/webhooks/1>; rel=\"successor-version\"",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v1 := doVersioned(t, r, tc.v1, tc.body, token)
+			v2 := doVersioned(t, r, tc.v2, tc.body, token)
+
+			if v1.status != tc.want {
+				t.Errorf("v1 status: got %d, want %d", v1.status, tc.want)
+			}
+			if v1.status != v2.status {
+				t.Errorf("status: v1 %d, v2 %d", v1.status, v2.status)
+			}
+			if v1.body != v2.body {
+				t.Errorf("body: v1 %q, v2 %q", v1.body, v2.body)
+			}
+
+			for _, header := range []string{"Deprecation", "Sunset"} {
+				if v1.header.Get(header) == "" {
+					t.Errorf("v1 missing %s", header)
+				}
+			}
+			if got := v1.header.Get("Link"); got != tc.link {
+				t.Errorf("v1 Link: got %q, want %q", got, tc.link)
+			}
+			for _, header := range []string{"Deprecation", "Sunset", "Link"} {
+				if got := v2.header.Get(header); got != "" {
+					t.Errorf("v2 has %s: %q", header, got)
+				}
+			}
+		})
+	}
+}
+
+type versionedResponse struct {
+	status int
+	body   string
+	header http.Header
+}
+
+func doVersioned(t *testing.T, r *HttpRouter, request versionedRequest, body, token string) versionedResponse {
+	t.Helper()
+	req := httptest.NewRequest(request.method, request.path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := r.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", request.method, request.path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("%s %s: %v", request.method, request.path, err)
+	}
+	return versionedResponse{status: resp.StatusCode, body: string(data), header: resp.Header}
+}
+
+// stubToken токен админа stubUserID, как его выдаёт AuthorizeUser
+func stubToken(t *testing.T, secret string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"id": stubUserID, "role": "admin"}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
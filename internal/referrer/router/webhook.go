@@ -0,0 +1,93 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (r *HttpRouter) CreateWebhook(ctx *fiber.Ctx) error {
+	request := &types.WebhookRequest{Active: true}
+	if err := bindTo(ctx, request); err != nil {
+		return err
+	}
+	id, err := r.controller.CreateWebhook(ctx.Context(), request)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusCreated)
+	return ctx.JSON(fiber.Map{"status": "success", "id": id})
+}
+
+// UpdateWebhook заменяет подписку целиком, в том числе секрет
+func (r *HttpRouter) UpdateWebhook(ctx *fiber.Ctx) error {
+	webhookId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	request := &types.WebhookRequest{Active: true}
+	if err = bindTo(ctx, request); err != nil {
+		return err
+	}
+	err = r.controller.UpdateWebhook(ctx.Context(), webhookId, request)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusOK)
+	return nil
+}
+
+func (r *HttpRouter) GetWebhooks(ctx *fiber.Ctx) error {
+	webhooks, err := r.controller.GetWebhooks(ctx.Context())
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(webhooks)
+}
+
+// GetWebhookDeliveries отдает журнал доставок подписки, ?status= фильтрует по статусу
+func (r *HttpRouter) GetWebhookDeliveries(ctx *fiber.Ctx) error {
+	webhookId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	limit, err := queryInt(ctx, "limit", 0)
+	if err != nil {
+		return err
+	}
+	offset, err := queryInt(ctx, "offset", 0)
+	if err != nil {
+		return err
+	}
+	deliveries, err := r.controller.GetWebhookDeliveries(ctx.Context(), webhookId, ctx.Query("status"), limit, offset)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(deliveries)
+}
+
+func (r *HttpRouter) GetWebhookDelivery(ctx *fiber.Ctx) error {
+	deliveryId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	delivery, err := r.controller.GetWebhookDelivery(ctx.Context(), deliveryId)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(delivery)
+}
+
+func (r *HttpRouter) ReplayWebhookDelivery(ctx *fiber.Ctx) error {
+	deliveryId, err := paramInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+	id, err := r.controller.ReplayWebhookDelivery(ctx.Context(), deliveryId)
+	if err != nil {
+		return err
+	}
+	ctx.Status(http.StatusCreated)
+	return ctx.JSON(fiber.Map{"status": "success", "id": id})
+}
@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods методы, доступные без токена
+var publicMethods = map[string]bool{
+	"/referrer.v1.Referrer/Register": true,
+	"/referrer.v1.Referrer/Login":    true,
+}
+
+type userIDKey struct{}
+
+// authInterceptor проверяет jwt из метаданных authorization: Bearer <token> так же, как Protected в HTTP API,
+// и кладет id пользователя из токена в контекст
+func authInterceptor(jwtSecret []byte) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		claims, err := parseToken(ctx, jwtSecret)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "Необходима авторизация")
+		}
+		id, _ := claims["id"].(float64)
+		return handler(context.WithValue(ctx, userIDKey{}, int(id)), req)
+	}
+}
+
+// ownerOnly пропускает только запросы пользователя к самому себе, как OwnerOnly в HTTP API
+func ownerOnly(ctx context.Context, userID int64) error {
+	id, ok := ctx.Value(userIDKey{}).(int)
+	if !ok || int64(id) != userID {
+		return status.Error(codes.PermissionDenied, "Недостаточно прав")
+	}
+	return nil
+}
+
+func parseToken(ctx context.Context, jwtSecret []byte) (jwt.MapClaims, error) {
+	values := metadata.ValueFromIncomingContext(ctx, "authorization")
+	if len(values) == 0 {
+		return nil, jwt.ErrTokenMalformed
+	}
+	raw, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, jwt.ErrTokenMalformed
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(*jwt.Token) (any, error) {
+		return jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SakuraBurst/denet/internal/referrer/rpc/referrerpb"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testSecret = []byte("secret")
+
+// ownerController выполняет задания и реферальные коды для любого пользователя, права проверяет сервер
+type ownerController struct {
+	controller
+}
+
+func (c *ownerController) CompleteTask(ctx context.Context, userID int, taskID int) (*types.Balance, error) {
+	return &types.Balance{Currency: types.CurrencyPoints, Amount: types.NewAmount(1)}, nil
+}
+
+func (c *ownerController) Referrer(ctx context.Context, id int, referrerCode string) error {
+	return nil
+}
+
+// callAuthorized вызывает метод через authInterceptor с токеном пользователя tokenUserID
+func callAuthorized(t *testing.T, tokenUserID int, method string, req any, call func(ctx context.Context) error) error {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"id": tokenUserID, "role": "user"}).SignedString(testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	_, err = authInterceptor(testSecret)(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+		return nil, call(ctx)
+	})
+	return err
+}
+
+func TestOwnerOnlyMethods(t *testing.T) {
+	s := &GrpcServer{controller: &ownerController{}}
+	const code = "6f1d3c52-2a4e-4f6b-9a51-0c7d1e2b3a4f"
+	for _, tc := range []struct {
+		name   string
+		userID int64
+		want   codes.Code
+	}{
+		{name: "own account", userID: 5, want: codes.OK},
+		{name: "another account", userID: 6, want: codes.PermissionDenied},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			complete := &referrerpb.CompleteTaskRequest{UserId: tc.userID, TaskId: 1}
+			err := callAuthorized(t, 5, "/referrer.v1.Referrer/CompleteTask", complete, func(ctx context.Context) error {
+				_, err := s.CompleteTask(ctx, complete)
+				return err
+			})
+			if status.Code(err) != tc.want {
+				t.Errorf("CompleteTask: got %v, want %s", err, tc.want)
+			}
+			referrer := &referrerpb.ReferrerRequest{UserId: tc.userID, ReferrerCode: code}
+			err = callAuthorized(t, 5, "/referrer.v1.Referrer/Referrer", referrer, func(ctx context.Context) error {
+				_, err := s.Referrer(ctx, referrer)
+				return err
+			})
+			if status.Code(err) != tc.want {
+				t.Errorf("Referrer: got %v, want %s", err, tc.want)
+			}
+		})
+	}
+}
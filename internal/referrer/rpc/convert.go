@@ -0,0 +1,142 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/rpc/referrerpb"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/SakuraBurst/denet/internal/referrer/validation"
+)
+
+// parseAmount разбирает сумму из строки, ошибка - InvalidArgument с полем field
+func parseAmount(field, value string) (types.Amount, error) {
+	amount, err := types.ParseAmount(value)
+	if err != nil {
+		return 0, invalidArgument(validation.Violation{Field: field, Rule: "amount", Message: "Должно быть суммой вида 12.5"})
+	}
+	return amount, nil
+}
+
+// parseTime разбирает дату (2006-01-02) в часовом поясе loc или время в RFC3339, пустая строка - def
+func parseTime(field, value string, def time.Time, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return def, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, loc); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, invalidArgument(validation.Violation{Field: field, Rule: "date", Message: "Должно быть датой 2006-01-02 или временем RFC3339"})
+	}
+	return t, nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func toTask(task *types.Task) *referrerpb.Task {
+	return &referrerpb.Task{
+		Id:          int64(task.ID),
+		Description: task.Description,
+		Reward:      task.Reward.String(),
+		Currency:    task.Currency,
+	}
+}
+
+func toTasks(tasks []*types.Task) []*referrerpb.Task {
+	result := make([]*referrerpb.Task, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, toTask(task))
+	}
+	return result
+}
+
+func toUser(user *types.FullUser) *referrerpb.User {
+	result := &referrerpb.User{
+		Id:             int64(user.ID),
+		FirstName:      user.FirstName,
+		LastName:       user.LastName,
+		UserName:       user.UserName,
+		ReferrerCode:   user.ReferrerCode,
+		Balance:        user.Balance.String(),
+		Balances:       make(map[string]string, len(user.Balances)),
+		CompletedTasks: toTasks(user.CompletedTasks),
+		Xp:             user.XP.String(),
+	}
+	for currency, amount := range user.Balances {
+		result.Balances[currency] = amount.String()
+	}
+	for _, expiration := range user.UpcomingExpirations {
+		result.UpcomingExpirations = append(result.UpcomingExpirations, &referrerpb.Expiration{
+			Amount:    expiration.Amount.String(),
+			ExpiresAt: formatTime(&expiration.ExpiresAt),
+		})
+	}
+	if user.Level != nil {
+		result.Level = &referrerpb.LevelProgress{
+			Level:    int64(user.Level.Level),
+			Xp:       user.Level.XP.String(),
+			LevelXp:  user.Level.LevelXP.String(),
+			Progress: int64(user.Level.Progress),
+		}
+		if user.Level.NextLevelXP != nil {
+			result.Level.NextLevelXp = user.Level.NextLevelXP.String()
+		}
+	}
+	return result
+}
+
+func toRankedUser(user *types.RankedUser) *referrerpb.RankedUser {
+	if user == nil {
+		return nil
+	}
+	return &referrerpb.RankedUser{
+		Id:        int64(user.ID),
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		UserName:  user.UserName,
+		Balance:   user.Balance.String(),
+		Rank:      int64(user.Rank),
+		DenseRank: int64(user.DenseRank),
+		Position:  int64(user.Position),
+	}
+}
+
+func toRankedUsers(users []*types.RankedUser) []*referrerpb.RankedUser {
+	result := make([]*referrerpb.RankedUser, 0, len(users))
+	for _, user := range users {
+		result = append(result, toRankedUser(user))
+	}
+	return result
+}
+
+func toNetworkRankedUser(user *types.NetworkRankedUser) *referrerpb.NetworkRankedUser {
+	if user == nil {
+		return nil
+	}
+	return &referrerpb.NetworkRankedUser{User: toRankedUser(&user.RankedUser), Relation: user.Relation}
+}
+
+func toPeriodLeaderBoard(leaderBoard *types.PeriodLeaderBoard) *referrerpb.PeriodLeaderBoard {
+	result := &referrerpb.PeriodLeaderBoard{
+		Period: string(leaderBoard.Period),
+		From:   formatTime(leaderBoard.From),
+		To:     formatTime(leaderBoard.To),
+		Final:  leaderBoard.Final,
+		Total:  int64(leaderBoard.Total),
+		Limit:  int64(leaderBoard.Limit),
+		Offset: int64(leaderBoard.Offset),
+	}
+	for _, user := range leaderBoard.Users {
+		result.Users = append(result.Users, &referrerpb.PeriodRankedUser{
+			User:   toRankedUser(&user.RankedUser),
+			Earned: user.Earned.String(),
+		})
+	}
+	return result
+}
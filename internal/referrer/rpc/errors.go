@@ -0,0 +1,112 @@
+package rpc
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/SakuraBurst/denet/internal/referrer/apierrors"
+	"github.com/SakuraBurst/denet/internal/referrer/database"
+	"github.com/SakuraBurst/denet/internal/referrer/validation"
+	"github.com/go-faster/errors"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// recoveryInterceptor стоит первым и превращает панику обработчика или следующих перехватчиков в Internal,
+// чтобы один вызов не ронял весь сервер
+func (s *GrpcServer) recoveryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			s.appLogger.Error(info.FullMethod+" panicked: ", zap.Any("panic", p), zap.Stack("stack"))
+			resp, err = nil, status.Error(codes.Internal, apierrors.InternalMessage)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// errorInterceptor единственное место, где ошибки превращаются в статусы gRPC. Пишет в лог только внутренние ошибки
+func (s *GrpcServer) errorInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	st := toStatus(err)
+	if st.Code() == codes.Internal {
+		s.appLogger.Error(info.FullMethod+" failed: ", zap.Error(err))
+	}
+	return nil, st.Err()
+}
+
+// toStatus переводит ошибку обработчика в статус gRPC, неизвестные ошибки становятся Internal
+func toStatus(err error) *status.Status {
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+	var limitErr *database.LimitExceededError
+	if errors.As(err, &limitErr) {
+		retryAfter := max(int64(math.Ceil(limitErr.RetryAfter.Seconds())), 1)
+		st := status.New(codes.ResourceExhausted, apierrors.LimitMessages[limitErr.Limit])
+		withDetails, detailsErr := st.WithDetails(
+			&errdetails.ErrorInfo{Reason: apierrors.CodeEarningLimitExceeded, Metadata: map[string]string{
+				"limit":       limitErr.Limit,
+				"retry_after": strconv.FormatInt(retryAfter, 10),
+			}},
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(limitErr.RetryAfter)},
+		)
+		if detailsErr != nil {
+			return st
+		}
+		return withDetails
+	}
+	if known, ok := apierrors.Lookup(err); ok {
+		return status.New(known.GRPC, known.Message)
+	}
+	return status.New(codes.Internal, apierrors.InternalMessage)
+}
+
+// loginError не раскрывает, что именно не подошло - ник или пароль
+func loginError(err error) error {
+	if errors.Is(err, database.ErrUserNotExist) || errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return status.Error(codes.Unauthenticated, "Неправильный логин или пароль")
+	}
+	return err
+}
+
+// referrerError ErrUserNotExist при вводе кода значит, что кода нет, а не пользователя
+func referrerError(err error) error {
+	if errors.Is(err, database.ErrUserNotExist) {
+		return status.Error(codes.NotFound, "Такого реферального кода не существует")
+	}
+	return err
+}
+
+// validateRequest возвращает InvalidArgument со всеми нарушенными правилами в деталях BadRequest
+func validateRequest(request any) error {
+	violations, err := validation.Struct(request)
+	if err != nil || violations == nil {
+		return err
+	}
+	return invalidArgument(violations...)
+}
+
+func invalidArgument(violations ...validation.Violation) error {
+	st := status.New(codes.InvalidArgument, "Запрос не прошел проверку")
+	badRequest := &errdetails.BadRequest{}
+	for _, violation := range violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Field,
+			Description: violation.Message,
+		})
+	}
+	withDetails, err := st.WithDetails(badRequest)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
@@ -0,0 +1,30 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SakuraBurst/denet/internal/referrer/database"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecoveryInterceptorTurnsPanicIntoInternal(t *testing.T) {
+	s := &GrpcServer{appLogger: zap.NewNop()}
+	info := &grpc.UnaryServerInfo{FullMethod: "/referrer.v1.Referrer/GetUserStatus"}
+	resp, err := s.recoveryInterceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+	if resp != nil || status.Code(err) != codes.Internal {
+		t.Errorf("got %v, %v, want Internal", resp, err)
+	}
+}
+
+func TestToStatusUsesSharedErrorTable(t *testing.T) {
+	st := toStatus(database.ErrUserNotExist)
+	if st.Code() != codes.NotFound || st.Message() != "Пользователя с таким id несуществует" {
+		t.Errorf("got %s %q", st.Code(), st.Message())
+	}
+}
@@ -0,0 +1,2090 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: referrer/v1/referrer.proto
+
+// API для внутренних сервисов, повторяет операции HTTP API над пользователями, заданиями,
+// рефералами и таблицами лидеров. Суммы передаются строками с точностью до сотых, как в HTTP API.
+// Код генерируется командой из README в internal/referrer/rpc/referrerpb
+
+package referrerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RegisterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FirstName     string                 `protobuf:"bytes,1,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	LastName      string                 `protobuf:"bytes,2,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	UserName      string                 `protobuf:"bytes,3,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	Password      string                 `protobuf:"bytes,4,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterRequest) Reset() {
+	*x = RegisterRequest{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRequest) ProtoMessage() {}
+
+func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RegisterRequest) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetLastName() string {
+	if x != nil {
+		return x.LastName
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type RegisterResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterResponse) Reset() {
+	*x = RegisterResponse{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterResponse) ProtoMessage() {}
+
+func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{1}
+}
+
+type LoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserName      string                 `protobuf:"bytes,1,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginRequest) Reset() {
+	*x = LoginRequest{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginRequest) ProtoMessage() {}
+
+func (x *LoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
+func (*LoginRequest) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LoginRequest) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type LoginResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginResponse) Reset() {
+	*x = LoginResponse{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginResponse) ProtoMessage() {}
+
+func (x *LoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginResponse.ProtoReflect.Descriptor instead.
+func (*LoginResponse) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LoginResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type GetUserStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserStatusRequest) Reset() {
+	*x = GetUserStatusRequest{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserStatusRequest) ProtoMessage() {}
+
+func (x *GetUserStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetUserStatusRequest) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetUserStatusRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type Task struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Reward        string                 `protobuf:"bytes,3,opt,name=reward,proto3" json:"reward,omitempty"`
+	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Task) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Task) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Task) GetReward() string {
+	if x != nil {
+		return x.Reward
+	}
+	return ""
+}
+
+func (x *Task) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type Expiration struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Amount        string                 `protobuf:"bytes,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	ExpiresAt     string                 `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Expiration) Reset() {
+	*x = Expiration{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Expiration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Expiration) ProtoMessage() {}
+
+func (x *Expiration) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Expiration.ProtoReflect.Descriptor instead.
+func (*Expiration) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Expiration) GetAmount() string {
+	if x != nil {
+		return x.Amount
+	}
+	return ""
+}
+
+func (x *Expiration) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+type LevelProgress struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Level   int64                  `protobuf:"varint,1,opt,name=level,proto3" json:"level,omitempty"`
+	Xp      string                 `protobuf:"bytes,2,opt,name=xp,proto3" json:"xp,omitempty"`
+	LevelXp string                 `protobuf:"bytes,3,opt,name=level_xp,json=levelXp,proto3" json:"level_xp,omitempty"`
+	// next_level_xp пустой на последнем уровне
+	NextLevelXp   string `protobuf:"bytes,4,opt,name=next_level_xp,json=nextLevelXp,proto3" json:"next_level_xp,omitempty"`
+	Progress      int64  `protobuf:"varint,5,opt,name=progress,proto3" json:"progress,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LevelProgress) Reset() {
+	*x = LevelProgress{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LevelProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LevelProgress) ProtoMessage() {}
+
+func (x *LevelProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LevelProgress.ProtoReflect.Descriptor instead.
+func (*LevelProgress) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LevelProgress) GetLevel() int64 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *LevelProgress) GetXp() string {
+	if x != nil {
+		return x.Xp
+	}
+	return ""
+}
+
+func (x *LevelProgress) GetLevelXp() string {
+	if x != nil {
+		return x.LevelXp
+	}
+	return ""
+}
+
+func (x *LevelProgress) GetNextLevelXp() string {
+	if x != nil {
+		return x.NextLevelXp
+	}
+	return ""
+}
+
+func (x *LevelProgress) GetProgress() int64 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+type User struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Id                  int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	FirstName           string                 `protobuf:"bytes,2,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	LastName            string                 `protobuf:"bytes,3,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	UserName            string                 `protobuf:"bytes,4,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	ReferrerCode        string                 `protobuf:"bytes,5,opt,name=referrer_code,json=referrerCode,proto3" json:"referrer_code,omitempty"`
+	Balance             string                 `protobuf:"bytes,6,opt,name=balance,proto3" json:"balance,omitempty"`
+	Balances            map[string]string      `protobuf:"bytes,7,rep,name=balances,proto3" json:"balances,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CompletedTasks      []*Task                `protobuf:"bytes,8,rep,name=completed_tasks,json=completedTasks,proto3" json:"completed_tasks,omitempty"`
+	UpcomingExpirations []*Expiration          `protobuf:"bytes,9,rep,name=upcoming_expirations,json=upcomingExpirations,proto3" json:"upcoming_expirations,omitempty"`
+	Xp                  string                 `protobuf:"bytes,10,opt,name=xp,proto3" json:"xp,omitempty"`
+	Level               *LevelProgress         `protobuf:"bytes,11,opt,name=level,proto3" json:"level,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *User) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *User) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *User) GetLastName() string {
+	if x != nil {
+		return x.LastName
+	}
+	return ""
+}
+
+func (x *User) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *User) GetReferrerCode() string {
+	if x != nil {
+		return x.ReferrerCode
+	}
+	return ""
+}
+
+func (x *User) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+func (x *User) GetBalances() map[string]string {
+	if x != nil {
+		return x.Balances
+	}
+	return nil
+}
+
+func (x *User) GetCompletedTasks() []*Task {
+	if x != nil {
+		return x.CompletedTasks
+	}
+	return nil
+}
+
+func (x *User) GetUpcomingExpirations() []*Expiration {
+	if x != nil {
+		return x.UpcomingExpirations
+	}
+	return nil
+}
+
+func (x *User) GetXp() string {
+	if x != nil {
+		return x.Xp
+	}
+	return ""
+}
+
+func (x *User) GetLevel() *LevelProgress {
+	if x != nil {
+		return x.Level
+	}
+	return nil
+}
+
+type CompleteTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	TaskId        int64                  `protobuf:"varint,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteTaskRequest) Reset() {
+	*x = CompleteTaskRequest{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteTaskRequest) ProtoMessage() {}
+
+func (x *CompleteTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteTaskRequest.ProtoReflect.Descriptor instead.
+func (*CompleteTaskRequest) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CompleteTaskRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *CompleteTaskRequest) GetTaskId() int64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+type CompleteTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reward        string                 `protobuf:"bytes,1,opt,name=reward,proto3" json:"reward,omitempty"`
+	Currency      string                 `protobuf:"bytes,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteTaskResponse) Reset() {
+	*x = CompleteTaskResponse{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteTaskResponse) ProtoMessage() {}
+
+func (x *CompleteTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteTaskResponse.ProtoReflect.Descriptor instead.
+func (*CompleteTaskResponse) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CompleteTaskResponse) GetReward() string {
+	if x != nil {
+		return x.Reward
+	}
+	return ""
+}
+
+func (x *CompleteTaskResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type ReferrerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ReferrerCode  string                 `protobuf:"bytes,2,opt,name=referrer_code,json=referrerCode,proto3" json:"referrer_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReferrerRequest) Reset() {
+	*x = ReferrerRequest{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReferrerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReferrerRequest) ProtoMessage() {}
+
+func (x *ReferrerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReferrerRequest.ProtoReflect.Descriptor instead.
+func (*ReferrerRequest) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ReferrerRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ReferrerRequest) GetReferrerCode() string {
+	if x != nil {
+		return x.ReferrerCode
+	}
+	return ""
+}
+
+type ReferrerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReferrerResponse) Reset() {
+	*x = ReferrerResponse{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReferrerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReferrerResponse) ProtoMessage() {}
+
+func (x *ReferrerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReferrerResponse.ProtoReflect.Descriptor instead.
+func (*ReferrerResponse) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{12}
+}
+
+type GetAllTasksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAllTasksRequest) Reset() {
+	*x = GetAllTasksRequest{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllTasksRequest) ProtoMessage() {}
+
+func (x *GetAllTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllTasksRequest.ProtoReflect.Descriptor instead.
+func (*GetAllTasksRequest) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{13}
+}
+
+type TaskList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskList) Reset() {
+	*x = TaskList{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskList) ProtoMessage() {}
+
+func (x *TaskList) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskList.ProtoReflect.Descriptor instead.
+func (*TaskList) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TaskList) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type GetTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        int64                  `protobuf:"varint,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTaskRequest) Reset() {
+	*x = GetTaskRequest{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTaskRequest) ProtoMessage() {}
+
+func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTaskRequest.ProtoReflect.Descriptor instead.
+func (*GetTaskRequest) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetTaskRequest) GetTaskId() int64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+type CreateTaskRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Description string                 `protobuf:"bytes,1,opt,name=description,proto3" json:"description,omitempty"`
+	Reward      string                 `protobuf:"bytes,2,opt,name=reward,proto3" json:"reward,omitempty"`
+	// currency по умолчанию points
+	Currency      string `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTaskRequest) Reset() {
+	*x = CreateTaskRequest{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTaskRequest) ProtoMessage() {}
+
+func (x *CreateTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTaskRequest.ProtoReflect.Descriptor instead.
+func (*CreateTaskRequest) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CreateTaskRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetReward() string {
+	if x != nil {
+		return x.Reward
+	}
+	return ""
+}
+
+func (x *CreateTaskRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+type CreateTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTaskResponse) Reset() {
+	*x = CreateTaskResponse{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTaskResponse) ProtoMessage() {}
+
+func (x *CreateTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTaskResponse.ProtoReflect.Descriptor instead.
+func (*CreateTaskResponse) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CreateTaskResponse) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type UpdateTaskRewardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        int64                  `protobuf:"varint,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Reward        string                 `protobuf:"bytes,2,opt,name=reward,proto3" json:"reward,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTaskRewardRequest) Reset() {
+	*x = UpdateTaskRewardRequest{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTaskRewardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTaskRewardRequest) ProtoMessage() {}
+
+func (x *UpdateTaskRewardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTaskRewardRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTaskRewardRequest) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UpdateTaskRewardRequest) GetTaskId() int64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+func (x *UpdateTaskRewardRequest) GetReward() string {
+	if x != nil {
+		return x.Reward
+	}
+	return ""
+}
+
+type UpdateTaskRewardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTaskRewardResponse) Reset() {
+	*x = UpdateTaskRewardResponse{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTaskRewardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTaskRewardResponse) ProtoMessage() {}
+
+func (x *UpdateTaskRewardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTaskRewardResponse.ProtoReflect.Descriptor instead.
+func (*UpdateTaskRewardResponse) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{19}
+}
+
+type RankedUser struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	FirstName     string                 `protobuf:"bytes,2,opt,name=first_name,json=firstName,proto3" json:"first_name,omitempty"`
+	LastName      string                 `protobuf:"bytes,3,opt,name=last_name,json=lastName,proto3" json:"last_name,omitempty"`
+	UserName      string                 `protobuf:"bytes,4,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	Balance       string                 `protobuf:"bytes,5,opt,name=balance,proto3" json:"balance,omitempty"`
+	Rank          int64                  `protobuf:"varint,6,opt,name=rank,proto3" json:"rank,omitempty"`
+	DenseRank     int64                  `protobuf:"varint,7,opt,name=dense_rank,json=denseRank,proto3" json:"dense_rank,omitempty"`
+	Position      int64                  `protobuf:"varint,8,opt,name=position,proto3" json:"position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RankedUser) Reset() {
+	*x = RankedUser{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RankedUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RankedUser) ProtoMessage() {}
+
+func (x *RankedUser) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RankedUser.ProtoReflect.Descriptor instead.
+func (*RankedUser) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RankedUser) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RankedUser) GetFirstName() string {
+	if x != nil {
+		return x.FirstName
+	}
+	return ""
+}
+
+func (x *RankedUser) GetLastName() string {
+	if x != nil {
+		return x.LastName
+	}
+	return ""
+}
+
+func (x *RankedUser) GetUserName() string {
+	if x != nil {
+		return x.UserName
+	}
+	return ""
+}
+
+func (x *RankedUser) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+func (x *RankedUser) GetRank() int64 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *RankedUser) GetDenseRank() int64 {
+	if x != nil {
+		return x.DenseRank
+	}
+	return 0
+}
+
+func (x *RankedUser) GetPosition() int64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+// limit и offset как в HTTP API: 0 - значение по умолчанию из конфига
+type GetLeaderBoardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int64                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLeaderBoardRequest) Reset() {
+	*x = GetLeaderBoardRequest{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLeaderBoardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLeaderBoardRequest) ProtoMessage() {}
+
+func (x *GetLeaderBoardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLeaderBoardRequest.ProtoReflect.Descriptor instead.
+func (*GetLeaderBoardRequest) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetLeaderBoardRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetLeaderBoardRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type LeaderBoard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Users         []*RankedUser          `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Limit         int64                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int64                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaderBoard) Reset() {
+	*x = LeaderBoard{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaderBoard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaderBoard) ProtoMessage() {}
+
+func (x *LeaderBoard) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaderBoard.ProtoReflect.Descriptor instead.
+func (*LeaderBoard) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *LeaderBoard) GetUsers() []*RankedUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *LeaderBoard) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *LeaderBoard) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *LeaderBoard) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type GetUserRankRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// neighbors сколько соседей сверху и снизу, если не задано - из конфига
+	Neighbors     *int64 `protobuf:"varint,2,opt,name=neighbors,proto3,oneof" json:"neighbors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRankRequest) Reset() {
+	*x = GetUserRankRequest{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRankRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRankRequest) ProtoMessage() {}
+
+func (x *GetUserRankRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRankRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRankRequest) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetUserRankRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *GetUserRankRequest) GetNeighbors() int64 {
+	if x != nil && x.Neighbors != nil {
+		return *x.Neighbors
+	}
+	return 0
+}
+
+type UserRank struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *RankedUser            `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Above         []*RankedUser          `protobuf:"bytes,2,rep,name=above,proto3" json:"above,omitempty"`
+	Below         []*RankedUser          `protobuf:"bytes,3,rep,name=below,proto3" json:"below,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserRank) Reset() {
+	*x = UserRank{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserRank) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserRank) ProtoMessage() {}
+
+func (x *UserRank) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserRank.ProtoReflect.Descriptor instead.
+func (*UserRank) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UserRank) GetUser() *RankedUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *UserRank) GetAbove() []*RankedUser {
+	if x != nil {
+		return x.Above
+	}
+	return nil
+}
+
+func (x *UserRank) GetBelow() []*RankedUser {
+	if x != nil {
+		return x.Below
+	}
+	return nil
+}
+
+type GetNetworkLeaderBoardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNetworkLeaderBoardRequest) Reset() {
+	*x = GetNetworkLeaderBoardRequest{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNetworkLeaderBoardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNetworkLeaderBoardRequest) ProtoMessage() {}
+
+func (x *GetNetworkLeaderBoardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNetworkLeaderBoardRequest.ProtoReflect.Descriptor instead.
+func (*GetNetworkLeaderBoardRequest) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetNetworkLeaderBoardRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type NetworkRankedUser struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	User  *RankedUser            `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	// relation self, referrer, referee или sibling
+	Relation      string `protobuf:"bytes,2,opt,name=relation,proto3" json:"relation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkRankedUser) Reset() {
+	*x = NetworkRankedUser{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkRankedUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkRankedUser) ProtoMessage() {}
+
+func (x *NetworkRankedUser) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkRankedUser.ProtoReflect.Descriptor instead.
+func (*NetworkRankedUser) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *NetworkRankedUser) GetUser() *RankedUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *NetworkRankedUser) GetRelation() string {
+	if x != nil {
+		return x.Relation
+	}
+	return ""
+}
+
+type NetworkLeaderBoard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *NetworkRankedUser     `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Users         []*NetworkRankedUser   `protobuf:"bytes,2,rep,name=users,proto3" json:"users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkLeaderBoard) Reset() {
+	*x = NetworkLeaderBoard{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkLeaderBoard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkLeaderBoard) ProtoMessage() {}
+
+func (x *NetworkLeaderBoard) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkLeaderBoard.ProtoReflect.Descriptor instead.
+func (*NetworkLeaderBoard) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *NetworkLeaderBoard) GetUser() *NetworkRankedUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *NetworkLeaderBoard) GetUsers() []*NetworkRankedUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+// GetPeriodLeaderBoardRequest даты в формате 2006-01-02 или RFC3339, как query параметры HTTP API
+type GetPeriodLeaderBoardRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// period daily, weekly, monthly, all или custom, по умолчанию all
+	Period        string `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	Date          string `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	From          string `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            string `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	Limit         int64  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int64  `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPeriodLeaderBoardRequest) Reset() {
+	*x = GetPeriodLeaderBoardRequest{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPeriodLeaderBoardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPeriodLeaderBoardRequest) ProtoMessage() {}
+
+func (x *GetPeriodLeaderBoardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPeriodLeaderBoardRequest.ProtoReflect.Descriptor instead.
+func (*GetPeriodLeaderBoardRequest) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetPeriodLeaderBoardRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *GetPeriodLeaderBoardRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *GetPeriodLeaderBoardRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *GetPeriodLeaderBoardRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *GetPeriodLeaderBoardRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetPeriodLeaderBoardRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type PeriodRankedUser struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *RankedUser            `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Earned        string                 `protobuf:"bytes,2,opt,name=earned,proto3" json:"earned,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PeriodRankedUser) Reset() {
+	*x = PeriodRankedUser{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeriodRankedUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeriodRankedUser) ProtoMessage() {}
+
+func (x *PeriodRankedUser) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeriodRankedUser.ProtoReflect.Descriptor instead.
+func (*PeriodRankedUser) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *PeriodRankedUser) GetUser() *RankedUser {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *PeriodRankedUser) GetEarned() string {
+	if x != nil {
+		return x.Earned
+	}
+	return ""
+}
+
+type PeriodLeaderBoard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Period        string                 `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	From          string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Final         bool                   `protobuf:"varint,4,opt,name=final,proto3" json:"final,omitempty"`
+	Users         []*PeriodRankedUser    `protobuf:"bytes,5,rep,name=users,proto3" json:"users,omitempty"`
+	Total         int64                  `protobuf:"varint,6,opt,name=total,proto3" json:"total,omitempty"`
+	Limit         int64                  `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int64                  `protobuf:"varint,8,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PeriodLeaderBoard) Reset() {
+	*x = PeriodLeaderBoard{}
+	mi := &file_referrer_v1_referrer_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeriodLeaderBoard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeriodLeaderBoard) ProtoMessage() {}
+
+func (x *PeriodLeaderBoard) ProtoReflect() protoreflect.Message {
+	mi := &file_referrer_v1_referrer_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeriodLeaderBoard.ProtoReflect.Descriptor instead.
+func (*PeriodLeaderBoard) Descriptor() ([]byte, []int) {
+	return file_referrer_v1_referrer_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *PeriodLeaderBoard) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *PeriodLeaderBoard) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *PeriodLeaderBoard) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *PeriodLeaderBoard) GetFinal() bool {
+	if x != nil {
+		return x.Final
+	}
+	return false
+}
+
+func (x *PeriodLeaderBoard) GetUsers() []*PeriodRankedUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *PeriodLeaderBoard) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *PeriodLeaderBoard) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *PeriodLeaderBoard) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+var File_referrer_v1_referrer_proto protoreflect.FileDescriptor
+
+const file_referrer_v1_referrer_proto_rawDesc = "" +
+	"\n" +
+	"\x1areferrer/v1/referrer.proto\x12\vreferrer.v1\"\x86\x01\n" +
+	"\x0fRegisterRequest\x12\x1d\n" +
+	"\n" +
+	"first_name\x18\x01 \x01(\tR\tfirstName\x12\x1b\n" +
+	"\tlast_name\x18\x02 \x01(\tR\blastName\x12\x1b\n" +
+	"\tuser_name\x18\x03 \x01(\tR\buserName\x12\x1a\n" +
+	"\bpassword\x18\x04 \x01(\tR\bpassword\"\x12\n" +
+	"\x10RegisterResponse\"G\n" +
+	"\fLoginRequest\x12\x1b\n" +
+	"\tuser_name\x18\x01 \x01(\tR\buserName\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"%\n" +
+	"\rLoginResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"/\n" +
+	"\x14GetUserStatusRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"l\n" +
+	"\x04Task\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06reward\x18\x03 \x01(\tR\x06reward\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\"C\n" +
+	"\n" +
+	"Expiration\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\tR\x06amount\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\tR\texpiresAt\"\x90\x01\n" +
+	"\rLevelProgress\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\x03R\x05level\x12\x0e\n" +
+	"\x02xp\x18\x02 \x01(\tR\x02xp\x12\x19\n" +
+	"\blevel_xp\x18\x03 \x01(\tR\alevelXp\x12\"\n" +
+	"\rnext_level_xp\x18\x04 \x01(\tR\vnextLevelXp\x12\x1a\n" +
+	"\bprogress\x18\x05 \x01(\x03R\bprogress\"\xf2\x03\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"first_name\x18\x02 \x01(\tR\tfirstName\x12\x1b\n" +
+	"\tlast_name\x18\x03 \x01(\tR\blastName\x12\x1b\n" +
+	"\tuser_name\x18\x04 \x01(\tR\buserName\x12#\n" +
+	"\rreferrer_code\x18\x05 \x01(\tR\freferrerCode\x12\x18\n" +
+	"\abalance\x18\x06 \x01(\tR\abalance\x12;\n" +
+	"\bbalances\x18\a \x03(\v2\x1f.referrer.v1.User.BalancesEntryR\bbalances\x12:\n" +
+	"\x0fcompleted_tasks\x18\b \x03(\v2\x11.referrer.v1.TaskR\x0ecompletedTasks\x12J\n" +
+	"\x14upcoming_expirations\x18\t \x03(\v2\x17.referrer.v1.ExpirationR\x13upcomingExpirations\x12\x0e\n" +
+	"\x02xp\x18\n" +
+	" \x01(\tR\x02xp\x120\n" +
+	"\x05level\x18\v \x01(\v2\x1a.referrer.v1.LevelProgressR\x05level\x1a;\n" +
+	"\rBalancesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"G\n" +
+	"\x13CompleteTaskRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x17\n" +
+	"\atask_id\x18\x02 \x01(\x03R\x06taskId\"J\n" +
+	"\x14CompleteTaskResponse\x12\x16\n" +
+	"\x06reward\x18\x01 \x01(\tR\x06reward\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\tR\bcurrency\"O\n" +
+	"\x0fReferrerRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12#\n" +
+	"\rreferrer_code\x18\x02 \x01(\tR\freferrerCode\"\x12\n" +
+	"\x10ReferrerResponse\"\x14\n" +
+	"\x12GetAllTasksRequest\"3\n" +
+	"\bTaskList\x12'\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x11.referrer.v1.TaskR\x05tasks\")\n" +
+	"\x0eGetTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\x03R\x06taskId\"i\n" +
+	"\x11CreateTaskRequest\x12 \n" +
+	"\vdescription\x18\x01 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06reward\x18\x02 \x01(\tR\x06reward\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\tR\bcurrency\"$\n" +
+	"\x12CreateTaskResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"J\n" +
+	"\x17UpdateTaskRewardRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\x03R\x06taskId\x12\x16\n" +
+	"\x06reward\x18\x02 \x01(\tR\x06reward\"\x1a\n" +
+	"\x18UpdateTaskRewardResponse\"\xde\x01\n" +
+	"\n" +
+	"RankedUser\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"first_name\x18\x02 \x01(\tR\tfirstName\x12\x1b\n" +
+	"\tlast_name\x18\x03 \x01(\tR\blastName\x12\x1b\n" +
+	"\tuser_name\x18\x04 \x01(\tR\buserName\x12\x18\n" +
+	"\abalance\x18\x05 \x01(\tR\abalance\x12\x12\n" +
+	"\x04rank\x18\x06 \x01(\x03R\x04rank\x12\x1d\n" +
+	"\n" +
+	"dense_rank\x18\a \x01(\x03R\tdenseRank\x12\x1a\n" +
+	"\bposition\x18\b \x01(\x03R\bposition\"E\n" +
+	"\x15GetLeaderBoardRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x03R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\"\x80\x01\n" +
+	"\vLeaderBoard\x12-\n" +
+	"\x05users\x18\x01 \x03(\v2\x17.referrer.v1.RankedUserR\x05users\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x03R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x03R\x06offset\"^\n" +
+	"\x12GetUserRankRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12!\n" +
+	"\tneighbors\x18\x02 \x01(\x03H\x00R\tneighbors\x88\x01\x01B\f\n" +
+	"\n" +
+	"_neighbors\"\x95\x01\n" +
+	"\bUserRank\x12+\n" +
+	"\x04user\x18\x01 \x01(\v2\x17.referrer.v1.RankedUserR\x04user\x12-\n" +
+	"\x05above\x18\x02 \x03(\v2\x17.referrer.v1.RankedUserR\x05above\x12-\n" +
+	"\x05below\x18\x03 \x03(\v2\x17.referrer.v1.RankedUserR\x05below\"7\n" +
+	"\x1cGetNetworkLeaderBoardRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"\\\n" +
+	"\x11NetworkRankedUser\x12+\n" +
+	"\x04user\x18\x01 \x01(\v2\x17.referrer.v1.RankedUserR\x04user\x12\x1a\n" +
+	"\brelation\x18\x02 \x01(\tR\brelation\"~\n" +
+	"\x12NetworkLeaderBoard\x122\n" +
+	"\x04user\x18\x01 \x01(\v2\x1e.referrer.v1.NetworkRankedUserR\x04user\x124\n" +
+	"\x05users\x18\x02 \x03(\v2\x1e.referrer.v1.NetworkRankedUserR\x05users\"\x9b\x01\n" +
+	"\x1bGetPeriodLeaderBoardRequest\x12\x16\n" +
+	"\x06period\x18\x01 \x01(\tR\x06period\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\tR\x04date\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\tR\x02to\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x03R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x06 \x01(\x03R\x06offset\"W\n" +
+	"\x10PeriodRankedUser\x12+\n" +
+	"\x04user\x18\x01 \x01(\v2\x17.referrer.v1.RankedUserR\x04user\x12\x16\n" +
+	"\x06earned\x18\x02 \x01(\tR\x06earned\"\xde\x01\n" +
+	"\x11PeriodLeaderBoard\x12\x16\n" +
+	"\x06period\x18\x01 \x01(\tR\x06period\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\x12\x14\n" +
+	"\x05final\x18\x04 \x01(\bR\x05final\x123\n" +
+	"\x05users\x18\x05 \x03(\v2\x1d.referrer.v1.PeriodRankedUserR\x05users\x12\x14\n" +
+	"\x05total\x18\x06 \x01(\x03R\x05total\x12\x14\n" +
+	"\x05limit\x18\a \x01(\x03R\x05limit\x12\x16\n" +
+	"\x06offset\x18\b \x01(\x03R\x06offset2\x88\b\n" +
+	"\bReferrer\x12G\n" +
+	"\bRegister\x12\x1c.referrer.v1.RegisterRequest\x1a\x1d.referrer.v1.RegisterResponse\x12>\n" +
+	"\x05Login\x12\x19.referrer.v1.LoginRequest\x1a\x1a.referrer.v1.LoginResponse\x12E\n" +
+	"\rGetUserStatus\x12!.referrer.v1.GetUserStatusRequest\x1a\x11.referrer.v1.User\x12S\n" +
+	"\fCompleteTask\x12 .referrer.v1.CompleteTaskRequest\x1a!.referrer.v1.CompleteTaskResponse\x12G\n" +
+	"\bReferrer\x12\x1c.referrer.v1.ReferrerRequest\x1a\x1d.referrer.v1.ReferrerResponse\x12E\n" +
+	"\vGetAllTasks\x12\x1f.referrer.v1.GetAllTasksRequest\x1a\x15.referrer.v1.TaskList\x129\n" +
+	"\aGetTask\x12\x1b.referrer.v1.GetTaskRequest\x1a\x11.referrer.v1.Task\x12M\n" +
+	"\n" +
+	"CreateTask\x12\x1e.referrer.v1.CreateTaskRequest\x1a\x1f.referrer.v1.CreateTaskResponse\x12_\n" +
+	"\x10UpdateTaskReward\x12$.referrer.v1.UpdateTaskRewardRequest\x1a%.referrer.v1.UpdateTaskRewardResponse\x12N\n" +
+	"\x0eGetLeaderBoard\x12\".referrer.v1.GetLeaderBoardRequest\x1a\x18.referrer.v1.LeaderBoard\x12E\n" +
+	"\vGetUserRank\x12\x1f.referrer.v1.GetUserRankRequest\x1a\x15.referrer.v1.UserRank\x12c\n" +
+	"\x15GetNetworkLeaderBoard\x12).referrer.v1.GetNetworkLeaderBoardRequest\x1a\x1f.referrer.v1.NetworkLeaderBoard\x12`\n" +
+	"\x14GetPeriodLeaderBoard\x12(.referrer.v1.GetPeriodLeaderBoardRequest\x1a\x1e.referrer.v1.PeriodLeaderBoardBJZHgithub.com/SakuraBurst/denet/internal/referrer/rpc/referrerpb;referrerpbb\x06proto3"
+
+var (
+	file_referrer_v1_referrer_proto_rawDescOnce sync.Once
+	file_referrer_v1_referrer_proto_rawDescData []byte
+)
+
+func file_referrer_v1_referrer_proto_rawDescGZIP() []byte {
+	file_referrer_v1_referrer_proto_rawDescOnce.Do(func() {
+		file_referrer_v1_referrer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_referrer_v1_referrer_proto_rawDesc), len(file_referrer_v1_referrer_proto_rawDesc)))
+	})
+	return file_referrer_v1_referrer_proto_rawDescData
+}
+
+var file_referrer_v1_referrer_proto_msgTypes = make([]protoimpl.MessageInfo, 32)
+var file_referrer_v1_referrer_proto_goTypes = []any{
+	(*RegisterRequest)(nil),              // 0: referrer.v1.RegisterRequest
+	(*RegisterResponse)(nil),             // 1: referrer.v1.RegisterResponse
+	(*LoginRequest)(nil),                 // 2: referrer.v1.LoginRequest
+	(*LoginResponse)(nil),                // 3: referrer.v1.LoginResponse
+	(*GetUserStatusRequest)(nil),         // 4: referrer.v1.GetUserStatusRequest
+	(*Task)(nil),                         // 5: referrer.v1.Task
+	(*Expiration)(nil),                   // 6: referrer.v1.Expiration
+	(*LevelProgress)(nil),                // 7: referrer.v1.LevelProgress
+	(*User)(nil),                         // 8: referrer.v1.User
+	(*CompleteTaskRequest)(nil),          // 9: referrer.v1.CompleteTaskRequest
+	(*CompleteTaskResponse)(nil),         // 10: referrer.v1.CompleteTaskResponse
+	(*ReferrerRequest)(nil),              // 11: referrer.v1.ReferrerRequest
+	(*ReferrerResponse)(nil),             // 12: referrer.v1.ReferrerResponse
+	(*GetAllTasksRequest)(nil),           // 13: referrer.v1.GetAllTasksRequest
+	(*TaskList)(nil),                     // 14: referrer.v1.TaskList
+	(*GetTaskRequest)(nil),               // 15: referrer.v1.GetTaskRequest
+	(*CreateTaskRequest)(nil),            // 16: referrer.v1.CreateTaskRequest
+	(*CreateTaskResponse)(nil),           // 17: referrer.v1.CreateTaskResponse
+	(*UpdateTaskRewardRequest)(nil),      // 18: referrer.v1.UpdateTaskRewardRequest
+	(*UpdateTaskRewardResponse)(nil),     // 19: referrer.v1.UpdateTaskRewardResponse
+	(*RankedUser)(nil),                   // 20: referrer.v1.RankedUser
+	(*GetLeaderBoardRequest)(nil),        // 21: referrer.v1.GetLeaderBoardRequest
+	(*LeaderBoard)(nil),                  // 22: referrer.v1.LeaderBoard
+	(*GetUserRankRequest)(nil),           // 23: referrer.v1.GetUserRankRequest
+	(*UserRank)(nil),                     // 24: referrer.v1.UserRank
+	(*GetNetworkLeaderBoardRequest)(nil), // 25: referrer.v1.GetNetworkLeaderBoardRequest
+	(*NetworkRankedUser)(nil),            // 26: referrer.v1.NetworkRankedUser
+	(*NetworkLeaderBoard)(nil),           // 27: referrer.v1.NetworkLeaderBoard
+	(*GetPeriodLeaderBoardRequest)(nil),  // 28: referrer.v1.GetPeriodLeaderBoardRequest
+	(*PeriodRankedUser)(nil),             // 29: referrer.v1.PeriodRankedUser
+	(*PeriodLeaderBoard)(nil),            // 30: referrer.v1.PeriodLeaderBoard
+	nil,                                  // 31: referrer.v1.User.BalancesEntry
+}
+var file_referrer_v1_referrer_proto_depIdxs = []int32{
+	31, // 0: referrer.v1.User.balances:type_name -> referrer.v1.User.BalancesEntry
+	5,  // 1: referrer.v1.User.completed_tasks:type_name -> referrer.v1.Task
+	6,  // 2: referrer.v1.User.upcoming_expirations:type_name -> referrer.v1.Expiration
+	7,  // 3: referrer.v1.User.level:type_name -> referrer.v1.LevelProgress
+	5,  // 4: referrer.v1.TaskList.tasks:type_name -> referrer.v1.Task
+	20, // 5: referrer.v1.LeaderBoard.users:type_name -> referrer.v1.RankedUser
+	20, // 6: referrer.v1.UserRank.user:type_name -> referrer.v1.RankedUser
+	20, // 7: referrer.v1.UserRank.above:type_name -> referrer.v1.RankedUser
+	20, // 8: referrer.v1.UserRank.below:type_name -> referrer.v1.RankedUser
+	20, // 9: referrer.v1.NetworkRankedUser.user:type_name -> referrer.v1.RankedUser
+	26, // 10: referrer.v1.NetworkLeaderBoard.user:type_name -> referrer.v1.NetworkRankedUser
+	26, // 11: referrer.v1.NetworkLeaderBoard.users:type_name -> referrer.v1.NetworkRankedUser
+	20, // 12: referrer.v1.PeriodRankedUser.user:type_name -> referrer.v1.RankedUser
+	29, // 13: referrer.v1.PeriodLeaderBoard.users:type_name -> referrer.v1.PeriodRankedUser
+	0,  // 14: referrer.v1.Referrer.Register:input_type -> referrer.v1.RegisterRequest
+	2,  // 15: referrer.v1.Referrer.Login:input_type -> referrer.v1.LoginRequest
+	4,  // 16: referrer.v1.Referrer.GetUserStatus:input_type -> referrer.v1.GetUserStatusRequest
+	9,  // 17: referrer.v1.Referrer.CompleteTask:input_type -> referrer.v1.CompleteTaskRequest
+	11, // 18: referrer.v1.Referrer.Referrer:input_type -> referrer.v1.ReferrerRequest
+	13, // 19: referrer.v1.Referrer.GetAllTasks:input_type -> referrer.v1.GetAllTasksRequest
+	15, // 20: referrer.v1.Referrer.GetTask:input_type -> referrer.v1.GetTaskRequest
+	16, // 21: referrer.v1.Referrer.CreateTask:input_type -> referrer.v1.CreateTaskRequest
+	18, // 22: referrer.v1.Referrer.UpdateTaskReward:input_type -> referrer.v1.UpdateTaskRewardRequest
+	21, // 23: referrer.v1.Referrer.GetLeaderBoard:input_type -> referrer.v1.GetLeaderBoardRequest
+	23, // 24: referrer.v1.Referrer.GetUserRank:input_type -> referrer.v1.GetUserRankRequest
+	25, // 25: referrer.v1.Referrer.GetNetworkLeaderBoard:input_type -> referrer.v1.GetNetworkLeaderBoardRequest
+	28, // 26: referrer.v1.Referrer.GetPeriodLeaderBoard:input_type -> referrer.v1.GetPeriodLeaderBoardRequest
+	1,  // 27: referrer.v1.Referrer.Register:output_type -> referrer.v1.RegisterResponse
+	3,  // 28: referrer.v1.Referrer.Login:output_type -> referrer.v1.LoginResponse
+	8,  // 29: referrer.v1.Referrer.GetUserStatus:output_type -> referrer.v1.User
+	10, // 30: referrer.v1.Referrer.CompleteTask:output_type -> referrer.v1.CompleteTaskResponse
+	12, // 31: referrer.v1.Referrer.Referrer:output_type -> referrer.v1.ReferrerResponse
+	14, // 32: referrer.v1.Referrer.GetAllTasks:output_type -> referrer.v1.TaskList
+	5,  // 33: referrer.v1.Referrer.GetTask:output_type -> referrer.v1.Task
+	17, // 34: referrer.v1.Referrer.CreateTask:output_type -> referrer.v1.CreateTaskResponse
+	19, // 35: referrer.v1.Referrer.UpdateTaskReward:output_type -> referrer.v1.UpdateTaskRewardResponse
+	22, // 36: referrer.v1.Referrer.GetLeaderBoard:output_type -> referrer.v1.LeaderBoard
+	24, // 37: referrer.v1.Referrer.GetUserRank:output_type -> referrer.v1.UserRank
+	27, // 38: referrer.v1.Referrer.GetNetworkLeaderBoard:output_type -> referrer.v1.NetworkLeaderBoard
+	30, // 39: referrer.v1.Referrer.GetPeriodLeaderBoard:output_type -> referrer.v1.PeriodLeaderBoard
+	27, // [27:40] is the sub-list for method output_type
+	14, // [14:27] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_referrer_v1_referrer_proto_init() }
+func file_referrer_v1_referrer_proto_init() {
+	if File_referrer_v1_referrer_proto != nil {
+		return
+	}
+	file_referrer_v1_referrer_proto_msgTypes[23].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_referrer_v1_referrer_proto_rawDesc), len(file_referrer_v1_referrer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   32,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_referrer_v1_referrer_proto_goTypes,
+		DependencyIndexes: file_referrer_v1_referrer_proto_depIdxs,
+		MessageInfos:      file_referrer_v1_referrer_proto_msgTypes,
+	}.Build()
+	File_referrer_v1_referrer_proto = out.File
+	file_referrer_v1_referrer_proto_goTypes = nil
+	file_referrer_v1_referrer_proto_depIdxs = nil
+}
@@ -0,0 +1,585 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: referrer/v1/referrer.proto
+
+// API для внутренних сервисов, повторяет операции HTTP API над пользователями, заданиями,
+// рефералами и таблицами лидеров. Суммы передаются строками с точностью до сотых, как в HTTP API.
+// Код генерируется командой из README в internal/referrer/rpc/referrerpb
+
+package referrerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Referrer_Register_FullMethodName              = "/referrer.v1.Referrer/Register"
+	Referrer_Login_FullMethodName                 = "/referrer.v1.Referrer/Login"
+	Referrer_GetUserStatus_FullMethodName         = "/referrer.v1.Referrer/GetUserStatus"
+	Referrer_CompleteTask_FullMethodName          = "/referrer.v1.Referrer/CompleteTask"
+	Referrer_Referrer_FullMethodName              = "/referrer.v1.Referrer/Referrer"
+	Referrer_GetAllTasks_FullMethodName           = "/referrer.v1.Referrer/GetAllTasks"
+	Referrer_GetTask_FullMethodName               = "/referrer.v1.Referrer/GetTask"
+	Referrer_CreateTask_FullMethodName            = "/referrer.v1.Referrer/CreateTask"
+	Referrer_UpdateTaskReward_FullMethodName      = "/referrer.v1.Referrer/UpdateTaskReward"
+	Referrer_GetLeaderBoard_FullMethodName        = "/referrer.v1.Referrer/GetLeaderBoard"
+	Referrer_GetUserRank_FullMethodName           = "/referrer.v1.Referrer/GetUserRank"
+	Referrer_GetNetworkLeaderBoard_FullMethodName = "/referrer.v1.Referrer/GetNetworkLeaderBoard"
+	Referrer_GetPeriodLeaderBoard_FullMethodName  = "/referrer.v1.Referrer/GetPeriodLeaderBoard"
+)
+
+// ReferrerClient is the client API for Referrer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Referrer все методы, кроме Register и Login, требуют метаданные authorization: Bearer <jwt>
+type ReferrerClient interface {
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	GetUserStatus(ctx context.Context, in *GetUserStatusRequest, opts ...grpc.CallOption) (*User, error)
+	CompleteTask(ctx context.Context, in *CompleteTaskRequest, opts ...grpc.CallOption) (*CompleteTaskResponse, error)
+	Referrer(ctx context.Context, in *ReferrerRequest, opts ...grpc.CallOption) (*ReferrerResponse, error)
+	GetAllTasks(ctx context.Context, in *GetAllTasksRequest, opts ...grpc.CallOption) (*TaskList, error)
+	GetTask(ctx context.Context, in *GetTaskRequest, opts ...grpc.CallOption) (*Task, error)
+	CreateTask(ctx context.Context, in *CreateTaskRequest, opts ...grpc.CallOption) (*CreateTaskResponse, error)
+	UpdateTaskReward(ctx context.Context, in *UpdateTaskRewardRequest, opts ...grpc.CallOption) (*UpdateTaskRewardResponse, error)
+	GetLeaderBoard(ctx context.Context, in *GetLeaderBoardRequest, opts ...grpc.CallOption) (*LeaderBoard, error)
+	GetUserRank(ctx context.Context, in *GetUserRankRequest, opts ...grpc.CallOption) (*UserRank, error)
+	GetNetworkLeaderBoard(ctx context.Context, in *GetNetworkLeaderBoardRequest, opts ...grpc.CallOption) (*NetworkLeaderBoard, error)
+	GetPeriodLeaderBoard(ctx context.Context, in *GetPeriodLeaderBoardRequest, opts ...grpc.CallOption) (*PeriodLeaderBoard, error)
+}
+
+type referrerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReferrerClient(cc grpc.ClientConnInterface) ReferrerClient {
+	return &referrerClient{cc}
+}
+
+func (c *referrerClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterResponse)
+	err := c.cc.Invoke(ctx, Referrer_Register_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referrerClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, Referrer_Login_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referrerClient) GetUserStatus(ctx context.Context, in *GetUserStatusRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, Referrer_GetUserStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referrerClient) CompleteTask(ctx context.Context, in *CompleteTaskRequest, opts ...grpc.CallOption) (*CompleteTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompleteTaskResponse)
+	err := c.cc.Invoke(ctx, Referrer_CompleteTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referrerClient) Referrer(ctx context.Context, in *ReferrerRequest, opts ...grpc.CallOption) (*ReferrerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReferrerResponse)
+	err := c.cc.Invoke(ctx, Referrer_Referrer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referrerClient) GetAllTasks(ctx context.Context, in *GetAllTasksRequest, opts ...grpc.CallOption) (*TaskList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskList)
+	err := c.cc.Invoke(ctx, Referrer_GetAllTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referrerClient) GetTask(ctx context.Context, in *GetTaskRequest, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, Referrer_GetTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referrerClient) CreateTask(ctx context.Context, in *CreateTaskRequest, opts ...grpc.CallOption) (*CreateTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateTaskResponse)
+	err := c.cc.Invoke(ctx, Referrer_CreateTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referrerClient) UpdateTaskReward(ctx context.Context, in *UpdateTaskRewardRequest, opts ...grpc.CallOption) (*UpdateTaskRewardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateTaskRewardResponse)
+	err := c.cc.Invoke(ctx, Referrer_UpdateTaskReward_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referrerClient) GetLeaderBoard(ctx context.Context, in *GetLeaderBoardRequest, opts ...grpc.CallOption) (*LeaderBoard, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaderBoard)
+	err := c.cc.Invoke(ctx, Referrer_GetLeaderBoard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referrerClient) GetUserRank(ctx context.Context, in *GetUserRankRequest, opts ...grpc.CallOption) (*UserRank, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserRank)
+	err := c.cc.Invoke(ctx, Referrer_GetUserRank_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referrerClient) GetNetworkLeaderBoard(ctx context.Context, in *GetNetworkLeaderBoardRequest, opts ...grpc.CallOption) (*NetworkLeaderBoard, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NetworkLeaderBoard)
+	err := c.cc.Invoke(ctx, Referrer_GetNetworkLeaderBoard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *referrerClient) GetPeriodLeaderBoard(ctx context.Context, in *GetPeriodLeaderBoardRequest, opts ...grpc.CallOption) (*PeriodLeaderBoard, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PeriodLeaderBoard)
+	err := c.cc.Invoke(ctx, Referrer_GetPeriodLeaderBoard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReferrerServer is the server API for Referrer service.
+// All implementations must embed UnimplementedReferrerServer
+// for forward compatibility.
+//
+// Referrer все методы, кроме Register и Login, требуют метаданные authorization: Bearer <jwt>
+type ReferrerServer interface {
+	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
+	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	GetUserStatus(context.Context, *GetUserStatusRequest) (*User, error)
+	CompleteTask(context.Context, *CompleteTaskRequest) (*CompleteTaskResponse, error)
+	Referrer(context.Context, *ReferrerRequest) (*ReferrerResponse, error)
+	GetAllTasks(context.Context, *GetAllTasksRequest) (*TaskList, error)
+	GetTask(context.Context, *GetTaskRequest) (*Task, error)
+	CreateTask(context.Context, *CreateTaskRequest) (*CreateTaskResponse, error)
+	UpdateTaskReward(context.Context, *UpdateTaskRewardRequest) (*UpdateTaskRewardResponse, error)
+	GetLeaderBoard(context.Context, *GetLeaderBoardRequest) (*LeaderBoard, error)
+	GetUserRank(context.Context, *GetUserRankRequest) (*UserRank, error)
+	GetNetworkLeaderBoard(context.Context, *GetNetworkLeaderBoardRequest) (*NetworkLeaderBoard, error)
+	GetPeriodLeaderBoard(context.Context, *GetPeriodLeaderBoardRequest) (*PeriodLeaderBoard, error)
+	mustEmbedUnimplementedReferrerServer()
+}
+
+// UnimplementedReferrerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReferrerServer struct{}
+
+func (UnimplementedReferrerServer) Register(context.Context, *RegisterRequest) (*RegisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedReferrerServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
+}
+func (UnimplementedReferrerServer) GetUserStatus(context.Context, *GetUserStatusRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserStatus not implemented")
+}
+func (UnimplementedReferrerServer) CompleteTask(context.Context, *CompleteTaskRequest) (*CompleteTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteTask not implemented")
+}
+func (UnimplementedReferrerServer) Referrer(context.Context, *ReferrerRequest) (*ReferrerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Referrer not implemented")
+}
+func (UnimplementedReferrerServer) GetAllTasks(context.Context, *GetAllTasksRequest) (*TaskList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllTasks not implemented")
+}
+func (UnimplementedReferrerServer) GetTask(context.Context, *GetTaskRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTask not implemented")
+}
+func (UnimplementedReferrerServer) CreateTask(context.Context, *CreateTaskRequest) (*CreateTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTask not implemented")
+}
+func (UnimplementedReferrerServer) UpdateTaskReward(context.Context, *UpdateTaskRewardRequest) (*UpdateTaskRewardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTaskReward not implemented")
+}
+func (UnimplementedReferrerServer) GetLeaderBoard(context.Context, *GetLeaderBoardRequest) (*LeaderBoard, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLeaderBoard not implemented")
+}
+func (UnimplementedReferrerServer) GetUserRank(context.Context, *GetUserRankRequest) (*UserRank, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserRank not implemented")
+}
+func (UnimplementedReferrerServer) GetNetworkLeaderBoard(context.Context, *GetNetworkLeaderBoardRequest) (*NetworkLeaderBoard, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNetworkLeaderBoard not implemented")
+}
+func (UnimplementedReferrerServer) GetPeriodLeaderBoard(context.Context, *GetPeriodLeaderBoardRequest) (*PeriodLeaderBoard, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPeriodLeaderBoard not implemented")
+}
+func (UnimplementedReferrerServer) mustEmbedUnimplementedReferrerServer() {}
+func (UnimplementedReferrerServer) testEmbeddedByValue()                  {}
+
+// UnsafeReferrerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReferrerServer will
+// result in compilation errors.
+type UnsafeReferrerServer interface {
+	mustEmbedUnimplementedReferrerServer()
+}
+
+func RegisterReferrerServer(s grpc.ServiceRegistrar, srv ReferrerServer) {
+	// If the following call pancis, it indicates UnimplementedReferrerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Referrer_ServiceDesc, srv)
+}
+
+func _Referrer_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferrerServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Referrer_Register_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferrerServer).Register(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Referrer_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferrerServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Referrer_Login_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferrerServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Referrer_GetUserStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferrerServer).GetUserStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Referrer_GetUserStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferrerServer).GetUserStatus(ctx, req.(*GetUserStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Referrer_CompleteTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferrerServer).CompleteTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Referrer_CompleteTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferrerServer).CompleteTask(ctx, req.(*CompleteTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Referrer_Referrer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReferrerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferrerServer).Referrer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Referrer_Referrer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferrerServer).Referrer(ctx, req.(*ReferrerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Referrer_GetAllTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAllTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferrerServer).GetAllTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Referrer_GetAllTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferrerServer).GetAllTasks(ctx, req.(*GetAllTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Referrer_GetTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferrerServer).GetTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Referrer_GetTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferrerServer).GetTask(ctx, req.(*GetTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Referrer_CreateTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferrerServer).CreateTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Referrer_CreateTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferrerServer).CreateTask(ctx, req.(*CreateTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Referrer_UpdateTaskReward_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTaskRewardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferrerServer).UpdateTaskReward(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Referrer_UpdateTaskReward_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferrerServer).UpdateTaskReward(ctx, req.(*UpdateTaskRewardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Referrer_GetLeaderBoard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLeaderBoardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferrerServer).GetLeaderBoard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Referrer_GetLeaderBoard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferrerServer).GetLeaderBoard(ctx, req.(*GetLeaderBoardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Referrer_GetUserRank_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRankRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferrerServer).GetUserRank(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Referrer_GetUserRank_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferrerServer).GetUserRank(ctx, req.(*GetUserRankRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Referrer_GetNetworkLeaderBoard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNetworkLeaderBoardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferrerServer).GetNetworkLeaderBoard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Referrer_GetNetworkLeaderBoard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferrerServer).GetNetworkLeaderBoard(ctx, req.(*GetNetworkLeaderBoardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Referrer_GetPeriodLeaderBoard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPeriodLeaderBoardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReferrerServer).GetPeriodLeaderBoard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Referrer_GetPeriodLeaderBoard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReferrerServer).GetPeriodLeaderBoard(ctx, req.(*GetPeriodLeaderBoardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Referrer_ServiceDesc is the grpc.ServiceDesc for Referrer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Referrer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "referrer.v1.Referrer",
+	HandlerType: (*ReferrerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler:    _Referrer_Register_Handler,
+		},
+		{
+			MethodName: "Login",
+			Handler:    _Referrer_Login_Handler,
+		},
+		{
+			MethodName: "GetUserStatus",
+			Handler:    _Referrer_GetUserStatus_Handler,
+		},
+		{
+			MethodName: "CompleteTask",
+			Handler:    _Referrer_CompleteTask_Handler,
+		},
+		{
+			MethodName: "Referrer",
+			Handler:    _Referrer_Referrer_Handler,
+		},
+		{
+			MethodName: "GetAllTasks",
+			Handler:    _Referrer_GetAllTasks_Handler,
+		},
+		{
+			MethodName: "GetTask",
+			Handler:    _Referrer_GetTask_Handler,
+		},
+		{
+			MethodName: "CreateTask",
+			Handler:    _Referrer_CreateTask_Handler,
+		},
+		{
+			MethodName: "UpdateTaskReward",
+			Handler:    _Referrer_UpdateTaskReward_Handler,
+		},
+		{
+			MethodName: "GetLeaderBoard",
+			Handler:    _Referrer_GetLeaderBoard_Handler,
+		},
+		{
+			MethodName: "GetUserRank",
+			Handler:    _Referrer_GetUserRank_Handler,
+		},
+		{
+			MethodName: "GetNetworkLeaderBoard",
+			Handler:    _Referrer_GetNetworkLeaderBoard_Handler,
+		},
+		{
+			MethodName: "GetPeriodLeaderBoard",
+			Handler:    _Referrer_GetPeriodLeaderBoard_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "referrer/v1/referrer.proto",
+}
@@ -0,0 +1,246 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/config"
+	"github.com/SakuraBurst/denet/internal/referrer/rpc/referrerpb"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+type controller interface {
+	CreateNewUser(ctx context.Context, user *types.UserRequest) error
+	AuthorizeUser(ctx context.Context, user *types.UserRequest) (string, error)
+	GetUserStatus(ctx context.Context, id int) (*types.FullUser, error)
+	CompleteTask(ctx context.Context, userID int, taskID int) (*types.Balance, error)
+	Referrer(ctx context.Context, id int, referrerCode string) error
+	CreateNewTask(ctx context.Context, task *types.Task) (int, error)
+	GetTask(ctx context.Context, id int) (*types.Task, error)
+	UpdateTaskReward(ctx context.Context, id int, newReward types.Amount) error
+	GetAllTasks(ctx context.Context) ([]*types.Task, error)
+	GetTopUsers(ctx context.Context, limit, offset int) (*types.LeaderBoard, error)
+	GetUserRank(ctx context.Context, id, neighbors int) (*types.UserRank, error)
+	GetNetworkLeaderBoard(ctx context.Context, id int) (*types.NetworkLeaderBoard, error)
+	GetPeriodLeaderBoard(ctx context.Context, period types.Period, at, from, to time.Time, limit, offset int) (*types.PeriodLeaderBoard, error)
+}
+
+// GrpcServer gRPC API поверх того же контроллера, что и HTTP API. Контроллер закрывает HttpRouter
+type GrpcServer struct {
+	referrerpb.UnimplementedReferrerServer
+	controller controller
+	server     *grpc.Server
+	appLogger  *zap.Logger
+	grpcPort   string
+	location   *time.Location
+}
+
+func (s *GrpcServer) Run() error {
+	listener, err := net.Listen("tcp", ":"+s.grpcPort)
+	if err != nil {
+		return errors.Wrap(err, "net.Listen failed: ")
+	}
+	return s.server.Serve(listener)
+}
+
+// Close дожидается завершения начатых вызовов и останавливает сервер
+func (s *GrpcServer) Close() {
+	s.server.GracefulStop()
+}
+
+func CreateServer(c controller, cfg *config.Config, logger *zap.Logger) *GrpcServer {
+	appLogger := logger.Named("grpc")
+	s := &GrpcServer{controller: c, appLogger: appLogger, grpcPort: cfg.GrpcPort, location: cfg.LeaderBoard.Location}
+	s.server = grpc.NewServer(grpc.ChainUnaryInterceptor(
+		s.recoveryInterceptor,
+		s.errorInterceptor,
+		authInterceptor([]byte(cfg.JWTSecret)),
+	))
+	referrerpb.RegisterReferrerServer(s.server, s)
+	return s
+}
+
+func (s *GrpcServer) Register(ctx context.Context, request *referrerpb.RegisterRequest) (*referrerpb.RegisterResponse, error) {
+	user := &types.UserRequest{
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		UserName:  request.UserName,
+		Password:  request.Password,
+	}
+	if err := validateRequest(user); err != nil {
+		return nil, err
+	}
+	if user.FirstName == "" {
+		user.FirstName = "Михал"
+	}
+	if user.LastName == "" {
+		user.LastName = "Палыч"
+	}
+	if err := s.controller.CreateNewUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return &referrerpb.RegisterResponse{}, nil
+}
+
+func (s *GrpcServer) Login(ctx context.Context, request *referrerpb.LoginRequest) (*referrerpb.LoginResponse, error) {
+	if err := validateRequest(&types.LoginRequest{UserName: request.UserName, Password: request.Password}); err != nil {
+		return nil, err
+	}
+	token, err := s.controller.AuthorizeUser(ctx, &types.UserRequest{UserName: request.UserName, Password: request.Password})
+	if err != nil {
+		return nil, loginError(err)
+	}
+	return &referrerpb.LoginResponse{Token: token}, nil
+}
+
+func (s *GrpcServer) GetUserStatus(ctx context.Context, request *referrerpb.GetUserStatusRequest) (*referrerpb.User, error) {
+	user, err := s.controller.GetUserStatus(ctx, int(request.UserId))
+	if err != nil {
+		return nil, err
+	}
+	return toUser(user), nil
+}
+
+func (s *GrpcServer) CompleteTask(ctx context.Context, request *referrerpb.CompleteTaskRequest) (*referrerpb.CompleteTaskResponse, error) {
+	if err := ownerOnly(ctx, request.UserId); err != nil {
+		return nil, err
+	}
+	if err := validateRequest(&types.CompleteTaskRequest{TaskId: int(request.TaskId)}); err != nil {
+		return nil, err
+	}
+	balance, err := s.controller.CompleteTask(ctx, int(request.UserId), int(request.TaskId))
+	if err != nil {
+		return nil, err
+	}
+	return &referrerpb.CompleteTaskResponse{Reward: balance.Amount.String(), Currency: balance.Currency}, nil
+}
+
+func (s *GrpcServer) Referrer(ctx context.Context, request *referrerpb.ReferrerRequest) (*referrerpb.ReferrerResponse, error) {
+	if err := ownerOnly(ctx, request.UserId); err != nil {
+		return nil, err
+	}
+	if err := validateRequest(&types.ReferrerRequest{ReferrerCode: request.ReferrerCode}); err != nil {
+		return nil, err
+	}
+	err := s.controller.Referrer(ctx, int(request.UserId), request.ReferrerCode)
+	if err != nil {
+		return nil, referrerError(err)
+	}
+	return &referrerpb.ReferrerResponse{}, nil
+}
+
+func (s *GrpcServer) GetAllTasks(ctx context.Context, _ *referrerpb.GetAllTasksRequest) (*referrerpb.TaskList, error) {
+	tasks, err := s.controller.GetAllTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &referrerpb.TaskList{Tasks: toTasks(tasks)}, nil
+}
+
+func (s *GrpcServer) GetTask(ctx context.Context, request *referrerpb.GetTaskRequest) (*referrerpb.Task, error) {
+	task, err := s.controller.GetTask(ctx, int(request.TaskId))
+	if err != nil {
+		return nil, err
+	}
+	return toTask(task), nil
+}
+
+func (s *GrpcServer) CreateTask(ctx context.Context, request *referrerpb.CreateTaskRequest) (*referrerpb.CreateTaskResponse, error) {
+	reward, err := parseAmount("reward", request.Reward)
+	if err != nil {
+		return nil, err
+	}
+	task := &types.Task{Description: request.Description, Reward: reward, Currency: request.Currency}
+	if err = validateRequest(task); err != nil {
+		return nil, err
+	}
+	id, err := s.controller.CreateNewTask(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	return &referrerpb.CreateTaskResponse{Id: int64(id)}, nil
+}
+
+func (s *GrpcServer) UpdateTaskReward(ctx context.Context, request *referrerpb.UpdateTaskRewardRequest) (*referrerpb.UpdateTaskRewardResponse, error) {
+	reward, err := parseAmount("reward", request.Reward)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateRequest(&types.UpdateTaskRewardRequest{Reward: reward}); err != nil {
+		return nil, err
+	}
+	err = s.controller.UpdateTaskReward(ctx, int(request.TaskId), reward)
+	if err != nil {
+		return nil, err
+	}
+	return &referrerpb.UpdateTaskRewardResponse{}, nil
+}
+
+func (s *GrpcServer) GetLeaderBoard(ctx context.Context, request *referrerpb.GetLeaderBoardRequest) (*referrerpb.LeaderBoard, error) {
+	leaderBoard, err := s.controller.GetTopUsers(ctx, int(request.Limit), int(request.Offset))
+	if err != nil {
+		return nil, err
+	}
+	return &referrerpb.LeaderBoard{
+		Users:  toRankedUsers(leaderBoard.Users),
+		Total:  int64(leaderBoard.Total),
+		Limit:  int64(leaderBoard.Limit),
+		Offset: int64(leaderBoard.Offset),
+	}, nil
+}
+
+func (s *GrpcServer) GetUserRank(ctx context.Context, request *referrerpb.GetUserRankRequest) (*referrerpb.UserRank, error) {
+	neighbors := -1
+	if request.Neighbors != nil {
+		neighbors = int(*request.Neighbors)
+	}
+	rank, err := s.controller.GetUserRank(ctx, int(request.UserId), neighbors)
+	if err != nil {
+		return nil, err
+	}
+	return &referrerpb.UserRank{
+		User:  toRankedUser(rank.User),
+		Above: toRankedUsers(rank.Above),
+		Below: toRankedUsers(rank.Below),
+	}, nil
+}
+
+func (s *GrpcServer) GetNetworkLeaderBoard(ctx context.Context, request *referrerpb.GetNetworkLeaderBoardRequest) (*referrerpb.NetworkLeaderBoard, error) {
+	leaderBoard, err := s.controller.GetNetworkLeaderBoard(ctx, int(request.UserId))
+	if err != nil {
+		return nil, err
+	}
+	result := &referrerpb.NetworkLeaderBoard{User: toNetworkRankedUser(leaderBoard.User)}
+	for _, user := range leaderBoard.Users {
+		result.Users = append(result.Users, toNetworkRankedUser(user))
+	}
+	return result, nil
+}
+
+func (s *GrpcServer) GetPeriodLeaderBoard(ctx context.Context, request *referrerpb.GetPeriodLeaderBoardRequest) (*referrerpb.PeriodLeaderBoard, error) {
+	period := types.Period(request.Period)
+	if period == "" {
+		period = types.PeriodAllTime
+	}
+	at, err := parseTime("date", request.Date, time.Now(), s.location)
+	if err != nil {
+		return nil, err
+	}
+	from, err := parseTime("from", request.From, time.Time{}, s.location)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTime("to", request.To, time.Now(), s.location)
+	if err != nil {
+		return nil, err
+	}
+	leaderBoard, err := s.controller.GetPeriodLeaderBoard(ctx, period, at, from, to, int(request.Limit), int(request.Offset))
+	if err != nil {
+		return nil, err
+	}
+	return toPeriodLeaderBoard(leaderBoard), nil
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+)
+
+type achievementDatabase interface {
+	GetAchievements(ctx context.Context) ([]*types.Achievement, error)
+}
+
+func (c *Controller) GetAchievements(ctx context.Context) ([]*types.Achievement, error) {
+	achievements, err := c.achievementDatabase.GetAchievements(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "achievementDatabase.GetAchievements failed: ")
+	}
+	return achievements, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+)
+
+// пакетные выборки для загрузчиков GraphQL, id без результата в ответе отсутствуют
+
+func (c *Controller) GetUsersByIDs(ctx context.Context, userIDs []int) ([]*types.User, error) {
+	users, err := c.userDatabase.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "userDatabase.GetUsersByIDs failed: ")
+	}
+	return users, nil
+}
+
+func (c *Controller) GetRefereesByUserIDs(ctx context.Context, userIDs []int) ([]*types.User, error) {
+	users, err := c.userDatabase.GetRefereesByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "userDatabase.GetRefereesByUserIDs failed: ")
+	}
+	return users, nil
+}
+
+func (c *Controller) GetUserRanks(ctx context.Context, userIDs []int) ([]*types.RankedUser, error) {
+	ranks, err := c.userDatabase.GetUserRanks(ctx, userIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "userDatabase.GetUserRanks failed: ")
+	}
+	return ranks, nil
+}
+
+func (c *Controller) GetCompletionsByUserIDs(ctx context.Context, userIDs []int) ([]*types.Completion, error) {
+	completions, err := c.taskToUserDatabase.GetCompletionsByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "taskToUserDatabase.GetCompletionsByUserIDs failed: ")
+	}
+	return completions, nil
+}
+
+func (c *Controller) GetTasksByIDs(ctx context.Context, taskIDs []int) ([]*types.Task, error) {
+	tasks, err := c.taskDataBase.GetTasksByIDs(ctx, taskIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "taskDataBase.GetTasksByIDs failed: ")
+	}
+	return tasks, nil
+}
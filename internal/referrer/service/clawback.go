@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+)
+
+type clawbackDatabase interface {
+	RevokeCompletion(ctx context.Context, userID, taskID int, reason string, allowNegative bool) (*types.Clawback, error)
+	RevokeReferral(ctx context.Context, refereeID int, reason string, allowNegative bool, fallbackReward types.Amount) ([]*types.Clawback, error)
+	GetClawbacks(ctx context.Context, userID int) ([]*types.Clawback, error)
+}
+
+type notificationDatabase interface {
+	GetNotifications(ctx context.Context, userID int) ([]*types.Notification, error)
+	MarkNotificationsRead(ctx context.Context, userID int) error
+}
+
+// RevokeCompletion отзывает награду за задание, если пользователь ее уже потратил - поступает по политике clawback
+func (c *Controller) RevokeCompletion(ctx context.Context, userID, taskID int, reason string) (*types.Clawback, error) {
+	clawback, err := c.clawbackDatabase.RevokeCompletion(ctx, userID, taskID, reason, c.clawback.AllowNegativeBalance)
+	if err != nil {
+		return nil, errors.Wrap(err, "clawbackDatabase.RevokeCompletion failed: ")
+	}
+	c.leaderBoardCache.Invalidate()
+	return clawback, nil
+}
+
+// RevokeReferral отзывает награды за реферальный код у реферала и у его реферера
+func (c *Controller) RevokeReferral(ctx context.Context, refereeID int, reason string) ([]*types.Clawback, error) {
+	clawbacks, err := c.clawbackDatabase.RevokeReferral(ctx, refereeID, reason, c.clawback.AllowNegativeBalance, defaultRefererReward)
+	if err != nil {
+		return nil, errors.Wrap(err, "clawbackDatabase.RevokeReferral failed: ")
+	}
+	c.leaderBoardCache.Invalidate()
+	return clawbacks, nil
+}
+
+// GetClawbacks возвращает отзывы начислений пользователя, userID == 0 - всех пользователей
+func (c *Controller) GetClawbacks(ctx context.Context, userID int) ([]*types.Clawback, error) {
+	clawbacks, err := c.clawbackDatabase.GetClawbacks(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "clawbackDatabase.GetClawbacks failed: ")
+	}
+	return clawbacks, nil
+}
+
+func (c *Controller) GetNotifications(ctx context.Context, userID int) ([]*types.Notification, error) {
+	notifications, err := c.notificationDatabase.GetNotifications(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "notificationDatabase.GetNotifications failed: ")
+	}
+	return notifications, nil
+}
+
+func (c *Controller) MarkNotificationsRead(ctx context.Context, userID int) error {
+	err := c.notificationDatabase.MarkNotificationsRead(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "notificationDatabase.MarkNotificationsRead failed: ")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+
+	"github.com/SakuraBurst/denet/internal/referrer/events"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+)
+
+type eventBus interface {
+	Publish(events ...*types.Event)
+	Deliver(events ...*types.Event)
+	HasSubscribers() bool
+	Subscribe(userID int) *events.Subscription
+}
+
+// Subscribe подписывает на события пользователя userID, подписку нужно закрыть
+func (c *Controller) Subscribe(userID int) *events.Subscription {
+	return c.events.Subscribe(userID)
+}
+
+// RefreshRanks перечитывает кэш таблицы лидеров, чтобы подписчики узнали о сдвигах мест, в том числе
+// от начислений на других экземплярах. Без подписчиков на этом экземпляре ничего не делает
+func (c *Controller) RefreshRanks(ctx context.Context) error {
+	if !c.events.HasSubscribers() {
+		return nil
+	}
+	return c.leaderBoardCache.Refresh(ctx)
+}
+
+// publishRankChanges вызывается после каждого перечитывания кэша и отправляет подписчикам этого экземпляра
+// сдвиги мест между двумя версиями верха таблицы: и тем, кто поднялся, и тем, кого обошли. Каждый экземпляр
+// считает их по своему кэшу сам, поэтому события не пересылаются остальным
+func (c *Controller) publishRankChanges(before, after []*types.RankedUser) {
+	if before == nil || !c.events.HasSubscribers() {
+		return
+	}
+	if events := rankChanges(before, after); len(events) > 0 {
+		c.events.Deliver(events...)
+	}
+}
+
+// rankChanges события для пользователей из after, чья позиция изменилась по сравнению с before. Кто раньше
+// не входил в before, получает PreviousPosition 0, выбывшие из after событий не получают: их места неизвестны
+func rankChanges(before, after []*types.RankedUser) []*types.Event {
+	previous := make(map[int]int, len(before))
+	for _, user := range before {
+		previous[user.ID] = user.Position
+	}
+	var result []*types.Event
+	for _, current := range after {
+		if previous[current.ID] == current.Position {
+			continue
+		}
+		result = append(result, types.NewEvent(types.EventRankChanged, current.ID, &types.RankChangedEvent{
+			Rank:             current.Rank,
+			DenseRank:        current.DenseRank,
+			Position:         current.Position,
+			PreviousPosition: previous[current.ID],
+		}))
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+)
+
+func TestRankChangesNotifiesDisplacedUsers(t *testing.T) {
+	ranked := func(ids ...int) []*types.RankedUser {
+		users := make([]*types.RankedUser, len(ids))
+		for i, id := range ids {
+			users[i] = &types.RankedUser{ID: id, Rank: i + 1, DenseRank: i + 1, Position: i + 1}
+		}
+		return users
+	}
+	// 4 обошел 2 и 3, 5 вошел в верх вместо выбывшего 6
+	before := ranked(1, 2, 3, 4, 6)
+	after := ranked(1, 4, 2, 3, 5)
+
+	want := map[int][2]int{4: {2, 4}, 2: {3, 2}, 3: {4, 3}, 5: {5, 0}}
+	events := rankChanges(before, after)
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for _, event := range events {
+		data, ok := event.Data.(*types.RankChangedEvent)
+		if !ok || event.Kind != types.EventRankChanged {
+			t.Fatalf("unexpected event %+v", event)
+		}
+		positions, ok := want[event.UserID]
+		if !ok {
+			t.Errorf("unexpected event for user %d", event.UserID)
+			continue
+		}
+		if data.Position != positions[0] || data.PreviousPosition != positions[1] {
+			t.Errorf("user %d: position %d from %d, want %d from %d", event.UserID, data.Position, data.PreviousPosition, positions[0], positions[1])
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+)
+
+// сколько пользователей обрабатывать за один проход
+const expirationBatchSize = 1000
+
+type expirationDatabase interface {
+	GetUsersWithExpiredLots(ctx context.Context, now time.Time, limit int) ([]int, error)
+	ExpireLots(ctx context.Context, userID int, now time.Time) (types.Amount, error)
+	GetUpcomingExpirations(ctx context.Context, userID int, before time.Time) ([]*types.Expiration, error)
+	ScheduleLegacyLots(ctx context.Context) error
+}
+
+// ExpirePoints сжигает баллы, срок которых вышел к моменту now. Перед этим назначает срок партиям из старых балансов
+func (c *Controller) ExpirePoints(ctx context.Context, now time.Time) error {
+	if err := c.expirationDatabase.ScheduleLegacyLots(ctx); err != nil {
+		return errors.Wrap(err, "expirationDatabase.ScheduleLegacyLots failed: ")
+	}
+	for {
+		users, err := c.expirationDatabase.GetUsersWithExpiredLots(ctx, now, expirationBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "expirationDatabase.GetUsersWithExpiredLots failed: ")
+		}
+		for _, id := range users {
+			if _, err = c.expirationDatabase.ExpireLots(ctx, id, now); err != nil {
+				return errors.Wrap(err, "expirationDatabase.ExpireLots failed: ")
+			}
+		}
+		if len(users) > 0 {
+			c.leaderBoardCache.Invalidate()
+		}
+		if len(users) < expirationBatchSize {
+			return nil
+		}
+	}
+}
+
+func (c *Controller) getUpcomingExpirations(ctx context.Context, userID int) ([]*types.Expiration, error) {
+	expirations, err := c.expirationDatabase.GetUpcomingExpirations(ctx, userID, time.Now().Add(c.expiration.NoticePeriod))
+	if err != nil {
+		return nil, errors.Wrap(err, "expirationDatabase.GetUpcomingExpirations failed: ")
+	}
+	return expirations, nil
+}
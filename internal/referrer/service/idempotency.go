@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+)
+
+type idempotencyDatabase interface {
+	StartIdempotentRequest(ctx context.Context, scope, key, fingerprint string, expiresAt time.Time) (*types.IdempotentResponse, error)
+	FinishIdempotentRequest(ctx context.Context, scope, key string, response *types.IdempotentResponse) error
+	ReleaseIdempotentRequest(ctx context.Context, scope, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+// StartIdempotentRequest возвращает сохраненный ответ, если запрос с таким ключом уже выполнялся, иначе резервирует ключ на ttl
+func (c *Controller) StartIdempotentRequest(ctx context.Context, scope, key, fingerprint string) (*types.IdempotentResponse, error) {
+	response, err := c.idempotencyDatabase.StartIdempotentRequest(ctx, scope, key, fingerprint, time.Now().Add(c.idempotency.TTL))
+	if err != nil {
+		return nil, errors.Wrap(err, "idempotencyDatabase.StartIdempotentRequest failed: ")
+	}
+	return response, nil
+}
+
+func (c *Controller) FinishIdempotentRequest(ctx context.Context, scope, key string, response *types.IdempotentResponse) error {
+	err := c.idempotencyDatabase.FinishIdempotentRequest(ctx, scope, key, response)
+	if err != nil {
+		return errors.Wrap(err, "idempotencyDatabase.FinishIdempotentRequest failed: ")
+	}
+	return nil
+}
+
+func (c *Controller) ReleaseIdempotentRequest(ctx context.Context, scope, key string) error {
+	err := c.idempotencyDatabase.ReleaseIdempotentRequest(ctx, scope, key)
+	if err != nil {
+		return errors.Wrap(err, "idempotencyDatabase.ReleaseIdempotentRequest failed: ")
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys удаляет ключи, срок жизни которых вышел
+func (c *Controller) DeleteExpiredIdempotencyKeys(ctx context.Context) error {
+	_, err := c.idempotencyDatabase.DeleteExpiredIdempotencyKeys(ctx, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "idempotencyDatabase.DeleteExpiredIdempotencyKeys failed: ")
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/config"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+)
+
+type leaderBoardLoader func(ctx context.Context, limit, offset int) ([]*types.RankedUser, int, error)
+
+// leaderBoardRefreshed получает прошлую и новую версии кэша, прошлая nil при первой загрузке
+type leaderBoardRefreshed func(before, after []*types.RankedUser)
+
+// leaderBoardCache держит в памяти верх таблицы лидеров, чтобы не ходить в базу на каждый запрос.
+// Кэш перечитывается, когда устарел (ttl) или когда его пометили грязным после начисления,
+// но не чаще чем раз в minRefresh
+type leaderBoardCache struct {
+	size       int
+	ttl        time.Duration
+	minRefresh time.Duration
+	load       leaderBoardLoader
+	refreshed  leaderBoardRefreshed
+
+	mu       sync.RWMutex
+	users    []*types.RankedUser
+	total    int
+	loadedAt time.Time
+	dirty    bool
+
+	// refreshMu не дает нескольким запросам одновременно перечитывать кэш
+	refreshMu sync.Mutex
+}
+
+func newLeaderBoardCache(cfg config.LeaderBoardConfig, load leaderBoardLoader, refreshed leaderBoardRefreshed) *leaderBoardCache {
+	return &leaderBoardCache{
+		size:       cfg.CacheSize,
+		ttl:        cfg.CacheTTL,
+		minRefresh: cfg.CacheMinRefresh,
+		load:       load,
+		refreshed:  refreshed,
+	}
+}
+
+// Invalidate помечает кэш устаревшим, следующий запрос перечитает его
+func (l *leaderBoardCache) Invalidate() {
+	l.mu.Lock()
+	l.dirty = true
+	l.mu.Unlock()
+}
+
+// Refresh перечитывает кэш из базы
+func (l *leaderBoardCache) Refresh(ctx context.Context) error {
+	l.refreshMu.Lock()
+	defer l.refreshMu.Unlock()
+	return l.refresh(ctx)
+}
+
+func (l *leaderBoardCache) refresh(ctx context.Context) error {
+	users, total, err := l.load(ctx, l.size, 0)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	before := l.users
+	l.users, l.total, l.loadedAt, l.dirty = users, total, time.Now(), false
+	l.mu.Unlock()
+	// под refreshMu, поэтому версии приходят по порядку
+	if l.refreshed != nil {
+		l.refreshed(before, users)
+	}
+	return nil
+}
+
+func (l *leaderBoardCache) stale(now time.Time) bool {
+	age := now.Sub(l.loadedAt)
+	return l.loadedAt.IsZero() || age >= l.ttl || (l.dirty && age >= l.minRefresh)
+}
+
+// Page возвращает страницу из кэша, ok == false если страница в кэш не помещается
+func (l *leaderBoardCache) Page(ctx context.Context, limit, offset int) (users []*types.RankedUser, total int, ok bool, err error) {
+	if l.size <= 0 || offset+limit > l.size {
+		return nil, 0, false, nil
+	}
+	l.mu.RLock()
+	stale := l.stale(time.Now())
+	l.mu.RUnlock()
+	if stale {
+		l.refreshMu.Lock()
+		// пока ждали блокировку, кэш мог перечитать другой запрос
+		l.mu.RLock()
+		stale = l.stale(time.Now())
+		l.mu.RUnlock()
+		if stale {
+			err = l.refresh(ctx)
+		}
+		l.refreshMu.Unlock()
+		if err != nil {
+			return nil, 0, false, err
+		}
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if offset >= len(l.users) {
+		return []*types.RankedUser{}, l.total, true, nil
+	}
+	end := min(offset+limit, len(l.users))
+	return l.users[offset:end], l.total, true, nil
+}
+
+// GetTopUsers возвращает страницу таблицы лидеров, limit <= 0 означает лимит по умолчанию
+func (c *Controller) GetTopUsers(ctx context.Context, limit, offset int) (*types.LeaderBoard, error) {
+	limit, offset = c.pageBounds(limit, offset)
+	users, total, ok, err := c.leaderBoardCache.Page(ctx, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "leaderBoardCache.Page failed: ")
+	}
+	if !ok {
+		users, total, err = c.userDatabase.GetLeaderBoard(ctx, limit, offset)
+		if err != nil {
+			return nil, errors.Wrap(err, "userDatabase.GetLeaderBoard failed: ")
+		}
+	}
+	return &types.LeaderBoard{Users: users, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// RefreshLeaderBoard перечитывает кэш таблицы лидеров
+func (c *Controller) RefreshLeaderBoard(ctx context.Context) error {
+	return c.leaderBoardCache.Refresh(ctx)
+}
+
+// pageBounds приводит limit и offset к допустимым значениям
+func (c *Controller) pageBounds(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = c.leaderBoard.DefaultLimit
+	}
+	if limit > c.leaderBoard.MaxLimit {
+		limit = c.leaderBoard.MaxLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// GetUserRank возвращает место пользователя в таблице лидеров и его соседей, neighbors < 0 означает значение по умолчанию
+func (c *Controller) GetUserRank(ctx context.Context, id, neighbors int) (*types.UserRank, error) {
+	if neighbors < 0 {
+		neighbors = c.leaderBoard.DefaultNeighbors
+	}
+	if neighbors > c.leaderBoard.MaxLimit {
+		neighbors = c.leaderBoard.MaxLimit
+	}
+	users, err := c.userDatabase.GetUserRank(ctx, id, neighbors)
+	if err != nil {
+		return nil, errors.Wrap(err, "userDatabase.GetUserRank failed: ")
+	}
+	rank := &types.UserRank{Above: []*types.RankedUser{}, Below: []*types.RankedUser{}}
+	for _, u := range users {
+		switch {
+		case u.ID == id:
+			rank.User = u
+		case rank.User == nil:
+			rank.Above = append(rank.Above, u)
+		default:
+			rank.Below = append(rank.Below, u)
+		}
+	}
+	return rank, nil
+}
+
+// GetNetworkLeaderBoard возвращает таблицу лидеров среди реферера пользователя, его рефералов и рефералов его реферера
+func (c *Controller) GetNetworkLeaderBoard(ctx context.Context, id int) (*types.NetworkLeaderBoard, error) {
+	users, err := c.userDatabase.GetNetworkLeaderBoard(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "userDatabase.GetNetworkLeaderBoard failed: ")
+	}
+	result := &types.NetworkLeaderBoard{Users: users}
+	for _, u := range users {
+		if u.Relation == types.RelationSelf {
+			result.User = u
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/config"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+)
+
+// benchDatabaseRoundTrip сколько в среднем занимает запрос окна таблицы лидеров к Postgres по сети
+const benchDatabaseRoundTrip = 500 * time.Microsecond
+
+// benchLeaderBoardDatabase отдает страницы из заранее отсортированной таблицы, каждый запрос стоит
+// benchDatabaseRoundTrip, как поход в базу
+type benchLeaderBoardDatabase struct {
+	userDatabase
+
+	users   []*types.RankedUser
+	queries atomic.Int64
+}
+
+func newBenchLeaderBoardDatabase(size int) *benchLeaderBoardDatabase {
+	db := &benchLeaderBoardDatabase{users: make([]*types.RankedUser, size)}
+	for i := range db.users {
+		db.users[i] = &types.RankedUser{
+			ID: i + 1, UserName: "user" + strconv.Itoa(i+1), Balance: types.NewAmount(int64(size - i)), Rank: i + 1, DenseRank: i + 1, Position: i + 1,
+		}
+	}
+	return db
+}
+
+func (d *benchLeaderBoardDatabase) GetLeaderBoard(ctx context.Context, limit, offset int) ([]*types.RankedUser, int, error) {
+	d.queries.Add(1)
+	time.Sleep(benchDatabaseRoundTrip)
+	offset = min(offset, len(d.users))
+	end := min(offset+limit, len(d.users))
+	return append([]*types.RankedUser(nil), d.users[offset:end]...), len(d.users), nil
+}
+
+func newBenchLeaderBoardController(db *benchLeaderBoardDatabase, cacheSize int) *Controller {
+	cfg := config.LeaderBoardConfig{
+		DefaultLimit:    5,
+		MaxLimit:        100,
+		CacheSize:       cacheSize,
+		CacheTTL:        30 * time.Second,
+		CacheMinRefresh: time.Millisecond,
+	}
+	return &Controller{
+		userDatabase:     db,
+		leaderBoard:      cfg,
+		leaderBoardCache: newLeaderBoardCache(cfg, db.GetLeaderBoard, nil),
+	}
+}
+
+// BenchmarkGetTopUsers сравнивает страницы таблицы лидеров из кэша и из базы при параллельных запросах.
+// invalidated - кэш, который помечают грязным через каждые 100 запросов, как при постоянных начислениях
+func BenchmarkGetTopUsers(b *testing.B) {
+	for _, bench := range []struct {
+		name       string
+		cacheSize  int
+		invalidate int64
+	}{
+		{name: "database", cacheSize: 0},
+		{name: "cache", cacheSize: 1000},
+		{name: "cache_invalidated", cacheSize: 1000, invalidate: 100},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			db := newBenchLeaderBoardDatabase(10000)
+			c := newBenchLeaderBoardController(db, bench.cacheSize)
+			var requests atomic.Int64
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					n := requests.Add(1)
+					if bench.invalidate > 0 && n%bench.invalidate == 0 {
+						c.leaderBoardCache.Invalidate()
+					}
+					if _, err := c.GetTopUsers(context.Background(), 20, int(n%40)*20); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.ReportMetric(float64(db.queries.Load())/float64(b.N), "queries/op")
+		})
+	}
+}
+
+func TestGetTopUsersFromCacheMatchesDatabase(t *testing.T) {
+	db := newBenchLeaderBoardDatabase(100)
+	cached := newBenchLeaderBoardController(db, 50)
+	direct := newBenchLeaderBoardController(db, 0)
+	for _, page := range []struct{ limit, offset int }{{5, 0}, {20, 30}, {10, 45}} {
+		want, err := direct.GetTopUsers(context.Background(), page.limit, page.offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := cached.GetTopUsers(context.Background(), page.limit, page.offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Total != want.Total || len(got.Users) != len(want.Users) {
+			t.Fatalf("limit %d offset %d: got %d of %d users, want %d of %d", page.limit, page.offset, len(got.Users), got.Total, len(want.Users), want.Total)
+		}
+		for i := range want.Users {
+			if got.Users[i].ID != want.Users[i].ID {
+				t.Errorf("limit %d offset %d: position %d is user %d, want %d", page.limit, page.offset, i, got.Users[i].ID, want.Users[i].ID)
+			}
+		}
+	}
+	// вторая страница из кэша не ходит в базу
+	before := db.queries.Load()
+	if _, err := cached.GetTopUsers(context.Background(), 5, 5); err != nil {
+		t.Fatal(err)
+	}
+	if db.queries.Load() != before {
+		t.Errorf("cached page queried the database")
+	}
+}
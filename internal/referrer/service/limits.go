@@ -0,0 +1,58 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/config"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+)
+
+func newEarningPolicy(cfg config.LimitsConfig, levels []config.LevelConfig) types.EarningPolicy {
+	policy := types.EarningPolicy{
+		Default: earningLimits(cfg.Default),
+		Roles:   make(map[string]types.EarningLimits, len(cfg.Roles)),
+		Levels:  newLevels(levels),
+	}
+	for role, limits := range cfg.Roles {
+		policy.Roles[role] = earningLimits(limits)
+	}
+	return policy
+}
+
+// newLevels сортирует уровни по опыту и нумерует с единицы, первый уровень всегда дается с нуля
+func newLevels(cfg []config.LevelConfig) types.Levels {
+	levels := make(types.Levels, 0, len(cfg))
+	for _, l := range cfg {
+		levels = append(levels, types.Level{
+			XP:              types.NewAmount(int64(l.XP)),
+			RewardPercent:   l.RewardPercent,
+			ReferralPercent: l.ReferralPercent,
+		})
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].XP < levels[j].XP })
+	for i := range levels {
+		levels[i].Number = i + 1
+	}
+	if len(levels) > 0 {
+		levels[0].XP = 0
+	}
+	return levels
+}
+
+func earningLimits(cfg config.EarningLimitsConfig) types.EarningLimits {
+	return types.EarningLimits{
+		MaxDailyReward:        types.NewAmount(int64(cfg.MaxDailyReward)),
+		MaxCompletionsPerHour: cfg.MaxCompletionsPerHour,
+		Cooldown:              cfg.Cooldown,
+	}
+}
+
+// currentEarningPolicy возвращает ограничения на заработок с границами текущего дня
+func (c *Controller) currentEarningPolicy() (types.EarningPolicy, error) {
+	policy := c.earningPolicy
+	policy.Now = time.Now()
+	var err error
+	policy.DayStart, policy.DayEnd, err = periodBounds(types.PeriodDaily, policy.Now, c.leaderBoard.Location)
+	return policy, err
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+)
+
+var ErrUnknownPeriod = errors.New("unknown period")
+var ErrInvalidPeriodRange = errors.New("invalid period range")
+
+// закрытые периоды, итоги которых фиксируются
+var snapshotPeriods = []types.Period{types.PeriodDaily, types.PeriodWeekly, types.PeriodMonthly}
+
+type leaderBoardDatabase interface {
+	GetPeriodLeaderBoard(ctx context.Context, from, to time.Time, limit, offset int) ([]*types.PeriodRankedUser, int, error)
+	GetLeaderBoardSnapshot(ctx context.Context, period types.Period, from time.Time, limit, offset int) ([]*types.PeriodRankedUser, int, error)
+	SnapshotLeaderBoard(ctx context.Context, period types.Period, from, to time.Time) (int64, error)
+	GetSnapshotBackfillStart(ctx context.Context, period types.Period) (*time.Time, error)
+}
+
+// periodBounds возвращает границы [from, to) периода, в который попадает at. Неделя начинается с понедельника
+func periodBounds(period types.Period, at time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	at = at.In(loc)
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, loc)
+	switch period {
+	case types.PeriodDaily:
+		return day, day.AddDate(0, 0, 1), nil
+	case types.PeriodWeekly:
+		monday := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return monday, monday.AddDate(0, 0, 7), nil
+	case types.PeriodMonthly:
+		first := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, loc)
+		return first, first.AddDate(0, 1, 0), nil
+	}
+	return time.Time{}, time.Time{}, ErrUnknownPeriod
+}
+
+// GetPeriodLeaderBoard возвращает таблицу лидеров по заработку за период, в который попадает at.
+// Для custom используются from и to. Если период уже закрыт и его итоги зафиксированы, отдаются они
+func (c *Controller) GetPeriodLeaderBoard(ctx context.Context, period types.Period, at, from, to time.Time, limit, offset int) (*types.PeriodLeaderBoard, error) {
+	limit, offset = c.pageBounds(limit, offset)
+	if period == types.PeriodAllTime {
+		return c.allTimeLeaderBoard(ctx, limit, offset)
+	}
+	if period != types.PeriodCustom {
+		var err error
+		from, to, err = periodBounds(period, at, c.leaderBoard.Location)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriodRange
+	}
+	result := &types.PeriodLeaderBoard{Period: period, From: &from, To: &to, Limit: limit, Offset: offset}
+
+	if period != types.PeriodCustom && !to.After(time.Now()) {
+		users, total, err := c.leaderBoardDatabase.GetLeaderBoardSnapshot(ctx, period, from, limit, offset)
+		if err != nil {
+			return nil, errors.Wrap(err, "leaderBoardDatabase.GetLeaderBoardSnapshot failed: ")
+		}
+		if total > 0 {
+			result.Users, result.Total, result.Final = users, total, true
+			return result, nil
+		}
+	}
+
+	users, total, err := c.leaderBoardDatabase.GetPeriodLeaderBoard(ctx, from, to, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "leaderBoardDatabase.GetPeriodLeaderBoard failed: ")
+	}
+	result.Users, result.Total = users, total
+	return result, nil
+}
+
+// allTimeLeaderBoard ранжирует, как и остальные периоды, по заработанному из журнала начислений, только без границ.
+// Баланс для этого не годится: он уменьшается от покупок, переводов, отзывов и сгорания
+func (c *Controller) allTimeLeaderBoard(ctx context.Context, limit, offset int) (*types.PeriodLeaderBoard, error) {
+	users, total, err := c.leaderBoardDatabase.GetPeriodLeaderBoard(ctx, time.Time{}, time.Time{}, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "leaderBoardDatabase.GetPeriodLeaderBoard failed: ")
+	}
+	return &types.PeriodLeaderBoard{Period: types.PeriodAllTime, Users: users, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// SnapshotClosedPeriods фиксирует итоги всех закончившихся дней, недель и месяцев, начиная с периода первого
+// начисления после последнего снимка, так что периоды, пропущенные пока сервис не работал, тоже фиксируются
+func (c *Controller) SnapshotClosedPeriods(ctx context.Context, now time.Time) error {
+	for _, period := range snapshotPeriods {
+		current, _, err := periodBounds(period, now, c.leaderBoard.Location)
+		if err != nil {
+			return err
+		}
+		start, err := c.leaderBoardDatabase.GetSnapshotBackfillStart(ctx, period)
+		if err != nil {
+			return errors.Wrap(err, "leaderBoardDatabase.GetSnapshotBackfillStart failed: ")
+		}
+		if start == nil {
+			continue
+		}
+		from, to, err := periodBounds(period, *start, c.leaderBoard.Location)
+		if err != nil {
+			return err
+		}
+		for !to.After(current) {
+			_, err = c.leaderBoardDatabase.SnapshotLeaderBoard(ctx, period, from, to)
+			if err != nil {
+				return errors.Wrap(err, "leaderBoardDatabase.SnapshotLeaderBoard failed: ")
+			}
+			// to - начало следующего периода
+			from, to, err = periodBounds(period, to, c.leaderBoard.Location)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
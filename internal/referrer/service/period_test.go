@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/config"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+)
+
+// snapshotDatabase запоминает зафиксированные периоды, последнего снимка нет, начисления начинаются со start
+type snapshotDatabase struct {
+	leaderBoardDatabase
+
+	start     *time.Time
+	snapshots map[types.Period][][2]time.Time
+}
+
+func (d *snapshotDatabase) GetSnapshotBackfillStart(ctx context.Context, period types.Period) (*time.Time, error) {
+	return d.start, nil
+}
+
+func (d *snapshotDatabase) SnapshotLeaderBoard(ctx context.Context, period types.Period, from, to time.Time) (int64, error) {
+	d.snapshots[period] = append(d.snapshots[period], [2]time.Time{from, to})
+	return 0, nil
+}
+
+// earnedDatabase отдает таблицу по заработку и запоминает запрошенные границы
+type earnedDatabase struct {
+	leaderBoardDatabase
+
+	from, to time.Time
+	users    []*types.PeriodRankedUser
+}
+
+func (d *earnedDatabase) GetPeriodLeaderBoard(ctx context.Context, from, to time.Time, limit, offset int) ([]*types.PeriodRankedUser, int, error) {
+	d.from, d.to = from, to
+	return d.users, len(d.users), nil
+}
+
+func TestAllTimeLeaderBoardRanksByEarned(t *testing.T) {
+	// пользователь 1 заработал больше, но потратил баланс, он все равно первый
+	db := &earnedDatabase{users: []*types.PeriodRankedUser{
+		{RankedUser: types.RankedUser{ID: 1, Balance: types.NewAmount(5), Position: 1}, Earned: types.NewAmount(100)},
+		{RankedUser: types.RankedUser{ID: 2, Balance: types.NewAmount(50), Position: 2}, Earned: types.NewAmount(50)},
+	}}
+	c := &Controller{leaderBoardDatabase: db, leaderBoard: config.LeaderBoardConfig{DefaultLimit: 10, MaxLimit: 100, Location: time.UTC}}
+
+	board, err := c.GetPeriodLeaderBoard(context.Background(), types.PeriodAllTime, time.Now(), time.Time{}, time.Time{}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !db.from.IsZero() || !db.to.IsZero() {
+		t.Errorf("all time asked for bounds %v - %v, want open", db.from, db.to)
+	}
+	if board.Total != 2 || board.Users[0].ID != 1 || board.Users[0].Earned != types.NewAmount(100) {
+		t.Errorf("got %+v, want user 1 first with earned 100", board)
+	}
+}
+
+func TestSnapshotClosedPeriodsBackfillsMissedPeriods(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.October, d, 0, 0, 0, 0, time.UTC) }
+	// первое незафиксированное начисление в пятницу 9 октября, сейчас среда 14 октября
+	start := day(9).Add(10 * time.Hour)
+	db := &snapshotDatabase{start: &start, snapshots: map[types.Period][][2]time.Time{}}
+	c := &Controller{leaderBoardDatabase: db, leaderBoard: config.LeaderBoardConfig{Location: time.UTC}}
+
+	if err := c.SnapshotClosedPeriods(context.Background(), day(14).Add(12*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[types.Period][][2]time.Time{
+		types.PeriodDaily:  {{day(9), day(10)}, {day(10), day(11)}, {day(11), day(12)}, {day(12), day(13)}, {day(13), day(14)}},
+		types.PeriodWeekly: {{day(5), day(12)}},
+	}
+	for _, period := range snapshotPeriods {
+		got := db.snapshots[period]
+		if len(got) != len(want[period]) {
+			t.Errorf("%s: got %d snapshots %v, want %v", period, len(got), got, want[period])
+			continue
+		}
+		for i := range got {
+			if !got[i][0].Equal(want[period][i][0]) || !got[i][1].Equal(want[period][i][1]) {
+				t.Errorf("%s snapshot %d: got %v, want %v", period, i, got[i], want[period][i])
+			}
+		}
+	}
+}
+
+func TestSnapshotClosedPeriodsWithoutNewRewards(t *testing.T) {
+	db := &snapshotDatabase{snapshots: map[types.Period][][2]time.Time{}}
+	c := &Controller{leaderBoardDatabase: db, leaderBoard: config.LeaderBoardConfig{Location: time.UTC}}
+	if err := c.SnapshotClosedPeriods(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.snapshots) != 0 {
+		t.Errorf("got snapshots %v, want none", db.snapshots)
+	}
+}
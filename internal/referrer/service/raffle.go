@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+)
+
+var ErrInvalidRaffle = errors.New("invalid raffle")
+
+type raffleDatabase interface {
+	CreateRaffle(ctx context.Context, raffle *types.Raffle, seed string) (int, error)
+	GetRaffles(ctx context.Context) ([]*types.Raffle, error)
+	GetRaffle(ctx context.Context, raffleID int) (*types.Raffle, error)
+	GetRaffleTickets(ctx context.Context, raffleID int) ([]*types.RaffleTicket, error)
+	BuyRaffleTickets(ctx context.Context, userID, raffleID, quantity int) (*types.RaffleTickets, error)
+	GetRafflesToDraw(ctx context.Context, now time.Time) ([]int, error)
+	DrawRaffle(ctx context.Context, raffleID int) error
+}
+
+// CreateRaffle загадывает случайный seed и создает розыгрыш, публикуя только хэш seed
+func (c *Controller) CreateRaffle(ctx context.Context, raffle *types.Raffle) (int, error) {
+	if raffle.Name == "" || raffle.TicketPrice <= 0 || !raffle.DrawAt.After(time.Now()) || len(raffle.Prizes) == 0 {
+		return 0, ErrInvalidRaffle
+	}
+	if raffle.MaxTicketsPerUser != nil && *raffle.MaxTicketsPerUser <= 0 {
+		return 0, ErrInvalidRaffle
+	}
+	places := make(map[int]bool, len(raffle.Prizes))
+	for _, prize := range raffle.Prizes {
+		if prize.Place <= 0 || prize.Reward <= 0 || places[prize.Place] {
+			return 0, ErrInvalidRaffle
+		}
+		places[prize.Place] = true
+	}
+	seed, err := newRaffleSeed()
+	if err != nil {
+		return 0, err
+	}
+	raffle.SeedHash = types.RaffleSeedHash(seed)
+	id, err := c.raffleDatabase.CreateRaffle(ctx, raffle, seed)
+	if err != nil {
+		return 0, errors.Wrap(err, "raffleDatabase.CreateRaffle failed: ")
+	}
+	return id, nil
+}
+
+func newRaffleSeed() (string, error) {
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return "", errors.Wrap(err, "rand.Read failed: ")
+	}
+	return hex.EncodeToString(seed), nil
+}
+
+func (c *Controller) GetRaffles(ctx context.Context) ([]*types.Raffle, error) {
+	raffles, err := c.raffleDatabase.GetRaffles(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "raffleDatabase.GetRaffles failed: ")
+	}
+	return raffles, nil
+}
+
+func (c *Controller) GetRaffle(ctx context.Context, raffleID int) (*types.Raffle, error) {
+	raffle, err := c.raffleDatabase.GetRaffle(ctx, raffleID)
+	if err != nil {
+		return nil, errors.Wrap(err, "raffleDatabase.GetRaffle failed: ")
+	}
+	return raffle, nil
+}
+
+func (c *Controller) GetRaffleTickets(ctx context.Context, raffleID int) ([]*types.RaffleTicket, error) {
+	tickets, err := c.raffleDatabase.GetRaffleTickets(ctx, raffleID)
+	if err != nil {
+		return nil, errors.Wrap(err, "raffleDatabase.GetRaffleTickets failed: ")
+	}
+	return tickets, nil
+}
+
+// BuyRaffleTickets покупает билеты за баланс пользователя, quantity == 0 означает один билет
+func (c *Controller) BuyRaffleTickets(ctx context.Context, userID, raffleID int, request *types.BuyRaffleTicketsRequest) (*types.RaffleTickets, error) {
+	if request.Quantity == 0 {
+		request.Quantity = 1
+	}
+	if request.Quantity < 0 {
+		return nil, ErrInvalidQuantity
+	}
+	tickets, err := c.raffleDatabase.BuyRaffleTickets(ctx, userID, raffleID, request.Quantity)
+	if err != nil {
+		return nil, errors.Wrap(err, "raffleDatabase.BuyRaffleTickets failed: ")
+	}
+	c.leaderBoardCache.Invalidate()
+	return tickets, nil
+}
+
+// DrawRaffles разыгрывает все розыгрыши, время которых пришло к моменту now
+func (c *Controller) DrawRaffles(ctx context.Context, now time.Time) error {
+	ids, err := c.raffleDatabase.GetRafflesToDraw(ctx, now)
+	if err != nil {
+		return errors.Wrap(err, "raffleDatabase.GetRafflesToDraw failed: ")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	for _, id := range ids {
+		if err = c.raffleDatabase.DrawRaffle(ctx, id); err != nil {
+			return errors.Wrap(err, "raffleDatabase.DrawRaffle failed: ")
+		}
+	}
+	c.leaderBoardCache.Invalidate()
+	return nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+)
+
+var ErrInvalidSeason = errors.New("invalid season")
+
+type seasonDatabase interface {
+	CreateSeason(ctx context.Context, season *types.Season) (int, error)
+	GetSeasons(ctx context.Context) ([]*types.Season, error)
+	GetSeason(ctx context.Context, seasonID int) (*types.Season, error)
+	GetSeasonLeaderBoard(ctx context.Context, seasonID, limit, offset int) ([]*types.SeasonRankedUser, int, error)
+	GetSeasonResults(ctx context.Context, seasonID, limit, offset int) ([]*types.SeasonRankedUser, int, error)
+	GetSeasonsToClose(ctx context.Context, now time.Time) ([]int, error)
+	CloseSeason(ctx context.Context, seasonID int) error
+}
+
+func (c *Controller) CreateSeason(ctx context.Context, season *types.Season) (int, error) {
+	if season.Name == "" || !season.StartsAt.Before(season.EndsAt) {
+		return 0, ErrInvalidSeason
+	}
+	places := make(map[int]bool, len(season.Prizes))
+	for _, prize := range season.Prizes {
+		if prize.Place <= 0 || prize.Reward <= 0 || places[prize.Place] {
+			return 0, ErrInvalidSeason
+		}
+		places[prize.Place] = true
+	}
+	id, err := c.seasonDatabase.CreateSeason(ctx, season)
+	if err != nil {
+		return 0, errors.Wrap(err, "seasonDatabase.CreateSeason failed: ")
+	}
+	return id, nil
+}
+
+func (c *Controller) GetSeasons(ctx context.Context) ([]*types.Season, error) {
+	seasons, err := c.seasonDatabase.GetSeasons(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "seasonDatabase.GetSeasons failed: ")
+	}
+	return seasons, nil
+}
+
+// GetSeasonLeaderBoard возвращает таблицу сезона, для закрытого сезона - зафиксированные итоги
+func (c *Controller) GetSeasonLeaderBoard(ctx context.Context, seasonID, limit, offset int) (*types.SeasonLeaderBoard, error) {
+	limit, offset = c.pageBounds(limit, offset)
+	season, err := c.seasonDatabase.GetSeason(ctx, seasonID)
+	if err != nil {
+		return nil, errors.Wrap(err, "seasonDatabase.GetSeason failed: ")
+	}
+	result := &types.SeasonLeaderBoard{Season: season, Final: season.ClosedAt != nil, Limit: limit, Offset: offset}
+	if result.Final {
+		result.Users, result.Total, err = c.seasonDatabase.GetSeasonResults(ctx, seasonID, limit, offset)
+	} else {
+		result.Users, result.Total, err = c.seasonDatabase.GetSeasonLeaderBoard(ctx, seasonID, limit, offset)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "seasonDatabase get leaderboard failed: ")
+	}
+	return result, nil
+}
+
+// CloseSeason досрочно закрывает сезон
+func (c *Controller) CloseSeason(ctx context.Context, seasonID int) error {
+	err := c.seasonDatabase.CloseSeason(ctx, seasonID)
+	if err != nil {
+		return errors.Wrap(err, "seasonDatabase.CloseSeason failed: ")
+	}
+	c.leaderBoardCache.Invalidate()
+	return nil
+}
+
+// CloseFinishedSeasons закрывает все сезоны, время которых вышло к моменту now
+func (c *Controller) CloseFinishedSeasons(ctx context.Context, now time.Time) error {
+	ids, err := c.seasonDatabase.GetSeasonsToClose(ctx, now)
+	if err != nil {
+		return errors.Wrap(err, "seasonDatabase.GetSeasonsToClose failed: ")
+	}
+	for _, id := range ids {
+		if err = c.CloseSeason(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
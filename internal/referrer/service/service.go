@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/SakuraBurst/denet/internal/referrer/config"
@@ -12,45 +13,99 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-const defaultRefererReward = 100
-const defaultTopUsersLimit = 5
+var defaultRefererReward = types.NewAmount(100)
 
 type userDatabase interface {
 	CreateNewUser(ctx context.Context, user *types.UserRequest, referrerCode string) error
 	GetFullUserInfo(ctx context.Context, userID int) (*types.FullUser, error)
 	GetUserByUserName(ctx context.Context, userName string) (*types.User, error)
 	GetUserByReferrerCode(ctx context.Context, referrerCode string) (*types.User, error)
-	RewardUser(ctx context.Context, userID, rewardValue int) error
-	GetTopUsersByBalance(ctx context.Context, limit int) ([]*types.User, error)
+	RewardUser(ctx context.Context, userID int, rewardValue types.Amount, source string) error
+	GetLeaderBoard(ctx context.Context, limit, offset int) ([]*types.RankedUser, int, error)
+	GetUserRank(ctx context.Context, userID, neighbors int) ([]*types.RankedUser, error)
+	CreateReferral(ctx context.Context, refereeID int, referrerCode string, reward types.Amount, policy types.EarningPolicy) (int, error)
+	GetNetworkLeaderBoard(ctx context.Context, userID int) ([]*types.NetworkRankedUser, error)
+	GetUsersByIDs(ctx context.Context, userIDs []int) ([]*types.User, error)
+	GetRefereesByUserIDs(ctx context.Context, userIDs []int) ([]*types.User, error)
+	GetUserRanks(ctx context.Context, userIDs []int) ([]*types.RankedUser, error)
 }
 
 type taskToUserDatabase interface {
-	CompleteTask(ctx context.Context, taskID, userID int) (int, error)
+	CompleteTask(ctx context.Context, taskID, userID int, policy types.EarningPolicy) (*types.Balance, error)
+	GetCompletionsByUserIDs(ctx context.Context, userIDs []int) ([]*types.Completion, error)
 }
 
 type taskDataBase interface {
 	CreateNewTask(ctx context.Context, task *types.Task) (int, error)
 	GetTaskById(ctx context.Context, taskID int) (*types.Task, error)
-	UpdateTaskReward(ctx context.Context, id, newReward int) error
+	UpdateTaskReward(ctx context.Context, id int, newReward types.Amount) error
 	GetAllTasks(ctx context.Context) ([]*types.Task, error)
+	GetTasksByIDs(ctx context.Context, taskIDs []int) ([]*types.Task, error)
 }
 
 type Controller struct {
-	userDatabase       userDatabase
-	taskDataBase       taskDataBase
-	taskToUserDatabase taskToUserDatabase
-	jwtSecret          []byte
-	databaseClose      func() error
-}
-
-func NewController(cfg *config.Config, u userDatabase, t taskDataBase, ttu taskToUserDatabase, dbClose func() error) *Controller {
-	return &Controller{
-		userDatabase:       u,
-		taskDataBase:       t,
-		taskToUserDatabase: ttu,
-		jwtSecret:          []byte(cfg.JWTSecret),
-		databaseClose:      dbClose,
+	userDatabase         userDatabase
+	taskDataBase         taskDataBase
+	taskToUserDatabase   taskToUserDatabase
+	leaderBoardDatabase  leaderBoardDatabase
+	seasonDatabase       seasonDatabase
+	shopDatabase         shopDatabase
+	transferDatabase     transferDatabase
+	idempotencyDatabase  idempotencyDatabase
+	clawbackDatabase     clawbackDatabase
+	notificationDatabase notificationDatabase
+	expirationDatabase   expirationDatabase
+	achievementDatabase  achievementDatabase
+	teamDatabase         teamDatabase
+	raffleDatabase       raffleDatabase
+	webhookDatabase      webhookDatabase
+	events               eventBus
+	jwtSecret            []byte
+	leaderBoard          config.LeaderBoardConfig
+	leaderBoardCache     *leaderBoardCache
+	transfers            config.TransfersConfig
+	idempotency          config.IdempotencyConfig
+	clawback             config.ClawbackConfig
+	expiration           config.ExpirationConfig
+	teams                config.TeamsConfig
+	webhooks             config.WebhooksConfig
+	webhookClient        *http.Client
+	earningPolicy        types.EarningPolicy
+	databaseClose        func() error
+}
+
+func NewController(cfg *config.Config, u userDatabase, t taskDataBase, ttu taskToUserDatabase, lb leaderBoardDatabase, s seasonDatabase, sh shopDatabase, tr transferDatabase, i idempotencyDatabase, cb clawbackDatabase, n notificationDatabase, e expirationDatabase, a achievementDatabase, tm teamDatabase, rf raffleDatabase, wh webhookDatabase, ev eventBus, dbClose func() error) *Controller {
+	c := &Controller{
+		userDatabase:         u,
+		taskDataBase:         t,
+		taskToUserDatabase:   ttu,
+		leaderBoardDatabase:  lb,
+		seasonDatabase:       s,
+		shopDatabase:         sh,
+		transferDatabase:     tr,
+		idempotencyDatabase:  i,
+		clawbackDatabase:     cb,
+		notificationDatabase: n,
+		expirationDatabase:   e,
+		achievementDatabase:  a,
+		teamDatabase:         tm,
+		raffleDatabase:       rf,
+		webhookDatabase:      wh,
+		events:               ev,
+		jwtSecret:            []byte(cfg.JWTSecret),
+		leaderBoard:          cfg.LeaderBoard,
+		transfers:            cfg.Transfers,
+		idempotency:          cfg.Idempotency,
+		clawback:             cfg.Clawback,
+		expiration:           cfg.Expiration,
+		teams:                cfg.Teams,
+		webhooks:             cfg.Webhooks,
+		webhookClient:        newWebhookClient(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivateNetworks),
+		earningPolicy:        newEarningPolicy(cfg.Limits, cfg.Levels),
+		databaseClose:        dbClose,
 	}
+	c.leaderBoardCache = newLeaderBoardCache(cfg.LeaderBoard, u.GetLeaderBoard, c.publishRankChanges)
+	return c
 }
 
 func (c *Controller) CreateNewUser(ctx context.Context, user *types.UserRequest) error {
@@ -77,7 +132,7 @@ func (c *Controller) AuthorizeUser(ctx context.Context, user *types.UserRequest)
 		return "", errors.Wrap(err, "bcrypt.CompareHashAndPassword failed: ")
 	}
 
-	return createJWT(foundUser.ID)
+	return createJWT(foundUser.ID, foundUser.Role, c.jwtSecret)
 }
 
 func (c *Controller) GetUserStatus(ctx context.Context, id int) (*types.FullUser, error) {
@@ -85,15 +140,12 @@ func (c *Controller) GetUserStatus(ctx context.Context, id int) (*types.FullUser
 	if err != nil {
 		return nil, errors.Wrap(err, "userDatabase.GetUserById failed: ")
 	}
-	return user, nil
-}
-
-func (c *Controller) GetTopUsers(ctx context.Context) ([]*types.User, error) {
-	users, err := c.userDatabase.GetTopUsersByBalance(ctx, defaultTopUsersLimit)
+	user.UpcomingExpirations, err = c.getUpcomingExpirations(ctx, id)
 	if err != nil {
-		return nil, errors.Wrap(err, "userDatabase.GetTopUsersByBalance failed: ")
+		return nil, err
 	}
-	return users, nil
+	user.Level = c.earningPolicy.Levels.Progress(user.XP)
+	return user, nil
 }
 
 func (c *Controller) GetAllTasks(ctx context.Context) ([]*types.Task, error) {
@@ -104,24 +156,50 @@ func (c *Controller) GetAllTasks(ctx context.Context) ([]*types.Task, error) {
 	return users, nil
 }
 
-func (c *Controller) CompleteTask(ctx context.Context, userID int, taskID int) (int, error) {
-	return c.taskToUserDatabase.CompleteTask(ctx, taskID, userID)
+func (c *Controller) CompleteTask(ctx context.Context, userID int, taskID int) (*types.Balance, error) {
+	policy, err := c.currentEarningPolicy()
+	if err != nil {
+		return nil, err
+	}
+	balance, err := c.taskToUserDatabase.CompleteTask(ctx, taskID, userID, policy)
+	if err != nil {
+		return nil, err
+	}
+	// бонусы за достижения могли поменять таблицу лидеров и в том случае, если награда не в баллах.
+	// О сдвигах мест подписчики узнают при перечитывании кэша
+	c.leaderBoardCache.Invalidate()
+	c.events.Publish(types.NewEvent(types.EventBalanceChanged, userID, &types.BalanceChangedEvent{Currency: balance.Currency, Balance: balance.Amount}))
+	return balance, nil
 }
 
 func (c *Controller) Referrer(ctx context.Context, id int, referrerCode string) error {
-	codeOwner, err := c.userDatabase.GetUserByReferrerCode(ctx, referrerCode)
+	policy, err := c.currentEarningPolicy()
 	if err != nil {
-		return errors.Wrap(err, "userDatabase.GetUserByReferrerCode failed: ")
+		return err
 	}
-	// поощряем и того кто ввел код, и того чей код был введен
-	err = c.userDatabase.RewardUser(ctx, codeOwner.ID, defaultRefererReward)
+	referrerID, err := c.userDatabase.CreateReferral(ctx, id, referrerCode, defaultRefererReward, policy)
 	if err != nil {
-		return errors.Wrap(err, "userDatabase.RewardUser failed: ")
+		return errors.Wrap(err, "userDatabase.CreateReferral failed: ")
 	}
-	return c.userDatabase.RewardUser(ctx, id, defaultRefererReward)
+	c.leaderBoardCache.Invalidate()
+	// события не должны ломать уже прошедшее начисление, без пользователей уходит только событие о реферале
+	users, _ := c.userDatabase.GetUsersByIDs(ctx, []int{id, referrerID})
+	joined := &types.RefereeJoinedEvent{RefereeID: id}
+	events := make([]*types.Event, 0, len(users)+1)
+	for _, user := range users {
+		if user.ID == id {
+			joined.UserName = user.UserName
+		}
+		events = append(events, types.NewEvent(types.EventBalanceChanged, user.ID, &types.BalanceChangedEvent{Currency: types.CurrencyPoints, Balance: user.Balance}))
+	}
+	c.events.Publish(append(events, types.NewEvent(types.EventRefereeJoined, referrerID, joined))...)
+	return nil
 }
 
 func (c *Controller) CreateNewTask(ctx context.Context, task *types.Task) (int, error) {
+	if task.Currency == "" {
+		task.Currency = types.CurrencyPoints
+	}
 	return c.taskDataBase.CreateNewTask(ctx, task)
 }
 
@@ -129,7 +207,7 @@ func (c *Controller) GetTask(ctx context.Context, id int) (*types.Task, error) {
 	return c.taskDataBase.GetTaskById(ctx, id)
 }
 
-func (c *Controller) UpdateTaskReward(ctx context.Context, id, newReward int) error {
+func (c *Controller) UpdateTaskReward(ctx context.Context, id int, newReward types.Amount) error {
 	return c.taskDataBase.UpdateTaskReward(ctx, id, newReward)
 }
 
@@ -137,14 +215,15 @@ func (c *Controller) Close() error {
 	return c.databaseClose()
 }
 
-func createJWT(id int) (string, error) {
+func createJWT(id int, role string, secret []byte) (string, error) {
 	token := jwt.New(jwt.SigningMethodHS256)
 
 	claims := token.Claims.(jwt.MapClaims)
 	claims["id"] = id
+	claims["role"] = role
 	claims["exp"] = time.Now().Add(time.Hour * 72).Unix()
 
-	return token.SignedString([]byte("secret"))
+	return token.SignedString(secret)
 
 }
 
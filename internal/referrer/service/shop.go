@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+)
+
+var ErrInvalidShopItem = errors.New("invalid shop item")
+var ErrInvalidQuantity = errors.New("invalid quantity")
+
+type shopDatabase interface {
+	CreateShopItem(ctx context.Context, item *types.ShopItem) (int, error)
+	UpdateShopItem(ctx context.Context, item *types.ShopItem) error
+	GetShopItems(ctx context.Context, onlyActive bool) ([]*types.ShopItem, error)
+	Redeem(ctx context.Context, userID int, request *types.RedeemRequest) (*types.Order, error)
+	GetOrders(ctx context.Context, userID int, status string) ([]*types.Order, error)
+	FulfilOrder(ctx context.Context, orderID int) error
+	CancelOrder(ctx context.Context, orderID int) error
+}
+
+func validateShopItem(item *types.ShopItem) error {
+	if item.Name == "" || item.Price <= 0 {
+		return ErrInvalidShopItem
+	}
+	if item.Stock != nil && *item.Stock < 0 {
+		return ErrInvalidShopItem
+	}
+	if item.PerUserLimit != nil && *item.PerUserLimit <= 0 {
+		return ErrInvalidShopItem
+	}
+	return nil
+}
+
+func (c *Controller) CreateShopItem(ctx context.Context, item *types.ShopItem) (int, error) {
+	if err := validateShopItem(item); err != nil {
+		return 0, err
+	}
+	id, err := c.shopDatabase.CreateShopItem(ctx, item)
+	if err != nil {
+		return 0, errors.Wrap(err, "shopDatabase.CreateShopItem failed: ")
+	}
+	return id, nil
+}
+
+func (c *Controller) UpdateShopItem(ctx context.Context, item *types.ShopItem) error {
+	if err := validateShopItem(item); err != nil {
+		return err
+	}
+	err := c.shopDatabase.UpdateShopItem(ctx, item)
+	if err != nil {
+		return errors.Wrap(err, "shopDatabase.UpdateShopItem failed: ")
+	}
+	return nil
+}
+
+func (c *Controller) GetShopItems(ctx context.Context, onlyActive bool) ([]*types.ShopItem, error) {
+	items, err := c.shopDatabase.GetShopItems(ctx, onlyActive)
+	if err != nil {
+		return nil, errors.Wrap(err, "shopDatabase.GetShopItems failed: ")
+	}
+	return items, nil
+}
+
+// Redeem покупает товар за баланс пользователя, quantity == 0 означает одну штуку
+func (c *Controller) Redeem(ctx context.Context, userID int, request *types.RedeemRequest) (*types.Order, error) {
+	if request.Quantity == 0 {
+		request.Quantity = 1
+	}
+	if request.Quantity < 0 {
+		return nil, ErrInvalidQuantity
+	}
+	order, err := c.shopDatabase.Redeem(ctx, userID, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "shopDatabase.Redeem failed: ")
+	}
+	c.leaderBoardCache.Invalidate()
+	return order, nil
+}
+
+func (c *Controller) GetOrders(ctx context.Context, userID int, status string) ([]*types.Order, error) {
+	orders, err := c.shopDatabase.GetOrders(ctx, userID, status)
+	if err != nil {
+		return nil, errors.Wrap(err, "shopDatabase.GetOrders failed: ")
+	}
+	return orders, nil
+}
+
+func (c *Controller) FulfilOrder(ctx context.Context, orderID int) error {
+	err := c.shopDatabase.FulfilOrder(ctx, orderID)
+	if err != nil {
+		return errors.Wrap(err, "shopDatabase.FulfilOrder failed: ")
+	}
+	return nil
+}
+
+// CancelOrder отменяет заказ и возвращает пользователю потраченный баланс
+func (c *Controller) CancelOrder(ctx context.Context, orderID int) error {
+	err := c.shopDatabase.CancelOrder(ctx, orderID)
+	if err != nil {
+		return errors.Wrap(err, "shopDatabase.CancelOrder failed: ")
+	}
+	c.leaderBoardCache.Invalidate()
+	return nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidTeam = errors.New("invalid team")
+var ErrInvalidTeamGoal = errors.New("invalid team goal")
+
+type teamDatabase interface {
+	CreateTeam(ctx context.Context, userID int, name, inviteCode string) (int, error)
+	JoinTeam(ctx context.Context, userID, teamID int, inviteCode string, maxMembers int) (int, error)
+	LeaveTeam(ctx context.Context, userID int) error
+	GetUserTeam(ctx context.Context, userID int) (*types.Team, error)
+	GetTeamLeaderBoard(ctx context.Context, limit, offset int) ([]*types.RankedTeam, int, error)
+	CreateTeamGoal(ctx context.Context, goal *types.TeamGoal) (int, error)
+	GetTeamGoalsToPay(ctx context.Context) ([]int, error)
+	PayTeamGoal(ctx context.Context, goalID int) error
+}
+
+// CreateTeam создает команду с новым кодом приглашения, создатель становится ее владельцем
+func (c *Controller) CreateTeam(ctx context.Context, userID int, request *types.CreateTeamRequest) (int, error) {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return 0, ErrInvalidTeam
+	}
+	id, err := c.teamDatabase.CreateTeam(ctx, userID, name, uuid.New().String())
+	if err != nil {
+		return 0, errors.Wrap(err, "teamDatabase.CreateTeam failed: ")
+	}
+	return id, nil
+}
+
+// JoinTeam добавляет пользователя в команду по коду приглашения, teamID не 0 - код должен быть от этой команды
+func (c *Controller) JoinTeam(ctx context.Context, userID, teamID int, inviteCode string) (int, error) {
+	id, err := c.teamDatabase.JoinTeam(ctx, userID, teamID, inviteCode, c.teams.MaxMembers)
+	if err != nil {
+		return 0, errors.Wrap(err, "teamDatabase.JoinTeam failed: ")
+	}
+	return id, nil
+}
+
+func (c *Controller) LeaveTeam(ctx context.Context, userID int) error {
+	err := c.teamDatabase.LeaveTeam(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "teamDatabase.LeaveTeam failed: ")
+	}
+	return nil
+}
+
+func (c *Controller) GetUserTeam(ctx context.Context, userID int) (*types.Team, error) {
+	team, err := c.teamDatabase.GetUserTeam(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "teamDatabase.GetUserTeam failed: ")
+	}
+	return team, nil
+}
+
+func (c *Controller) GetTeamLeaderBoard(ctx context.Context, limit, offset int) (*types.TeamLeaderBoard, error) {
+	limit, offset = c.pageBounds(limit, offset)
+	teams, total, err := c.teamDatabase.GetTeamLeaderBoard(ctx, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "teamDatabase.GetTeamLeaderBoard failed: ")
+	}
+	return &types.TeamLeaderBoard{Teams: teams, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+func (c *Controller) CreateTeamGoal(ctx context.Context, goal *types.TeamGoal) (int, error) {
+	if goal.Target <= 0 || goal.Bonus < 0 {
+		return 0, ErrInvalidTeamGoal
+	}
+	id, err := c.teamDatabase.CreateTeamGoal(ctx, goal)
+	if err != nil {
+		return 0, errors.Wrap(err, "teamDatabase.CreateTeamGoal failed: ")
+	}
+	return id, nil
+}
+
+// PayTeamGoals выплачивает бонусы за все достигнутые цели команд
+func (c *Controller) PayTeamGoals(ctx context.Context) error {
+	ids, err := c.teamDatabase.GetTeamGoalsToPay(ctx)
+	if err != nil {
+		return errors.Wrap(err, "teamDatabase.GetTeamGoalsToPay failed: ")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	for _, id := range ids {
+		if err = c.teamDatabase.PayTeamGoal(ctx, id); err != nil {
+			return errors.Wrap(err, "teamDatabase.PayTeamGoal failed: ")
+		}
+	}
+	c.leaderBoardCache.Invalidate()
+	return nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+)
+
+var ErrInvalidTransfer = errors.New("invalid transfer")
+
+type transferDatabase interface {
+	Transfer(ctx context.Context, fromUserID int, request *types.TransferRequest, idempotencyKey string, limits types.TransferLimits) (*types.Transfer, error)
+	GetTransfers(ctx context.Context, userID int) ([]*types.Transfer, error)
+}
+
+// Transfer переводит баланс другому пользователю. idempotencyKey обязателен, повтор с тем же ключом не переводит второй раз
+func (c *Controller) Transfer(ctx context.Context, fromUserID int, request *types.TransferRequest, idempotencyKey string) (*types.Transfer, error) {
+	if request.ToUserID == 0 || request.Amount <= 0 || idempotencyKey == "" {
+		return nil, ErrInvalidTransfer
+	}
+	now := time.Now()
+	dayStart, _, err := periodBounds(types.PeriodDaily, now, c.leaderBoard.Location)
+	if err != nil {
+		return nil, err
+	}
+	limits := types.TransferLimits{
+		DailyLimit:       types.NewAmount(int64(c.transfers.DailyLimit)),
+		DayStart:         dayStart,
+		RegisteredBefore: now.Add(-c.transfers.MinAccountAge),
+	}
+	transfer, err := c.transferDatabase.Transfer(ctx, fromUserID, request, idempotencyKey, limits)
+	if err != nil {
+		return nil, errors.Wrap(err, "transferDatabase.Transfer failed: ")
+	}
+	c.leaderBoardCache.Invalidate()
+	return transfer, nil
+}
+
+func (c *Controller) GetTransfers(ctx context.Context, userID int) ([]*types.Transfer, error) {
+	transfers, err := c.transferDatabase.GetTransfers(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "transferDatabase.GetTransfers failed: ")
+	}
+	return transfers, nil
+}
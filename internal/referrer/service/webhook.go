@@ -0,0 +1,287 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/pkg/backoff"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/SakuraBurst/denet/internal/referrer/validation"
+	"github.com/go-faster/errors"
+)
+
+// заголовки запроса вебхука. Подпись - hex HMAC-SHA256 от "<timestamp>.<тело>" на секрете подписки,
+// метка времени в подписи не дает переотправить перехваченный запрос позже
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookIDHeader        = "X-Webhook-ID"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// сколько доставок отдавать за раз по умолчанию и максимум
+const (
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 200
+)
+
+// сколько байт ответа получателя сохранять в журнале попыток
+const webhookResponseSnippet = 256
+
+type webhookDatabase interface {
+	CreateWebhook(ctx context.Context, webhook *types.WebhookRequest) (int, error)
+	UpdateWebhook(ctx context.Context, id int, webhook *types.WebhookRequest) error
+	GetWebhooks(ctx context.Context) ([]*types.Webhook, error)
+	GetWebhookDeliveries(ctx context.Context, webhookID int, status string, limit, offset int) ([]*types.WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, id int) (*types.WebhookDelivery, error)
+	ReplayWebhookDelivery(ctx context.Context, id int) (int, error)
+	ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*types.PendingWebhookDelivery, error)
+	RecordWebhookAttempt(ctx context.Context, deliveryID int, attempt *types.WebhookAttempt, status string, nextAttemptAt time.Time) error
+	EnqueueWebhooks(ctx context.Context, message *types.OutboxMessage) error
+}
+
+// ErrWebhookURLNotPublic адрес подписки ведет в локальную или внутреннюю сеть, а webhooks.allow_private_networks выключен
+var ErrWebhookURLNotPublic = errors.New("webhook url is not public")
+
+// errWebhookAddressForbidden имя получателя разрешилось в локальный или внутренний адрес
+var errWebhookAddressForbidden = errors.New("webhook address is not public")
+
+// webhookDialControl не дает соединиться с локальным или внутренним адресом. Адрес проверяется после
+// разрешения имени, поэтому имя, которое после проверки подписки стали разрешать во внутреннюю сеть, не поможет
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return errors.Wrap(err, "netip.ParseAddrPort failed: ")
+	}
+	if !validation.PublicAddr(addrPort.Addr()) {
+		return errors.Wrap(errWebhookAddressForbidden, address)
+	}
+	return nil
+}
+
+// newWebhookClient клиент для отправки вебхуков, allowPrivate отключает проверку адреса при соединении
+func newWebhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: webhookDialControl}
+	if allowPrivate {
+		dialer.Control = nil
+	}
+	return &http.Client{
+		Timeout: timeout,
+		// без прокси из окружения: иначе проверялся бы адрес прокси, а не получателя
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// переадресация считается неудачной попыткой: подписку с устаревшим адресом нужно исправить
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkWebhookURL не дает подписать адрес в локальной или внутренней сети, если это не разрешено настройкой.
+// Имя проверяется без DNS, куда оно указывает на самом деле, проверяется при соединении
+func (c *Controller) checkWebhookURL(rawURL string) error {
+	if c.webhooks.AllowPrivateNetworks {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || !validation.PublicHost(u.Hostname()) {
+		return ErrWebhookURLNotPublic
+	}
+	return nil
+}
+
+func (c *Controller) CreateWebhook(ctx context.Context, webhook *types.WebhookRequest) (int, error) {
+	if err := c.checkWebhookURL(webhook.URL); err != nil {
+		return 0, err
+	}
+	id, err := c.webhookDatabase.CreateWebhook(ctx, webhook)
+	if err != nil {
+		return 0, errors.Wrap(err, "webhookDatabase.CreateWebhook failed: ")
+	}
+	return id, nil
+}
+
+func (c *Controller) UpdateWebhook(ctx context.Context, id int, webhook *types.WebhookRequest) error {
+	if err := c.checkWebhookURL(webhook.URL); err != nil {
+		return err
+	}
+	err := c.webhookDatabase.UpdateWebhook(ctx, id, webhook)
+	if err != nil {
+		return errors.Wrap(err, "webhookDatabase.UpdateWebhook failed: ")
+	}
+	return nil
+}
+
+func (c *Controller) GetWebhooks(ctx context.Context) ([]*types.Webhook, error) {
+	webhooks, err := c.webhookDatabase.GetWebhooks(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "webhookDatabase.GetWebhooks failed: ")
+	}
+	return webhooks, nil
+}
+
+func (c *Controller) GetWebhookDeliveries(ctx context.Context, webhookID int, status string, limit, offset int) ([]*types.WebhookDelivery, error) {
+	if limit <= 0 {
+		limit = defaultWebhookDeliveriesLimit
+	}
+	limit = min(limit, maxWebhookDeliveriesLimit)
+	offset = max(offset, 0)
+	deliveries, err := c.webhookDatabase.GetWebhookDeliveries(ctx, webhookID, status, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "webhookDatabase.GetWebhookDeliveries failed: ")
+	}
+	return deliveries, nil
+}
+
+func (c *Controller) GetWebhookDelivery(ctx context.Context, id int) (*types.WebhookDelivery, error) {
+	delivery, err := c.webhookDatabase.GetWebhookDelivery(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "webhookDatabase.GetWebhookDelivery failed: ")
+	}
+	return delivery, nil
+}
+
+// ReplayWebhookDelivery отправляет событие доставки еще раз, возвращает id новой доставки
+func (c *Controller) ReplayWebhookDelivery(ctx context.Context, id int) (int, error) {
+	replayID, err := c.webhookDatabase.ReplayWebhookDelivery(ctx, id)
+	if err != nil {
+		return 0, errors.Wrap(err, "webhookDatabase.ReplayWebhookDelivery failed: ")
+	}
+	return replayID, nil
+}
+
+// EnqueueWebhooks ставит событие из outbox в очередь подписанным вебхукам
+func (c *Controller) EnqueueWebhooks(ctx context.Context, message *types.OutboxMessage) error {
+	err := c.webhookDatabase.EnqueueWebhooks(ctx, message)
+	if err != nil {
+		return errors.Wrap(err, "webhookDatabase.EnqueueWebhooks failed: ")
+	}
+	return nil
+}
+
+// DeliverWebhooks отправляет доставки, которым пора уйти к моменту now, пока они не кончатся. Неудачные
+// откладываются с удвоением задержки, после webhooks.max_attempts попыток доставка становится dead
+func (c *Controller) DeliverWebhooks(ctx context.Context, now time.Time) error {
+	concurrency := max(c.webhooks.Concurrency, 1)
+	for {
+		// пока пачка отправляется, ее не возьмут другие экземпляры
+		leaseUntil := now.Add(webhookLease(c.webhooks.BatchSize, concurrency, c.webhooks.Timeout))
+		deliveries, err := c.webhookDatabase.ClaimWebhookDeliveries(ctx, now, leaseUntil, c.webhooks.BatchSize)
+		if err != nil {
+			return errors.Wrap(err, "webhookDatabase.ClaimWebhookDeliveries failed: ")
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var failed error
+		slots := make(chan struct{}, concurrency)
+		for _, delivery := range deliveries {
+			slots <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() { <-slots; wg.Done() }()
+				if err := c.deliverWebhook(ctx, delivery); err != nil {
+					mu.Lock()
+					failed = err
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		if failed != nil {
+			return failed
+		}
+		if len(deliveries) < c.webhooks.BatchSize {
+			return nil
+		}
+		now = time.Now()
+	}
+}
+
+// deliverWebhook отправляет одну доставку и записывает результат, ошибка - только если результат не записался
+func (c *Controller) deliverWebhook(ctx context.Context, delivery *types.PendingWebhookDelivery) error {
+	attempt := c.sendWebhook(ctx, delivery)
+	status := types.WebhookDeliveryPending
+	var nextAttemptAt time.Time
+	switch {
+	case attempt.Error == nil:
+		status = types.WebhookDeliveryDelivered
+	case delivery.Attempts >= c.webhooks.MaxAttempts:
+		status = types.WebhookDeliveryDead
+	default:
+		nextAttemptAt = attempt.AttemptedAt.Add(backoff.Exponential(delivery.Attempts, c.webhooks.RetryBackoff, c.webhooks.MaxBackoff))
+	}
+	err := c.webhookDatabase.RecordWebhookAttempt(ctx, delivery.ID, attempt, status, nextAttemptAt)
+	if err != nil {
+		return errors.Wrap(err, "webhookDatabase.RecordWebhookAttempt failed: ")
+	}
+	return nil
+}
+
+// sendWebhook делает одну попытку, успешна она если получатель ответил 2xx
+func (c *Controller) sendWebhook(ctx context.Context, delivery *types.PendingWebhookDelivery) *types.WebhookAttempt {
+	attempt := &types.WebhookAttempt{AttemptedAt: time.Now()}
+	fail := func(message string) *types.WebhookAttempt {
+		attempt.Error = &message
+		attempt.DurationMs = int(time.Since(attempt.AttemptedAt).Milliseconds())
+		return attempt
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fail(err.Error())
+	}
+	timestamp := strconv.FormatInt(attempt.AttemptedAt.Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "denet-webhooks")
+	request.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(delivery.Secret, timestamp, delivery.Payload))
+	request.Header.Set(WebhookTimestampHeader, timestamp)
+	request.Header.Set(WebhookEventHeader, delivery.EventType)
+	request.Header.Set(WebhookIDHeader, delivery.EventID)
+	request.Header.Set(WebhookDeliveryHeader, strconv.Itoa(delivery.ID))
+
+	response, err := c.webhookClient.Do(request)
+	if err != nil {
+		return fail(err.Error())
+	}
+	defer response.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(response.Body, webhookResponseSnippet))
+	attempt.StatusCode = &response.StatusCode
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fail(response.Status + ": " + string(snippet))
+	}
+	attempt.DurationMs = int(time.Since(attempt.AttemptedAt).Milliseconds())
+	return attempt
+}
+
+// SignWebhook подпись тела запроса вебхука, получатель считает ее так же и сравнивает с заголовком
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookLease на сколько брать пачку из batchSize доставок: они уходят волнами по concurrency, каждая
+// волна - не дольше timeout, еще один timeout - запас на запись результатов
+func webhookLease(batchSize, concurrency int, timeout time.Duration) time.Duration {
+	waves := (batchSize + concurrency - 1) / concurrency
+	return time.Duration(waves+1) * timeout
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SakuraBurst/denet/internal/pkg/backoff"
+	"github.com/SakuraBurst/denet/internal/referrer/config"
+	"github.com/SakuraBurst/denet/internal/referrer/types"
+	"github.com/go-faster/errors"
+)
+
+const testWebhookSecret = "0123456789abcdef"
+
+// fakeWebhookDatabase очередь доставок в памяти, берет и записывает их так же, как webhook_deliveries
+type fakeWebhookDatabase struct {
+	webhookDatabase
+
+	mu         sync.Mutex
+	nextID     int
+	deliveries map[int]*fakeDelivery
+}
+
+type fakeDelivery struct {
+	pending       types.PendingWebhookDelivery
+	status        string
+	nextAttemptAt time.Time
+	attempts      []*types.WebhookAttempt
+}
+
+func newFakeWebhookDatabase() *fakeWebhookDatabase {
+	return &fakeWebhookDatabase{deliveries: map[int]*fakeDelivery{}}
+}
+
+func (f *fakeWebhookDatabase) add(url, eventID string, payload []byte) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	f.deliveries[f.nextID] = &fakeDelivery{
+		pending: types.PendingWebhookDelivery{
+			ID: f.nextID, EventID: eventID, EventType: "task.completed", Payload: payload, URL: url, Secret: testWebhookSecret,
+		},
+		status: types.WebhookDeliveryPending,
+	}
+	return f.nextID
+}
+
+func (f *fakeWebhookDatabase) get(id int) *fakeDelivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.deliveries[id]
+}
+
+func (f *fakeWebhookDatabase) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*types.PendingWebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var claimed []*types.PendingWebhookDelivery
+	for id := 1; id <= f.nextID && len(claimed) < limit; id++ {
+		delivery := f.deliveries[id]
+		if delivery.status != types.WebhookDeliveryPending || delivery.nextAttemptAt.After(now) {
+			continue
+		}
+		delivery.pending.Attempts++
+		delivery.nextAttemptAt = leaseUntil
+		pending := delivery.pending
+		claimed = append(claimed, &pending)
+	}
+	return claimed, nil
+}
+
+func (f *fakeWebhookDatabase) RecordWebhookAttempt(ctx context.Context, deliveryID int, attempt *types.WebhookAttempt, status string, nextAttemptAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delivery := f.deliveries[deliveryID]
+	delivery.attempts = append(delivery.attempts, attempt)
+	delivery.status = status
+	delivery.nextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (f *fakeWebhookDatabase) CreateWebhook(ctx context.Context, webhook *types.WebhookRequest) (int, error) {
+	return 1, nil
+}
+
+func (f *fakeWebhookDatabase) ReplayWebhookDelivery(ctx context.Context, id int) (int, error) {
+	f.mu.Lock()
+	source := f.deliveries[id]
+	f.mu.Unlock()
+	return f.add(source.pending.URL, source.pending.EventID, source.pending.Payload), nil
+}
+
+// webhookReceiver получатель вебхуков, отвечает статусами из statuses по очереди, последний - на все остальные
+type webhookReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	t.Helper()
+	receiver := &webhookReceiver{statuses: statuses}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receiver.mu.Lock()
+		receiver.requests = append(receiver.requests, receivedWebhook{header: r.Header.Clone(), body: body})
+		status := receiver.statuses[min(len(receiver.requests), len(receiver.statuses))-1]
+		receiver.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func (r *webhookReceiver) received() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook(nil), r.requests...)
+}
+
+// newWebhookController контроллер только с вебхуками. Получатель слушает на loopback, поэтому внутренние
+// сети разрешены, как при разработке с локальной заглушкой
+func newWebhookController(db *fakeWebhookDatabase) *Controller {
+	cfg := config.WebhooksConfig{
+		Timeout:              time.Second,
+		MaxAttempts:          3,
+		RetryBackoff:         30 * time.Second,
+		MaxBackoff:           time.Hour,
+		BatchSize:            10,
+		Concurrency:          2,
+		AllowPrivateNetworks: true,
+	}
+	return &Controller{
+		webhookDatabase: db,
+		webhooks:        cfg,
+		webhookClient:   newWebhookClient(cfg.Timeout, cfg.AllowPrivateNetworks),
+	}
+}
+
+func TestDeliverWebhooksSignsTimestampAndBody(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusNoContent)
+	db := newFakeWebhookDatabase()
+	payload := []byte(`{"id":"event-1","type":"task.completed","data":{"user_id":5}}`)
+	id := db.add(receiver.URL, "event-1", payload)
+
+	if err := newWebhookController(db).DeliverWebhooks(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := receiver.received()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	request := requests[0]
+	if string(request.body) != string(payload) {
+		t.Errorf("body: got %s, want %s", request.body, payload)
+	}
+	timestamp := request.header.Get(WebhookTimestampHeader)
+	if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil {
+		t.Fatalf("timestamp %q: %v", timestamp, err)
+	}
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write([]byte(timestamp + "." + string(payload)))
+	if got, want := request.header.Get(WebhookSignatureHeader), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("signature: got %s, want %s", got, want)
+	}
+	if got := request.header.Get(WebhookIDHeader); got != "event-1" {
+		t.Errorf("event id: got %q", got)
+	}
+	if got := request.header.Get(WebhookDeliveryHeader); got != strconv.Itoa(id) {
+		t.Errorf("delivery id: got %q, want %d", got, id)
+	}
+	if got := db.get(id).status; got != types.WebhookDeliveryDelivered {
+		t.Errorf("status: got %s, want delivered", got)
+	}
+}
+
+func TestWebhookLease(t *testing.T) {
+	// 100 доставок по 8 - 13 волн и одна в запас
+	if got, want := webhookLease(100, 8, 10*time.Second), 140*time.Second; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestDeliverWebhooksRetriesWithBackoff(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK)
+	db := newFakeWebhookDatabase()
+	id := db.add(receiver.URL, "event-1", []byte(`{}`))
+	c := newWebhookController(db)
+
+	now := time.Now()
+	for attempt := 1; attempt <= 2; attempt++ {
+		if err := c.DeliverWebhooks(context.Background(), now); err != nil {
+			t.Fatal(err)
+		}
+		delivery := db.get(id)
+		if delivery.status != types.WebhookDeliveryPending {
+			t.Fatalf("attempt %d: status %s, want pending", attempt, delivery.status)
+		}
+		last := delivery.attempts[len(delivery.attempts)-1]
+		if want := last.AttemptedAt.Add(backoff.Exponential(attempt, c.webhooks.RetryBackoff, c.webhooks.MaxBackoff)); !delivery.nextAttemptAt.Equal(want) {
+			t.Errorf("attempt %d: next attempt at %s, want %s", attempt, delivery.nextAttemptAt, want)
+		}
+		if last.StatusCode == nil || *last.StatusCode != http.StatusInternalServerError || last.Error == nil {
+			t.Errorf("attempt %d: got %+v, want a failed 500 attempt", attempt, last)
+		}
+
+		// до следующего повтора доставку не берут
+		if err := c.DeliverWebhooks(context.Background(), now); err != nil {
+			t.Fatal(err)
+		}
+		if got := len(receiver.received()); got != attempt {
+			t.Fatalf("sent %d times before the retry was due, want %d", got, attempt)
+		}
+		now = delivery.nextAttemptAt
+	}
+
+	if err := c.DeliverWebhooks(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+	if got := db.get(id).status; got != types.WebhookDeliveryDelivered {
+		t.Errorf("status: got %s, want delivered", got)
+	}
+}
+
+func TestDeliverWebhooksDeadLettersAfterMaxAttempts(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusServiceUnavailable)
+	db := newFakeWebhookDatabase()
+	id := db.add(receiver.URL, "event-1", []byte(`{}`))
+	c := newWebhookController(db)
+
+	now := time.Now()
+	for range c.webhooks.MaxAttempts + 2 {
+		if err := c.DeliverWebhooks(context.Background(), now); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(c.webhooks.MaxBackoff)
+	}
+
+	delivery := db.get(id)
+	if delivery.status != types.WebhookDeliveryDead {
+		t.Errorf("status: got %s, want dead", delivery.status)
+	}
+	if got := len(delivery.attempts); got != c.webhooks.MaxAttempts {
+		t.Errorf("attempts: got %d, want %d", got, c.webhooks.MaxAttempts)
+	}
+	if got := len(receiver.received()); got != c.webhooks.MaxAttempts {
+		t.Errorf("requests: got %d, want %d", got, c.webhooks.MaxAttempts)
+	}
+}
+
+func TestReplayWebhookDeliveryResendsEvent(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusServiceUnavailable, http.StatusOK)
+	db := newFakeWebhookDatabase()
+	id := db.add(receiver.URL, "event-1", []byte(`{"id":"event-1"}`))
+	c := newWebhookController(db)
+	c.webhooks.MaxAttempts = 1
+
+	if err := c.DeliverWebhooks(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if got := db.get(id).status; got != types.WebhookDeliveryDead {
+		t.Fatalf("status: got %s, want dead", got)
+	}
+
+	replayID, err := c.ReplayWebhookDelivery(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.DeliverWebhooks(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := db.get(replayID).status; got != types.WebhookDeliveryDelivered {
+		t.Errorf("replay status: got %s, want delivered", got)
+	}
+	if got := db.get(id).status; got != types.WebhookDeliveryDead {
+		t.Errorf("original status: got %s, want dead", got)
+	}
+	requests := receiver.received()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	// получатель узнает повтор по id события, доставки у него разные
+	if requests[0].header.Get(WebhookIDHeader) != requests[1].header.Get(WebhookIDHeader) {
+		t.Errorf("event ids differ: %q and %q", requests[0].header.Get(WebhookIDHeader), requests[1].header.Get(WebhookIDHeader))
+	}
+	if got := requests[1].header.Get(WebhookDeliveryHeader); got != strconv.Itoa(replayID) {
+		t.Errorf("replay delivery id: got %q, want %d", got, replayID)
+	}
+	if string(requests[0].body) != string(requests[1].body) {
+		t.Errorf("bodies differ: %s and %s", requests[0].body, requests[1].body)
+	}
+}
+
+func TestWebhookClientRejectsInternalAddresses(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusOK)
+	_, err := newWebhookClient(time.Second, false).Post(receiver.URL, "application/json", nil)
+	if !errors.Is(err, errWebhookAddressForbidden) {
+		t.Errorf("got %v, want errWebhookAddressForbidden", err)
+	}
+	if got := len(receiver.received()); got != 0 {
+		t.Errorf("receiver got %d requests", got)
+	}
+}
+
+func TestCreateWebhookChecksPrivateNetworks(t *testing.T) {
+	for _, tc := range []struct {
+		url          string
+		allowPrivate bool
+		want         error
+	}{
+		{url: "https://example.com/hook", want: nil},
+		{url: "http://127.0.0.1:8080/hook", want: ErrWebhookURLNotPublic},
+		{url: "http://stub.internal/hook", want: ErrWebhookURLNotPublic},
+		{url: "http://127.0.0.1:8080/hook", allowPrivate: true, want: nil},
+	} {
+		c := newWebhookController(newFakeWebhookDatabase())
+		c.webhooks.AllowPrivateNetworks = tc.allowPrivate
+		_, err := c.CreateWebhook(context.Background(), &types.WebhookRequest{URL: tc.url})
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s with allow_private_networks=%t: got %v, want %v", tc.url, tc.allowPrivate, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package types
+
+import (
+	"database/sql/driver"
+	"errors"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// AmountDecimals сколько знаков после запятой хранится в Amount
+const AmountDecimals = 2
+
+const amountScale = 100
+
+var (
+	ErrInvalidAmount   = errors.New("invalid amount")
+	ErrAmountPrecision = errors.New("amount has too many decimal places")
+	ErrAmountOverflow  = errors.New("amount overflow")
+)
+
+// Amount сумма с фиксированной точкой, хранится в сотых долях единицы валюты.
+// В JSON отдается строкой, чтобы клиенты не теряли точность на float, в базе лежит как numeric
+type Amount int64
+
+// NewAmount возвращает сумму в units целых единиц
+func NewAmount(units int64) Amount {
+	return Amount(units * amountScale)
+}
+
+// ParseAmount разбирает десятичную строку вида "12", "-0.5", "100.25"
+func ParseAmount(s string) (Amount, error) {
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	intPart, fracPart, hasDot := strings.Cut(s, ".")
+	if intPart == "" && (!hasDot || fracPart == "") {
+		return 0, ErrInvalidAmount
+	}
+	if hasDot && fracPart == "" {
+		return 0, ErrInvalidAmount
+	}
+	// лишние нули в дробной части не меняют значение, numeric из базы их отдает
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > AmountDecimals {
+		return 0, ErrAmountPrecision
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, ErrInvalidAmount
+	}
+
+	var units int64
+	if intPart != "" {
+		var err error
+		units, err = strconv.ParseInt(intPart, 10, 64)
+		if err != nil {
+			return 0, ErrAmountOverflow
+		}
+	}
+	if units > math.MaxInt64/amountScale {
+		return 0, ErrAmountOverflow
+	}
+	var frac int64
+	if fracPart != "" {
+		frac, _ = strconv.ParseInt(fracPart+strings.Repeat("0", AmountDecimals-len(fracPart)), 10, 64)
+	}
+	value := units*amountScale + frac
+	if value < 0 {
+		return 0, ErrAmountOverflow
+	}
+	if neg {
+		value = -value
+	}
+	return Amount(value), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (a Amount) String() string {
+	value := int64(a)
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	units, frac := value/amountScale, value%amountScale
+	if frac == 0 {
+		return sign + strconv.FormatInt(units, 10)
+	}
+	fracStr := strconv.FormatInt(frac+amountScale, 10)[1:]
+	return sign + strconv.FormatInt(units, 10) + "." + strings.TrimRight(fracStr, "0")
+}
+
+// Add складывает суммы, ErrAmountOverflow - сумма не помещается в Amount
+func (a Amount) Add(b Amount) (Amount, error) {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		return 0, ErrAmountOverflow
+	}
+	return sum, nil
+}
+
+// Mul умножает сумму на целое число, ErrAmountOverflow - произведение не помещается в Amount
+func (a Amount) Mul(n int) (Amount, error) {
+	return a.MulRatio(int64(n), 1)
+}
+
+// MulRatio умножает сумму на num/den, округляя к меньшему по модулю. Промежуточное произведение считается
+// в 128 битах, ErrAmountOverflow возвращается только если не помещается результат
+func (a Amount) MulRatio(num, den int64) (Amount, error) {
+	if den == 0 {
+		return 0, ErrInvalidAmount
+	}
+	hi, lo := bits.Mul64(abs(int64(a)), abs(num))
+	if hi >= abs(den) {
+		return 0, ErrAmountOverflow
+	}
+	quo, _ := bits.Div64(hi, lo, abs(den))
+	negative := (a < 0) != (num < 0) != (den < 0)
+	if quo > math.MaxInt64 && !(negative && quo == -math.MinInt64) {
+		return 0, ErrAmountOverflow
+	}
+	if negative {
+		return Amount(-quo), nil
+	}
+	return Amount(quo), nil
+}
+
+// abs модуль без переполнения на math.MinInt64
+func abs(v int64) uint64 {
+	if v < 0 {
+		return uint64(-v)
+	}
+	return uint64(v)
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(a.String())), nil
+}
+
+// UnmarshalJSON принимает как строку "12.5", так и число 12.5, число разбирается без перевода во float
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	value, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = value
+	return nil
+}
+
+func (a *Amount) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		value, err := ParseAmount(v)
+		if err != nil {
+			return err
+		}
+		*a = value
+	case []byte:
+		value, err := ParseAmount(string(v))
+		if err != nil {
+			return err
+		}
+		*a = value
+	case int64:
+		*a = NewAmount(v)
+	default:
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
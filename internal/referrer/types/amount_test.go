@@ -0,0 +1,58 @@
+package types
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAmountMulRatio(t *testing.T) {
+	cases := []struct {
+		name     string
+		amount   Amount
+		num, den int64
+		want     Amount
+		wantErr  error
+	}{
+		{name: "percent", amount: NewAmount(10), num: 150, den: 100, want: NewAmount(15)},
+		{name: "rounds toward zero", amount: 1, num: 1, den: 3, want: 0},
+		{name: "negative rounds toward zero", amount: -5, num: 1, den: 3, want: -1},
+		{name: "large intermediate product", amount: math.MaxInt64 / 2, num: 200, den: 100, want: math.MaxInt64 - 1},
+		{name: "min amount", amount: math.MinInt64, num: 1, den: 1, want: math.MinInt64},
+		{name: "overflow", amount: math.MaxInt64 / 2, num: 3, den: 1, wantErr: ErrAmountOverflow},
+		{name: "negative overflow", amount: math.MinInt64, num: -1, den: 1, wantErr: ErrAmountOverflow},
+		{name: "zero denominator", amount: 1, num: 1, den: 0, wantErr: ErrInvalidAmount},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.amount.MulRatio(tc.num, tc.den)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("error: got %v, want %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAmountMulOverflow(t *testing.T) {
+	if _, err := NewAmount(1_000_000_000).Mul(1_000_000_000_000); !errors.Is(err, ErrAmountOverflow) {
+		t.Errorf("got %v, want ErrAmountOverflow", err)
+	}
+	if got, err := NewAmount(3).Mul(4); err != nil || got != NewAmount(12) {
+		t.Errorf("got %s, %v, want 12", got, err)
+	}
+}
+
+func TestAmountAddOverflow(t *testing.T) {
+	if _, err := Amount(math.MaxInt64).Add(1); !errors.Is(err, ErrAmountOverflow) {
+		t.Errorf("got %v, want ErrAmountOverflow", err)
+	}
+	if _, err := Amount(math.MinInt64).Add(-1); !errors.Is(err, ErrAmountOverflow) {
+		t.Errorf("got %v, want ErrAmountOverflow", err)
+	}
+	if got, err := NewAmount(3).Add(NewAmount(-4)); err != nil || got != NewAmount(-1) {
+		t.Errorf("got %s, %v, want -1", got, err)
+	}
+}
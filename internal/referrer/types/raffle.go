@@ -0,0 +1,54 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"slices"
+	"strconv"
+)
+
+// RaffleSeedHash хэш seed, который публикуется при создании розыгрыша
+func RaffleSeedHash(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
+
+// RaffleTicketsHash хэш итогового списка билетов: sha256 от строк "<номер>:<id владельца>\n" по возрастанию
+// номера. Список складывается только к закрытию продаж, поэтому загадавший seed не знает хэш заранее
+func RaffleTicketsHash(tickets []*RaffleTicket) string {
+	sorted := slices.SortedFunc(slices.Values(tickets), func(a, b *RaffleTicket) int { return a.Number - b.Number })
+	h := sha256.New()
+	for _, ticket := range sorted {
+		h.Write([]byte(strconv.Itoa(ticket.Number) + ":" + strconv.Itoa(ticket.UserID) + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RaffleDrawKey ключ, по которому разыгрываются места: seed вместе с хэшем итогового списка билетов
+func RaffleDrawKey(seed, ticketsHash string) string {
+	return seed + ":" + ticketsHash
+}
+
+// DrawRaffleTickets выбирает номера выигравших билетов для мест с 1 по places из tickets проданных.
+// Номер для места p - это sha256("key:p:attempt") по модулю tickets плюс один, attempt начинается с нуля
+// и увеличивается, пока не выпадет билет, еще не выигравший другое место. Если билетов меньше чем мест,
+// разыгрываются только первые места
+func DrawRaffleTickets(key string, tickets, places int) []int {
+	places = min(places, tickets)
+	won := make(map[int]bool, places)
+	result := make([]int, 0, places)
+	modulus := big.NewInt(int64(tickets))
+	for place := 1; place <= places; place++ {
+		for attempt := 0; ; attempt++ {
+			sum := sha256.Sum256([]byte(key + ":" + strconv.Itoa(place) + ":" + strconv.Itoa(attempt)))
+			number := int(new(big.Int).Mod(new(big.Int).SetBytes(sum[:]), modulus).Int64()) + 1
+			if !won[number] {
+				won[number] = true
+				result = append(result, number)
+				break
+			}
+		}
+	}
+	return result
+}
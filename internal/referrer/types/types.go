@@ -1,5 +1,10 @@
 package types
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type User struct {
 	ID           int
 	FirstName    string `json:"first_name"`
@@ -7,9 +12,18 @@ type User struct {
 	UserName     string `json:"user_name"`
 	Password     string `json:"-"`
 	ReferrerCode string `json:"referrer_code"`
-	Balance      int    `json:"balance"`
+	Balance      Amount `json:"balance"`
+	Role         string `json:"role"`
+	ReferrerID   *int   `json:"referrer_id"`
 }
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+	// RoleTrusted проверенные пользователи, для которых ослаблены ограничения на заработок
+	RoleTrusted = "trusted"
+)
+
 type FullUser struct {
 	ID             int
 	FirstName      string  `json:"first_name"`
@@ -17,27 +31,689 @@ type FullUser struct {
 	UserName       string  `json:"user_name"`
 	Password       string  `json:"-"`
 	ReferrerCode   string  `json:"referrer_code"`
-	Balance        int     `json:"balance"`
+	Balance        Amount  `json:"balance"`
 	CompletedTasks []*Task `json:"completed_tasks"`
+	// Balances балансы во всех валютах, в том числе в баллах
+	Balances map[string]Amount `json:"balances"`
+	// UpcomingExpirations баллы, которые скоро сгорят
+	UpcomingExpirations []*Expiration      `json:"upcoming_expirations"`
+	Achievements        []*UserAchievement `json:"achievements"`
+	XP                  Amount             `json:"xp"`
+	Level               *LevelProgress     `json:"level"`
+}
+
+type Expiration struct {
+	Amount    Amount    `json:"amount"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
+// UserRequest регистрация пользователя. Пароль не длиннее 72 байт - дальше bcrypt его не различает
 type UserRequest struct {
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	UserName  string `json:"user_name"`
-	Password  string `json:"password"`
+	FirstName string `json:"first_name" validate:"omitempty,max=64"`
+	LastName  string `json:"last_name" validate:"omitempty,max=64"`
+	UserName  string `json:"user_name" validate:"required,min=3,max=32,username"`
+	Password  string `json:"password" validate:"required,min=8,max=72"`
+}
+
+// LoginRequest вход, ограничения мягче чем при регистрации, чтобы не отрезать старых пользователей
+type LoginRequest struct {
+	UserName string `json:"user_name" validate:"required,max=256"`
+	Password string `json:"password" validate:"required,max=256"`
+}
+
+// Completion выполненное задание, CompletedAt нет у заданий, выполненных до того, как стали хранить время
+type Completion struct {
+	UserID      int        `json:"user_id"`
+	TaskID      int        `json:"task_id"`
+	CompletedAt *time.Time `json:"completed_at"`
 }
 
 type CompleteTaskRequest struct {
-	TaskId int `json:"task_id"`
+	TaskId int `json:"task_id" validate:"required,gt=0"`
 }
 
 type ReferrerRequest struct {
-	ReferrerCode string `json:"referrer_code"`
+	ReferrerCode string `json:"referrer_code" validate:"required,uuid"`
 }
 
+// валюты, в которых начисляются награды. Баллы - основная валюта: в ней считаются таблицы лидеров,
+// сезоны, магазин, переводы и сгорание, остальные валюты только копятся на балансе
+const (
+	CurrencyPoints = "points"
+	CurrencyGems   = "gems"
+)
+
 type Task struct {
 	ID          int    `json:"id"`
+	Description string `json:"description" validate:"required,max=1000"`
+	Reward      Amount `json:"reward" validate:"gt=0"`
+	Currency    string `json:"currency" validate:"omitempty,max=32,alpha,lowercase"`
+}
+
+type UpdateTaskRewardRequest struct {
+	Reward Amount `json:"reward" validate:"gt=0"`
+}
+
+// Balance баланс пользователя в одной валюте
+type Balance struct {
+	Currency string `json:"currency"`
+	Amount   Amount `json:"amount"`
+}
+
+type RankedUser struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	UserName  string `json:"user_name"`
+	Balance   Amount `json:"balance"`
+	Rank      int    `json:"rank"`
+	DenseRank int    `json:"dense_rank"`
+	Position  int    `json:"position"`
+}
+
+type LeaderBoard struct {
+	Users  []*RankedUser `json:"users"`
+	Total  int           `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+type UserRank struct {
+	User  *RankedUser   `json:"user"`
+	Above []*RankedUser `json:"above"`
+	Below []*RankedUser `json:"below"`
+}
+
+// источники начислений в reward_events
+const (
+	RewardSourceTask        = "task"
+	RewardSourceReferral    = "referral"
+	RewardSourceSeasonPrize = "season_prize"
+)
+
+const NotificationSeasonPrize = "season_prize"
+
+// отношения пользователей в реферальной сети
+const (
+	RelationSelf     = "self"
+	RelationReferrer = "referrer"
+	RelationReferee  = "referee"
+	RelationSibling  = "sibling"
+)
+
+// NetworkRankedUser это место в таблице лидеров реферальной сети пользователя, Relation - кем он приходится пользователю
+type NetworkRankedUser struct {
+	RankedUser
+	Relation string `json:"relation"`
+}
+
+type NetworkLeaderBoard struct {
+	User  *NetworkRankedUser   `json:"user"`
+	Users []*NetworkRankedUser `json:"users"`
+}
+
+type Period string
+
+const (
+	PeriodDaily   Period = "daily"
+	PeriodWeekly  Period = "weekly"
+	PeriodMonthly Period = "monthly"
+	PeriodAllTime Period = "all"
+	PeriodCustom  Period = "custom"
+)
+
+// PeriodRankedUser это место пользователя в таблице за период, Earned - сколько он заработал за этот период
+type PeriodRankedUser struct {
+	RankedUser
+	Earned Amount `json:"earned"`
+}
+
+type PeriodLeaderBoard struct {
+	Period Period              `json:"period"`
+	From   *time.Time          `json:"from,omitempty"`
+	To     *time.Time          `json:"to,omitempty"`
+	Final  bool                `json:"final"`
+	Users  []*PeriodRankedUser `json:"users"`
+	Total  int                 `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+type Season struct {
+	ID       int            `json:"id"`
+	Name     string         `json:"name"`
+	StartsAt time.Time      `json:"starts_at"`
+	EndsAt   time.Time      `json:"ends_at"`
+	ClosedAt *time.Time     `json:"closed_at"`
+	Prizes   []*SeasonPrize `json:"prizes"`
+}
+
+// SeasonPrize начисляется пользователю, занявшему место Place по итогам сезона
+type SeasonPrize struct {
+	Place  int    `json:"place"`
+	Reward Amount `json:"reward"`
+}
+
+// SeasonRankedUser это место пользователя в сезоне, Points - очки набранные за сезон
+type SeasonRankedUser struct {
+	RankedUser
+	Points Amount `json:"points"`
+	Prize  Amount `json:"prize"`
+}
+
+type SeasonLeaderBoard struct {
+	Season *Season             `json:"season"`
+	Final  bool                `json:"final"`
+	Users  []*SeasonRankedUser `json:"users"`
+	Total  int                 `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+// ShopItem товар, который можно купить за баланс. Stock == nil - товар не заканчивается,
+// PerUserLimit == nil - один пользователь может купить сколько угодно
+type ShopItem struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Price        Amount `json:"price"`
+	Stock        *int   `json:"stock"`
+	PerUserLimit *int   `json:"per_user_limit"`
+	Active       bool   `json:"active"`
+}
+
+type RedeemRequest struct {
+	ItemID   int `json:"item_id" validate:"required,gt=0"`
+	Quantity int `json:"quantity" validate:"max=1000"`
+}
+
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusFulfilled = "fulfilled"
+	OrderStatusCancelled = "cancelled"
+)
+
+// Order покупка пользователя, Price - сколько всего списано с баланса
+type Order struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	ItemID    int       `json:"item_id"`
+	Quantity  int       `json:"quantity"`
+	Price     Amount    `json:"price"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// виды записей в ledger_entries, начисления пишутся с источником из reward_events
+const (
+	LedgerTransferOut   = "transfer_out"
+	LedgerTransferIn    = "transfer_in"
+	LedgerOrder         = "order"
+	LedgerOrderRefund   = "order_refund"
+	LedgerRaffleTickets = "raffle_tickets"
+)
+
+type TransferRequest struct {
+	ToUserID int    `json:"to_user_id"`
+	Amount   Amount `json:"amount"`
+}
+
+type Transfer struct {
+	ID         int       `json:"id"`
+	FromUserID int       `json:"from_user_id"`
+	ToUserID   int       `json:"to_user_id"`
+	Amount     Amount    `json:"amount"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Replayed значит, что перевод с таким ключом уже был и повторно не выполнялся
+	Replayed bool `json:"-"`
+}
+
+// TransferLimits ограничения на переводы: DailyLimit сколько можно отправить начиная с DayStart (0 - без лимита),
+// переводить могут только зарегистрированные раньше RegisteredBefore
+type TransferLimits struct {
+	DailyLimit       Amount
+	DayStart         time.Time
+	RegisteredBefore time.Time
+}
+
+// IdempotentResponse сохраненный ответ на запрос с заголовком Idempotency-Key
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+const (
+	RewardSourceClawback = "clawback"
+	LedgerClawback       = "clawback"
+	LedgerExpiry         = "expiry"
+)
+
+// источники партий баланса помимо начислений
+const (
+	LotSourceRefund   = "refund"
+	LotSourceTransfer = "transfer"
+	// LotSourceLegacy балансы, которые были до появления партий
+	LotSourceLegacy = "legacy"
+)
+
+const (
+	ClawbackKindTask     = "task"
+	ClawbackKindReferral = "referral"
+)
+
+// Clawback отзыв начисления: Amount - сколько было начислено, Clawed - сколько реально списано с баланса
+type Clawback struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	Kind        string    `json:"kind"`
+	ReferenceID int       `json:"reference_id"`
+	Currency    string    `json:"currency"`
+	Amount      Amount    `json:"amount"`
+	Clawed      Amount    `json:"clawed"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RevokeRequest struct {
+	Reason string `json:"reason"`
+}
+
+const NotificationClawback = "clawback"
+
+type Notification struct {
+	ID        int        `json:"id"`
+	UserID    int        `json:"user_id"`
+	Kind      string     `json:"kind"`
+	Message   string     `json:"message"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at"`
+}
+
+// какое ограничение на заработок сработало
+const (
+	LimitDailyReward       = "daily_reward"
+	LimitHourlyCompletions = "hourly_completions"
+	LimitCooldown          = "cooldown"
+)
+
+// EarningLimits ограничения на заработок, 0 - без ограничения
+type EarningLimits struct {
+	MaxDailyReward        Amount
+	MaxCompletionsPerHour int
+	Cooldown              time.Duration
+}
+
+// EarningPolicy ограничения на заработок по умолчанию и для отдельных ролей на момент Now,
+// дневной лимит считается в промежутке [DayStart, DayEnd). Levels задают множители наград по уровням
+type EarningPolicy struct {
+	Default  EarningLimits
+	Roles    map[string]EarningLimits
+	Levels   Levels
+	Now      time.Time
+	DayStart time.Time
+	DayEnd   time.Time
+}
+
+// For возвращает ограничения для пользователя с ролью role
+func (p EarningPolicy) For(role string) EarningLimits {
+	if limits, ok := p.Roles[role]; ok {
+		return limits
+	}
+	return p.Default
+}
+
+// виды достижений: за сколько выполненных заданий, приглашенных друзей, дней подряд с заданиями
+// или за какое место в общей таблице лидеров (не ниже Threshold) оно выдается
+const (
+	AchievementTasksCompleted  = "tasks_completed"
+	AchievementReferrals       = "referrals"
+	AchievementStreakDays      = "streak_days"
+	AchievementLeaderBoardRank = "leaderboard_rank"
+)
+
+const (
+	RewardSourceAchievement = "achievement"
+	NotificationAchievement = "achievement"
+)
+
+// Achievement достижение, Bonus - сколько баллов начисляется один раз при получении
+type Achievement struct {
+	ID          int    `json:"id"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
 	Description string `json:"description"`
-	Reward      int    `json:"reward"`
+	Kind        string `json:"kind"`
+	Threshold   int    `json:"threshold"`
+	Bonus       Amount `json:"bonus"`
+}
+
+type UserAchievement struct {
+	Achievement
+	AwardedAt time.Time `json:"awarded_at"`
+}
+
+const NotificationLevelUp = "level_up"
+
+// Level уровень, который дается с XP опыта. RewardPercent - сколько процентов награды за задание
+// получает пользователь этого уровня, ReferralPercent - сколько процентов награды за реферала
+type Level struct {
+	Number          int
+	XP              Amount
+	RewardPercent   int
+	ReferralPercent int
+}
+
+// Levels уровни по возрастанию опыта, первый дается с нуля
+type Levels []Level
+
+// defaultLevel единственный уровень, если уровни не настроены
+var defaultLevel = Level{Number: 1, RewardPercent: 100, ReferralPercent: 100}
+
+// For возвращает уровень пользователя с опытом xp
+func (l Levels) For(xp Amount) Level {
+	level := defaultLevel
+	for _, next := range l {
+		if next.XP > xp {
+			break
+		}
+		level = next
+	}
+	return level
+}
+
+// Progress возвращает уровень пользователя с опытом xp и сколько пройдено до следующего
+func (l Levels) Progress(xp Amount) *LevelProgress {
+	level := l.For(xp)
+	progress := &LevelProgress{Level: level.Number, XP: xp, LevelXP: level.XP, Progress: 100}
+	if level.Number < len(l) {
+		next := l[level.Number].XP
+		progress.NextLevelXP = &next
+		progress.Progress = int((xp - level.XP) * 100 / (next - level.XP))
+	}
+	return progress
+}
+
+// LevelProgress уровень пользователя: LevelXP - с какого опыта начинается текущий уровень,
+// NextLevelXP - следующий (nil на последнем уровне), Progress - сколько процентов пути до него пройдено
+type LevelProgress struct {
+	Level       int     `json:"level"`
+	XP          Amount  `json:"xp"`
+	LevelXP     Amount  `json:"level_xp"`
+	NextLevelXP *Amount `json:"next_level_xp"`
+	Progress    int     `json:"progress"`
+}
+
+const (
+	RewardSourceTeamGoal = "team_goal"
+	NotificationTeamGoal = "team_goal"
+)
+
+// Team команда пользователей, Points - сколько баллов участники заработали на заданиях, пока были в команде
+type Team struct {
+	ID         int           `json:"id"`
+	Name       string        `json:"name"`
+	InviteCode string        `json:"invite_code"`
+	OwnerID    *int          `json:"owner_id"`
+	Points     Amount        `json:"points"`
+	CreatedAt  time.Time     `json:"created_at"`
+	Members    []*TeamMember `json:"members"`
+	Goals      []*TeamGoal   `json:"goals"`
+}
+
+type TeamMember struct {
+	ID           int       `json:"id"`
+	FirstName    string    `json:"first_name"`
+	LastName     string    `json:"last_name"`
+	UserName     string    `json:"user_name"`
+	TeamJoinedAt time.Time `json:"joined_at"`
+}
+
+// TeamGoal цель команды: когда участники заработают Target баллов после ее создания,
+// каждый участник получит Bonus
+type TeamGoal struct {
+	ID          int        `json:"id"`
+	TeamID      int        `json:"team_id"`
+	Description string     `json:"description"`
+	Target      Amount     `json:"target"`
+	Progress    Amount     `json:"progress"`
+	Bonus       Amount     `json:"bonus"`
+	ReachedAt   *time.Time `json:"reached_at"`
+	PaidAt      *time.Time `json:"paid_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type CreateTeamRequest struct {
+	Name string `json:"name"`
+}
+
+type JoinTeamRequest struct {
+	InviteCode string `json:"invite_code" validate:"required"`
+}
+
+// RankedTeam место команды в таблице команд
+type RankedTeam struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	Points    Amount `json:"points"`
+	Members   int    `json:"members"`
+	Rank      int    `json:"rank"`
+	DenseRank int    `json:"dense_rank"`
+	Position  int    `json:"position"`
+}
+
+type TeamLeaderBoard struct {
+	Teams  []*RankedTeam `json:"teams"`
+	Total  int           `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+const (
+	RewardSourceRaffle = "raffle"
+	NotificationRaffle = "raffle"
+)
+
+// Raffle розыгрыш, билеты на который покупаются за баллы. Seed загадывается при создании и до розыгрыша
+// виден только его хэш SeedHash, после розыгрыша Seed раскрывается, чтобы любой мог проверить
+// победителей через DrawRaffleTickets
+type Raffle struct {
+	ID                int             `json:"id"`
+	Name              string          `json:"name"`
+	Description       string          `json:"description"`
+	TicketPrice       Amount          `json:"ticket_price"`
+	MaxTicketsPerUser *int            `json:"max_tickets_per_user"`
+	DrawAt            time.Time       `json:"draw_at"`
+	SeedHash          string          `json:"seed_hash"`
+	Seed              *string         `json:"seed"`
+	TicketsHash       *string         `json:"tickets_hash"`
+	TicketsSold       int             `json:"tickets_sold"`
+	DrawnAt           *time.Time      `json:"drawn_at"`
+	CreatedAt         time.Time       `json:"created_at"`
+	Prizes            []*RafflePrize  `json:"prizes"`
+	Winners           []*RaffleWinner `json:"winners"`
+}
+
+// RafflePrize начисляется владельцу билета, выпавшего на место Place
+type RafflePrize struct {
+	Place  int    `json:"place"`
+	Reward Amount `json:"reward"`
+}
+
+type RaffleWinner struct {
+	Place        int    `json:"place"`
+	TicketNumber int    `json:"ticket_number"`
+	UserID       int    `json:"user_id"`
+	Reward       Amount `json:"reward"`
+}
+
+type RaffleTicket struct {
+	Number int `json:"number"`
+	UserID int `json:"user_id"`
+}
+
+type BuyRaffleTicketsRequest struct {
+	Quantity int `json:"quantity" validate:"max=1000"`
+}
+
+// RaffleTickets купленные за раз билеты с номерами с FirstNumber по LastNumber
+type RaffleTickets struct {
+	RaffleID    int    `json:"raffle_id"`
+	FirstNumber int    `json:"first_number"`
+	LastNumber  int    `json:"last_number"`
+	Price       Amount `json:"price"`
+	Balance     Amount `json:"balance"`
+}
+
+// виды событий, которые пользователи получают по WebSocket и SSE
+const (
+	EventBalanceChanged = "balance_changed"
+	EventRefereeJoined  = "referee_joined"
+	EventRankChanged    = "rank_changed"
+)
+
+// Event событие для пользователя UserID, содержимое Data зависит от Kind
+type Event struct {
+	Kind      string    `json:"kind"`
+	UserID    int       `json:"user_id"`
+	Data      any       `json:"data"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewEvent(kind string, userID int, data any) *Event {
+	return &Event{Kind: kind, UserID: userID, Data: data, CreatedAt: time.Now()}
+}
+
+// BalanceChangedEvent новый баланс пользователя в валюте Currency
+type BalanceChangedEvent struct {
+	Currency string `json:"currency"`
+	Balance  Amount `json:"balance"`
+}
+
+type RefereeJoinedEvent struct {
+	RefereeID int    `json:"referee_id"`
+	UserName  string `json:"user_name"`
+}
+
+// RankChangedEvent новое место пользователя в общей таблице лидеров и позиция до изменения
+type RankChangedEvent struct {
+	Rank             int `json:"rank"`
+	DenseRank        int `json:"dense_rank"`
+	Position         int `json:"position"`
+	PreviousPosition int `json:"previous_position"`
+}
+
+// виды событий, на которые можно подписать вебхук
+const (
+	WebhookUserRegistered  = "user.registered"
+	WebhookTaskCompleted   = "task.completed"
+	WebhookReferralCreated = "referral.created"
+)
+
+// статусы доставки вебхука: pending ждет отправки или повтора, delivered принята получателем,
+// dead - попытки кончились, доставку можно только повторить вручную
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// Webhook подписка внешнего сервиса на события, секрет наружу не отдается
+type Webhook struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookRequest создание или замена подписки. Secret - ключ HMAC-SHA256 подписи запросов
+type WebhookRequest struct {
+	URL        string   `json:"url" validate:"required,http_url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=user.registered task.completed referral.created"`
+	Secret     string   `json:"secret" validate:"required,min=16,max=256"`
+	Active     bool     `json:"active"`
+}
+
+// WebhookEvent тело запроса вебхука и сообщения outbox. ID один у всех доставок и повторов события,
+// по нему получатель отбрасывает дубли
+type WebhookEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+type UserRegisteredWebhook struct {
+	UserID    int    `json:"user_id"`
+	UserName  string `json:"user_name"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// TaskCompletedWebhook Reward - сколько начислено с учетом уровня пользователя
+type TaskCompletedWebhook struct {
+	UserID   int    `json:"user_id"`
+	TaskID   int    `json:"task_id"`
+	Reward   Amount `json:"reward"`
+	Currency string `json:"currency"`
+}
+
+type ReferralCreatedWebhook struct {
+	RefereeID      int    `json:"referee_id"`
+	ReferrerID     int    `json:"referrer_id"`
+	RefereeReward  Amount `json:"referee_reward"`
+	ReferrerReward Amount `json:"referrer_reward"`
+}
+
+// WebhookDelivery отправка одного события одной подписке, Attempts - сколько раз ее пытались отправить,
+// AttemptLog заполняется только при запросе одной доставки
+type WebhookDelivery struct {
+	ID             int             `json:"id"`
+	WebhookID      int             `json:"webhook_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	// ReplayOf первая доставка события, если это повтор через replay
+	ReplayOf   *int              `json:"replay_of"`
+	AttemptLog []*WebhookAttempt `json:"attempt_log,omitempty" db:"-"`
+}
+
+// WebhookAttempt одна попытка доставки. StatusCode нет, если получатель не ответил
+type WebhookAttempt struct {
+	StatusCode  *int      `json:"status_code"`
+	Error       *string   `json:"error"`
+	DurationMs  int       `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// PendingWebhookDelivery доставка, взятая на отправку, вместе с адресом и секретом подписки
+type PendingWebhookDelivery struct {
+	ID        int
+	EventID   string
+	EventType string
+	Payload   []byte
+	Attempts  int
+	URL       string
+	Secret    string
+}
+
+// OutboxMessage событие, записанное в outbox в одной транзакции с изменением, которое его вызвало.
+// Payload - WebhookEvent в JSON. Сообщение публикуется хотя бы один раз: после сбоя оно может уйти
+// повторно с тем же EventID, по нему получатели отбрасывают дубли
+type OutboxMessage struct {
+	ID        int64           `json:"-"`
+	EventID   string          `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	CreatedAt time.Time       `json:"created_at"`
 }
@@ -0,0 +1,143 @@
+package validation
+
+import (
+	"net/netip"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-faster/errors"
+	"github.com/go-playground/validator/v10"
+)
+
+// UserNamePattern допустимые символы ника: латиница, цифры и _.-
+var UserNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// validate проверяет запросы по тегам validate, в ошибках поля называются как в json
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	err := v.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+		return UserNamePattern.MatchString(fl.Field().String())
+	})
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// internalNetworks служебные сети, которых нет среди loopback, частных и link-local: "эта" сеть, CGNAT,
+// служебная сеть IETF и сеть для тестов производительности
+var internalNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// internalZones зоны, имена в которых разрешаются только внутри сети
+var internalZones = []string{".localhost", ".local", ".localdomain", ".internal", ".home.arpa"}
+
+// PublicAddr можно ли слать запросы на addr: не loopback, не частная, не link-local и не служебная сеть
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, network := range internalNetworks {
+		if network.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// PublicHost можно ли слать запросы на host из адреса. Имя проверяется без DNS: отсекаются имена без точки
+// и внутренние зоны, а куда имя указывает на самом деле, проверяется при соединении
+func PublicHost(host string) bool {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return PublicAddr(addr)
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" || !strings.Contains(host, ".") {
+		return false
+	}
+	for _, zone := range internalZones {
+		if strings.HasSuffix(host, zone) {
+			return false
+		}
+	}
+	return true
+}
+
+// Violation нарушенное правило одного поля запроса
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+var ruleMessages = map[string]string{
+	"required":  "Обязательное поле",
+	"min":       "Слишком короткое значение, минимум %s",
+	"max":       "Слишком длинное значение, максимум %s",
+	"gt":        "Должно быть больше %s",
+	"gte":       "Должно быть не меньше %s",
+	"username":  "Допустимы только латинские буквы, цифры и символы _.-",
+	"uuid":      "Должно быть в формате UUID",
+	"alpha":     "Допустимы только латинские буквы",
+	"lowercase": "Допустимы только строчные буквы",
+	"http_url":  "Должно быть адресом http или https",
+	"oneof":     "Допустимые значения: %s",
+}
+
+// numberRuleMessages сообщения правил, которые у чисел проверяют значение, а не длину
+var numberRuleMessages = map[string]string{
+	"min": "Должно быть не меньше %s",
+	"max": "Должно быть не больше %s",
+}
+
+// Struct проверяет структуру по тегам validate и возвращает все нарушения сразу, nil - нарушений нет
+func Struct(request any) ([]Violation, error) {
+	err := validate.Struct(request)
+	if err == nil {
+		return nil, nil
+	}
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil, errors.Wrap(err, "validate.Struct failed: ")
+	}
+	violations := make([]Violation, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		message := ruleMessages[fieldErr.Tag()]
+		if numberMessage, ok := numberRuleMessages[fieldErr.Tag()]; ok && isNumber(fieldErr.Kind()) {
+			message = numberMessage
+		}
+		if message == "" {
+			message = "Неправильное значение"
+		}
+		if strings.Contains(message, "%s") {
+			message = strings.Replace(message, "%s", fieldErr.Param(), 1)
+		}
+		violations = append(violations, Violation{
+			Field:   fieldErr.Namespace()[strings.Index(fieldErr.Namespace(), ".")+1:],
+			Rule:    fieldErr.Tag(),
+			Param:   fieldErr.Param(),
+			Message: message,
+		})
+	}
+	return violations, nil
+}
+
+func isNumber(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
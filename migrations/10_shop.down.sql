@@ -0,0 +1,2 @@
+drop table orders;
+drop table shop_items;
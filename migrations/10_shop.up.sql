@@ -0,0 +1,5 @@
+create table shop_items (id serial primary key, name varchar not null, description varchar not null default '', price int not null check (price > 0), stock int check (stock >= 0), per_user_limit int check (per_user_limit > 0), active boolean not null default true);
+alter table shop_items add constraint unique_item_name unique (name);
+create table orders (id serial primary key, user_id int not null references users(id), item_id int not null references shop_items(id), quantity int not null check (quantity > 0), price int not null, status varchar not null default 'pending', created_at timestamptz not null default now(), updated_at timestamptz not null default now());
+create index orders_user_id_item_id on orders (user_id, item_id);
+create index orders_status on orders (status, created_at);
@@ -0,0 +1,3 @@
+drop table ledger_entries;
+drop table transfers;
+alter table users drop column created_at;
@@ -0,0 +1,7 @@
+alter table users add column created_at timestamptz not null default now();
+create table transfers (id serial primary key, from_user_id int not null references users(id), to_user_id int not null references users(id), amount int not null check (amount > 0), idempotency_key varchar not null, created_at timestamptz not null default now(), check (from_user_id <> to_user_id));
+alter table transfers add constraint unique_transfer_idempotency_key unique (from_user_id, idempotency_key);
+create index transfers_from_user_id_created_at on transfers (from_user_id, created_at);
+create index transfers_to_user_id_created_at on transfers (to_user_id, created_at);
+create table ledger_entries (id serial primary key, user_id int not null references users(id), amount int not null, kind varchar not null, reference_id int, created_at timestamptz not null default now());
+create index ledger_entries_user_id_created_at on ledger_entries (user_id, created_at);
@@ -0,0 +1,2 @@
+create table idempotency_keys (scope varchar not null, key varchar not null, fingerprint varchar not null, status_code int, content_type varchar, response_body bytea, created_at timestamptz not null default now(), expires_at timestamptz not null, primary key (scope, key));
+create index idempotency_keys_expires_at on idempotency_keys (expires_at);
@@ -0,0 +1,6 @@
+drop table notifications;
+drop table clawbacks;
+alter table users drop column referral_revoked_at;
+alter table tasks_to_users drop column revoked_at;
+drop index reward_events_user_id_source_reference_id;
+alter table reward_events drop column reference_id;
@@ -0,0 +1,8 @@
+alter table reward_events add column reference_id int;
+create index reward_events_user_id_source_reference_id on reward_events (user_id, source, reference_id);
+alter table tasks_to_users add column revoked_at timestamptz;
+alter table users add column referral_revoked_at timestamptz;
+create table clawbacks (id serial primary key, user_id int not null references users(id), kind varchar not null, reference_id int not null, amount int not null, clawed int not null, reason varchar not null default '', created_at timestamptz not null default now());
+create index clawbacks_user_id on clawbacks (user_id);
+create table notifications (id serial primary key, user_id int not null references users(id), kind varchar not null, message varchar not null, created_at timestamptz not null default now(), read_at timestamptz);
+create index notifications_user_id_created_at on notifications (user_id, created_at);
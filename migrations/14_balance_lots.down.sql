@@ -0,0 +1 @@
+drop table balance_lots;
@@ -0,0 +1,4 @@
+create table balance_lots (id serial primary key, user_id int not null references users(id), amount int not null, remaining int not null check (remaining >= 0), source varchar not null, created_at timestamptz not null default now(), expires_at timestamptz, expired_at timestamptz);
+create index balance_lots_user_id_remaining on balance_lots (user_id, created_at) where remaining > 0;
+create index balance_lots_expires_at on balance_lots (expires_at) where remaining > 0;
+insert into balance_lots (user_id, amount, remaining, source, expires_at) select id, balance, balance, 'legacy', null from users where balance > 0;
@@ -0,0 +1,17 @@
+alter table balance_lots alter column amount type int using round(amount), alter column remaining type int using round(remaining);
+alter table clawbacks drop column currency, alter column amount type int using round(amount), alter column clawed type int using round(clawed);
+delete from ledger_entries where currency <> 'points';
+alter table ledger_entries drop column currency, alter column amount type int using round(amount);
+alter table transfers alter column amount type int using round(amount);
+alter table orders alter column price type int using round(price);
+alter table shop_items alter column price type int using round(price);
+alter table season_results alter column points type int using round(points), alter column prize type int using round(prize);
+alter table season_points alter column points type int using round(points);
+alter table season_prizes alter column reward type int using round(reward);
+alter table leaderboard_snapshots alter column earned type int using round(earned);
+delete from reward_events where currency <> 'points';
+alter table reward_events drop column currency, alter column amount type int using round(amount);
+drop table user_balances;
+alter table tasks drop column currency, alter column reward type int using round(reward);
+alter table users alter column balance type int using round(balance);
+drop table currencies;
@@ -0,0 +1,16 @@
+create table currencies (code varchar primary key, name varchar not null);
+insert into currencies (code, name) values ('points', 'Баллы'), ('gems', 'Кристаллы');
+alter table users alter column balance type numeric(20, 2);
+alter table tasks alter column reward type numeric(20, 2), add column currency varchar not null default 'points' references currencies(code);
+create table user_balances (user_id int not null references users(id), currency varchar not null references currencies(code), amount numeric(20, 2) not null default 0, primary key (user_id, currency), check (currency <> 'points'));
+alter table reward_events alter column amount type numeric(20, 2), add column currency varchar not null default 'points' references currencies(code);
+alter table leaderboard_snapshots alter column earned type numeric(20, 2);
+alter table season_prizes alter column reward type numeric(20, 2);
+alter table season_points alter column points type numeric(20, 2);
+alter table season_results alter column points type numeric(20, 2), alter column prize type numeric(20, 2);
+alter table shop_items alter column price type numeric(20, 2);
+alter table orders alter column price type numeric(20, 2);
+alter table transfers alter column amount type numeric(20, 2);
+alter table ledger_entries alter column amount type numeric(20, 2), add column currency varchar not null default 'points' references currencies(code);
+alter table clawbacks alter column amount type numeric(20, 2), alter column clawed type numeric(20, 2), add column currency varchar not null default 'points' references currencies(code);
+alter table balance_lots alter column amount type numeric(20, 2), alter column remaining type numeric(20, 2);
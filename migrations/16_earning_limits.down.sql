@@ -0,0 +1,3 @@
+drop index reward_events_user_id_created_at;
+drop index tasks_to_users_user_id_completed_at;
+alter table tasks_to_users drop column completed_at;
@@ -0,0 +1,4 @@
+alter table tasks_to_users add column completed_at timestamptz;
+alter table tasks_to_users alter column completed_at set default now();
+create index tasks_to_users_user_id_completed_at on tasks_to_users (user_id, completed_at);
+create index reward_events_user_id_created_at on reward_events (user_id, created_at);
@@ -0,0 +1,2 @@
+drop table user_achievements;
+drop table achievements;
@@ -0,0 +1,7 @@
+create table achievements (id serial primary key, code varchar not null unique, name varchar not null, description varchar not null default '', kind varchar not null, threshold int not null check (threshold > 0), bonus numeric(20, 2) not null default 0 check (bonus >= 0));
+create table user_achievements (user_id int not null references users(id), achievement_id int not null references achievements(id), awarded_at timestamptz not null default now(), primary key (user_id, achievement_id));
+INSERT INTO achievements (code, name, description, kind, threshold, bonus) VALUES
+ ('first_task', 'Первое задание', 'Выполнить первое задание', 'tasks_completed', 1, 5),
+ ('ten_referrals', 'Амбассадор', 'Пригласить 10 друзей', 'referrals', 10, 100),
+ ('streak_30', 'Месяц без перерыва', 'Выполнять задания 30 дней подряд', 'streak_days', 30, 200),
+ ('top_10', 'В десятке лучших', 'Попасть в топ-10 таблицы лидеров', 'leaderboard_rank', 10, 0);
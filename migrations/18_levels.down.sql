@@ -0,0 +1,2 @@
+drop table level_ups;
+alter table users drop column xp;
@@ -0,0 +1,6 @@
+alter table users add column xp numeric(20, 2) not null default 0;
+update users u set xp = e.earned
+from (select user_id, sum(amount) as earned from reward_events where currency = 'points' and source in ('task', 'referral') group by user_id) e
+where u.id = e.user_id and e.earned > 0;
+create table level_ups (id serial primary key, user_id int not null references users(id), level int not null, created_at timestamptz not null default now());
+create index level_ups_user_id on level_ups (user_id);
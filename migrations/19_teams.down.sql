@@ -0,0 +1,3 @@
+drop table team_goals;
+alter table users drop column team_joined_at, drop column team_id;
+drop table teams;
@@ -0,0 +1,7 @@
+create table teams (id serial primary key, name varchar not null unique, invite_code varchar not null unique, owner_id int references users(id), points numeric(20, 2) not null default 0, created_at timestamptz not null default now());
+create index teams_points on teams (points desc, id);
+alter table users add column team_id int references teams(id), add column team_joined_at timestamptz;
+create index users_team_id on users (team_id);
+create table team_goals (id serial primary key, team_id int not null references teams(id), description varchar not null default '', target numeric(20, 2) not null check (target > 0), progress numeric(20, 2) not null default 0, bonus numeric(20, 2) not null check (bonus >= 0), reached_at timestamptz, paid_at timestamptz, created_at timestamptz not null default now());
+create index team_goals_team_id on team_goals (team_id) where reached_at is null;
+create index team_goals_to_pay on team_goals (reached_at) where reached_at is not null and paid_at is null;
@@ -0,0 +1,4 @@
+drop table raffle_winners;
+drop table raffle_tickets;
+drop table raffle_prizes;
+drop table raffles;
@@ -0,0 +1,6 @@
+create table raffles (id serial primary key, name varchar not null, description varchar not null default '', ticket_price numeric(20, 2) not null check (ticket_price > 0), max_tickets_per_user int check (max_tickets_per_user > 0), draw_at timestamptz not null, seed varchar not null, seed_hash varchar not null, tickets_sold int not null default 0, drawn_at timestamptz, created_at timestamptz not null default now());
+create index raffles_to_draw on raffles (draw_at) where drawn_at is null;
+create table raffle_prizes (raffle_id int not null references raffles(id), place int not null check (place > 0), reward numeric(20, 2) not null check (reward > 0), primary key (raffle_id, place));
+create table raffle_tickets (raffle_id int not null references raffles(id), number int not null, user_id int not null references users(id), price numeric(20, 2) not null, created_at timestamptz not null default now(), primary key (raffle_id, number));
+create index raffle_tickets_user_id on raffle_tickets (raffle_id, user_id);
+create table raffle_winners (raffle_id int not null references raffles(id), place int not null, ticket_number int not null, user_id int not null references users(id), reward numeric(20, 2) not null, primary key (raffle_id, place));
@@ -0,0 +1,3 @@
+drop table webhook_delivery_attempts;
+drop table webhook_deliveries;
+drop table webhooks;
@@ -0,0 +1,6 @@
+create table webhooks (id serial primary key, url varchar not null, event_types varchar[] not null, secret varchar not null, active boolean not null default true, created_at timestamptz not null default now());
+create table webhook_deliveries (id bigserial primary key, webhook_id int not null references webhooks(id), event_id uuid not null, event_type varchar not null, payload jsonb not null, status varchar not null default 'pending', attempts int not null default 0, next_attempt_at timestamptz not null default now(), last_status_code int, last_error varchar, delivered_at timestamptz, created_at timestamptz not null default now());
+create index webhook_deliveries_pending on webhook_deliveries (next_attempt_at) where status = 'pending';
+create index webhook_deliveries_webhook_id on webhook_deliveries (webhook_id, id desc);
+create table webhook_delivery_attempts (id bigserial primary key, delivery_id bigint not null references webhook_deliveries(id), status_code int, error varchar, duration_ms int not null, attempted_at timestamptz not null default now());
+create index webhook_delivery_attempts_delivery_id on webhook_delivery_attempts (delivery_id);
@@ -0,0 +1,2 @@
+drop index webhook_deliveries_event_id;
+drop table outbox;
@@ -0,0 +1,4 @@
+create table outbox (id bigserial primary key, event_id uuid not null unique, event_type varchar not null, payload jsonb not null, attempts int not null default 0, next_attempt_at timestamptz not null default now(), last_error varchar, published_at timestamptz, created_at timestamptz not null default now());
+create index outbox_pending on outbox (next_attempt_at) where published_at is null;
+create index outbox_published_at on outbox (published_at) where published_at is not null;
+create index webhook_deliveries_event_id on webhook_deliveries (event_id, webhook_id);
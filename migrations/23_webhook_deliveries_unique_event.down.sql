@@ -0,0 +1,3 @@
+drop index webhook_deliveries_event_id;
+create index webhook_deliveries_event_id on webhook_deliveries (event_id, webhook_id);
+alter table webhook_deliveries drop column replay_of;
@@ -0,0 +1,6 @@
+alter table webhook_deliveries add column replay_of bigint references webhook_deliveries(id);
+update webhook_deliveries d set replay_of = f.id
+from (select distinct on (event_id, webhook_id) id, event_id, webhook_id from webhook_deliveries order by event_id, webhook_id, id) f
+where d.event_id = f.event_id and d.webhook_id = f.webhook_id and d.id <> f.id;
+drop index webhook_deliveries_event_id;
+create unique index webhook_deliveries_event_id on webhook_deliveries (event_id, webhook_id) where replay_of is null;
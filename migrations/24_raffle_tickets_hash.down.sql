@@ -0,0 +1 @@
+alter table raffles drop column tickets_hash;
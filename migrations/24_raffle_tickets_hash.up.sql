@@ -0,0 +1 @@
+alter table raffles add column tickets_hash varchar;
@@ -0,0 +1,2 @@
+create table order_lots (order_id int not null references orders(id), amount numeric(20, 2) not null check (amount > 0), expires_at timestamptz);
+create index order_lots_order_id on order_lots (order_id);
@@ -0,0 +1,2 @@
+drop table leaderboard_snapshots;
+drop table reward_events;
@@ -0,0 +1,4 @@
+create table reward_events (id serial primary key, user_id int not null references users(id), amount int not null, source varchar not null, created_at timestamptz not null default now());
+create index reward_events_created_at_user_id on reward_events (created_at, user_id);
+create table leaderboard_snapshots (id serial primary key, period varchar not null, period_start timestamptz not null, period_end timestamptz not null, user_id int not null references users(id), earned int not null, rank int not null, dense_rank int not null, position int not null, created_at timestamptz not null default now());
+alter table leaderboard_snapshots add constraint unique_snapshot_user unique (period, period_start, user_id);
@@ -0,0 +1,2 @@
+drop index tasks_to_users_user_id;
+drop index users_balance_id;
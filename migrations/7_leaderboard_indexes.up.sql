@@ -0,0 +1,2 @@
+create index users_balance_id on users (balance desc, id);
+create index tasks_to_users_user_id on tasks_to_users (user_id);
@@ -0,0 +1,2 @@
+drop index users_referrer_id;
+alter table users drop column referrer_id;
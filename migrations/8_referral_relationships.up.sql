@@ -0,0 +1,2 @@
+alter table users add column referrer_id int references users(id);
+create index users_referrer_id on users (referrer_id);
@@ -0,0 +1,5 @@
+drop table season_results;
+drop table season_points;
+drop table season_prizes;
+drop table seasons;
+alter table users drop column role;
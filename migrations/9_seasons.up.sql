@@ -0,0 +1,8 @@
+alter table users add column role varchar not null default 'user';
+create table seasons (id serial primary key, name varchar not null, starts_at timestamptz not null, ends_at timestamptz not null, closed_at timestamptz, check (starts_at < ends_at));
+create table season_prizes (id serial primary key, season_id int not null references seasons(id), place int not null, reward int not null, check (place > 0 and reward > 0));
+alter table season_prizes add constraint unique_season_place unique (season_id, place);
+create table season_points (season_id int not null references seasons(id), user_id int not null references users(id), points int not null, primary key (season_id, user_id));
+create index season_points_season_id_points on season_points (season_id, points desc, user_id);
+create table season_results (id serial primary key, season_id int not null references seasons(id), user_id int not null references users(id), points int not null, rank int not null, dense_rank int not null, position int not null, prize int not null default 0);
+alter table season_results add constraint unique_season_result unique (season_id, user_id);